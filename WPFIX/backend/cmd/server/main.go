@@ -1,9 +1,18 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 	"wallet-point/config"
 	"wallet-point/internal/database"
+	"wallet-point/internal/fraud"
 	"wallet-point/routes"
 	"wallet-point/utils"
 
@@ -49,16 +58,52 @@ func main() {
 	r := gin.Default()
 
 	// Setup routes
-	routes.SetupRoutes(r, db, cfg.AllowedOrigins, cfg.JWTExpiryHours)
+	fraudPolicy := fraud.Policy{
+		FlagScore:  cfg.FraudFlagScore,
+		HoldScore:  cfg.FraudHoldScore,
+		BlockScore: cfg.FraudBlockScore,
+	}
+	jobs := routes.SetupRoutes(r, db, cfg.AllowedOrigins, cfg.JWTExpiryHours, fraudPolicy)
+
+	// Stop on Ctrl+C or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Start background jobs
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			job(ctx)
+		}()
+	}
 
 	// Start server
 	serverAddress := ":" + cfg.ServerPort
+	server := &http.Server{Addr: serverAddress, Handler: r}
 	log.Printf("🚀 Server starting on http://%s", cfg.ServerAddress)
 	log.Printf("📚 API Documentation (if Swagger enabled): http://%s/swagger/index.html", cfg.ServerAddress)
 	log.Printf("🏥 Health Check: http://%s/api/v1/health", cfg.ServerAddress)
 	log.Println("✨ Press Ctrl+C to stop the server")
 
-	if err := r.Run(serverAddress); err != nil {
-		log.Fatal("❌ Failed to start server:", err)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("❌ Failed to start server:", err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	log.Println("🛑 Shutting down server...")
+
+	// Let requests in flight finish, then wait for the background jobs
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("❌ Server shutdown failed: %v", err)
 	}
+	wg.Wait()
+
+	log.Println("✅ Server stopped")
 }
@@ -24,6 +24,9 @@ type Config struct {
 	MaxUploadSize      int64
 	UploadPath         string
 	ExternalAPITimeout int
+	FraudFlagScore     int
+	FraudHoldScore     int
+	FraudBlockScore    int
 }
 
 func LoadConfig() *Config {
@@ -50,6 +53,20 @@ func LoadConfig() *Config {
 		apiTimeout = 30
 	}
 
+	// Parse fraud screening thresholds (0 disables the action)
+	fraudFlag, err := strconv.Atoi(getEnv("FRAUD_FLAG_SCORE", "40"))
+	if err != nil {
+		fraudFlag = 40
+	}
+	fraudHold, err := strconv.Atoi(getEnv("FRAUD_HOLD_SCORE", "70"))
+	if err != nil {
+		fraudHold = 70
+	}
+	fraudBlock, err := strconv.Atoi(getEnv("FRAUD_BLOCK_SCORE", "100"))
+	if err != nil {
+		fraudBlock = 100
+	}
+
 	serverHost := getEnv("SERVER_HOST", "localhost")
 	serverPort := getEnv("SERVER_PORT", "8102")
 
@@ -69,6 +86,9 @@ func LoadConfig() *Config {
 		MaxUploadSize:      maxUploadSize,
 		UploadPath:         getEnv("UPLOAD_PATH", "./uploads"),
 		ExternalAPITimeout: apiTimeout,
+		FraudFlagScore:     fraudFlag,
+		FraudHoldScore:     fraudHold,
+		FraudBlockScore:    fraudBlock,
 	}
 }
 
@@ -11,6 +11,8 @@ type User struct {
 	FullName     string    `json:"full_name" gorm:"not null"`
 	NimNip       string    `json:"nim_nip" gorm:"uniqueIndex;not null"`
 	Role         string    `json:"role" gorm:"type:enum('admin','dosen','mahasiswa','merchant');not null"`
+	Major        string    `json:"major" gorm:"size:255"`
+	Batch        string    `json:"batch" gorm:"size:50"`
 	Status       string    `json:"status" gorm:"type:enum('active','inactive','suspended');default:'active'"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
@@ -31,6 +33,8 @@ type RegisterRequest struct {
 	FullName string `json:"full_name" binding:"required"`
 	NimNip   string `json:"nim_nip" binding:"required"`
 	Role     string `json:"role" binding:"required,oneof=admin dosen mahasiswa merchant"`
+	Major    string `json:"major" binding:"max=255"`
+	Batch    string `json:"batch" binding:"max=50"`
 }
 
 type LoginResponse struct {
@@ -94,6 +94,8 @@ func (s *AuthService) Register(req *RegisterRequest) (*User, error) {
 		FullName:     req.FullName,
 		NimNip:       req.NimNip,
 		Role:         req.Role,
+		Major:        req.Major,
+		Batch:        req.Batch,
 		Status:       "active",
 	}
 
@@ -4,8 +4,11 @@ import (
 	"log"
 	"wallet-point/internal/audit"
 	"wallet-point/internal/auth"
+	"wallet-point/internal/fraud"
 	"wallet-point/internal/marketplace"
 	"wallet-point/internal/mission"
+	"wallet-point/internal/notification"
+	"wallet-point/internal/paymentrequest"
 	"wallet-point/internal/transfer"
 	"wallet-point/internal/wallet"
 
@@ -22,12 +25,36 @@ func Migrate(db *gorm.DB) {
 		&wallet.WalletTransaction{},
 		&wallet.PaymentToken{},
 		&transfer.Transfer{},
+		&marketplace.Category{},
+		&marketplace.Tag{},
 		&marketplace.Product{},
+		&marketplace.ProductVariant{},
+		&marketplace.Order{},
 		&marketplace.MarketplaceTransaction{},
+		&marketplace.CartItem{},
+		&marketplace.StockReservation{},
+		&marketplace.Promotion{},
+		&marketplace.Coupon{},
+		&marketplace.CouponRedemption{},
+		&marketplace.DigitalCode{},
+		&marketplace.Review{},
+		&marketplace.WishlistItem{},
+		&marketplace.Auction{},
+		&marketplace.Bid{},
 		&audit.AuditLog{},
 		&mission.Mission{},
 		&mission.MissionQuestion{},
 		&mission.MissionSubmission{},
+		&mission.AttemptSession{},
+		&mission.BankQuestion{},
+		&mission.PoolRule{},
+		&mission.Class{},
+		&mission.ClassEnrollment{},
+		&mission.MissionClass{},
+		&paymentrequest.PaymentRequest{},
+		&paymentrequest.PaymentRequestShare{},
+		&fraud.Alert{},
+		&notification.Notification{},
 	)
 
 	if err != nil {
@@ -38,8 +65,17 @@ func Migrate(db *gorm.DB) {
 	// Execute these AFTER tables are created
 	db.Exec("ALTER TABLE users MODIFY COLUMN role ENUM('admin', 'dosen', 'mahasiswa', 'merchant') NOT NULL")
 	db.Exec("ALTER TABLE missions MODIFY COLUMN type ENUM('quiz', 'task', 'assignment') NOT NULL")
+	db.Exec("ALTER TABLE missions MODIFY COLUMN status ENUM('active', 'inactive', 'expired', 'scheduled', 'recurring') DEFAULT 'active'")
 	db.Exec("ALTER TABLE mission_submissions MODIFY COLUMN status ENUM('pending', 'approved', 'rejected') NOT NULL DEFAULT 'pending'")
-	db.Exec("ALTER TABLE wallet_transactions MODIFY COLUMN type ENUM('mission', 'task', 'transfer_in', 'transfer_out', 'marketplace', 'marketplace_sale', 'external', 'adjustment', 'topup') NOT NULL")
+	db.Exec("ALTER TABLE transfers MODIFY COLUMN status ENUM('success', 'failed', 'held', 'reversed') DEFAULT 'success'")
+	db.Exec("ALTER TABLE payment_request_shares MODIFY COLUMN status ENUM('pending', 'paid', 'held', 'declined', 'cancelled') DEFAULT 'pending'")
+	db.Exec("ALTER TABLE products MODIFY COLUMN type ENUM('physical', 'digital', 'auction') NOT NULL DEFAULT 'physical'")
+	db.Exec("ALTER TABLE wallet_transactions MODIFY COLUMN type ENUM('mission', 'task', 'transfer_in', 'transfer_out', 'marketplace', 'marketplace_sale', 'marketplace_refund', 'external', 'adjustment', 'topup', 'auction_hold', 'auction_release') NOT NULL")
+
+	// Marketplace search: full-text index for product name/description matching
+	if !db.Migrator().HasIndex(&marketplace.Product{}, "idx_products_search") {
+		db.Exec("ALTER TABLE products ADD FULLTEXT INDEX idx_products_search (name, description)")
+	}
 
 	// Cleanup: Remove legacy tables
 	db.Exec("DROP TABLE IF EXISTS task_submissions")
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"wallet-point/internal/auth"
+	"wallet-point/internal/wallet"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var (
+	testDBOnce sync.Once
+	testDB     *gorm.DB
+	testDBErr  error
+	testUserN  int64
+)
+
+// OpenTestDB connects to the MySQL database named by TEST_DB_DSN and migrates
+// it once per test binary, skipping the test when the variable is unset. The
+// database is shared, so tests create their own rows instead of expecting
+// empty tables.
+func OpenTestDB(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DB_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN is not set; skipping database test")
+	}
+
+	testDBOnce.Do(func() {
+		testDB, testDBErr = gorm.Open(mysql.Open(dsn), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+			NowFunc: func() time.Time {
+				return time.Now().Local()
+			},
+		})
+		if testDBErr == nil {
+			Migrate(testDB)
+		}
+	})
+	if testDBErr != nil {
+		t.Fatalf("failed to connect to test database: %v", testDBErr)
+	}
+
+	return testDB
+}
+
+// CreateTestUser creates an active user with a wallet holding balance points
+func CreateTestUser(t testing.TB, db *gorm.DB, role string, balance int) (*auth.User, *wallet.Wallet) {
+	t.Helper()
+
+	n := atomic.AddInt64(&testUserN, 1)
+	suffix := fmt.Sprintf("%d%d", time.Now().UnixNano(), n)
+	user := &auth.User{
+		Email:        "test" + suffix + "@example.com",
+		PasswordHash: "-",
+		FullName:     fmt.Sprintf("Test %s %d", role, n),
+		NimNip:       "T" + suffix,
+		Role:         role,
+		Status:       "active",
+	}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+
+	w := &wallet.Wallet{UserID: user.ID, Balance: balance}
+	if err := db.Create(w).Error; err != nil {
+		t.Fatalf("failed to create test wallet: %v", err)
+	}
+
+	return user, w
+}
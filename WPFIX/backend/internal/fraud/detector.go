@@ -0,0 +1,161 @@
+package fraud
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Rule tuning. Scores are additive; the policy thresholds decide the action.
+const (
+	circularWindow      = 24 * time.Hour
+	circularScore       = 40
+	fanInWindow         = time.Hour
+	fanInSmallAmount    = 50
+	fanInMinSenders     = 5
+	fanInScore          = 30
+	fanInPurchaseScore  = 45
+	newAccountAge       = 7 * 24 * time.Hour
+	newAccountDrainPct  = 80
+	newAccountScore     = 40
+	velocityWindow      = time.Hour
+	velocityMaxOutgoing = 10
+	velocityScore       = 25
+)
+
+// Detector scores transfers and payments against a fixed set of rules
+type Detector struct {
+	db *gorm.DB
+}
+
+// NewDetector creates a new anomaly detector
+func NewDetector(db *gorm.DB) *Detector {
+	return &Detector{db: db}
+}
+
+type rule func(d *Detector, e Event, now time.Time) (*RuleHit, error)
+
+var rules = []rule{
+	circularTransferRule,
+	fanInRule,
+	newAccountDrainRule,
+	velocityRule,
+}
+
+// Score evaluates every rule against the event and sums the hits
+func (d *Detector) Score(e Event) (int, RuleHits, error) {
+	now := time.Now()
+	total := 0
+	hits := RuleHits{}
+
+	for _, r := range rules {
+		hit, err := r(d, e, now)
+		if err != nil {
+			return 0, nil, err
+		}
+		if hit != nil {
+			total += hit.Score
+			hits = append(hits, *hit)
+		}
+	}
+
+	return total, hits, nil
+}
+
+// circularTransferRule flags points bouncing back to a wallet that just sent to the sender
+func circularTransferRule(d *Detector, e Event, now time.Time) (*RuleHit, error) {
+	if e.Type != EventTransfer || e.ReceiverWalletID == 0 {
+		return nil, nil
+	}
+
+	var count int64
+	err := d.db.Table("transfers").
+		Where("sender_wallet_id = ? AND receiver_wallet_id = ? AND created_at >= ?", e.ReceiverWalletID, e.SenderWalletID, now.Add(-circularWindow)).
+		Count(&count).Error
+	if err != nil || count == 0 {
+		return nil, err
+	}
+
+	return &RuleHit{
+		Rule:   "circular_transfer",
+		Score:  circularScore,
+		Detail: fmt.Sprintf("receiver sent %d transfer(s) to the sender in the last %s", count, circularWindow),
+	}, nil
+}
+
+// fanInRule flags a wallet collecting many small transfers from different senders,
+// and scores it higher when that wallet is about to spend them
+func fanInRule(d *Detector, e Event, now time.Time) (*RuleHit, error) {
+	// For transfers the collecting wallet is the receiver; for payments it's the payer
+	walletID := e.ReceiverWalletID
+	score := fanInScore
+	if e.Type == EventPayment {
+		walletID = e.SenderWalletID
+		score = fanInPurchaseScore
+	}
+	if walletID == 0 {
+		return nil, nil
+	}
+
+	var senders int64
+	err := d.db.Table("transfers").
+		Where("receiver_wallet_id = ? AND amount <= ? AND created_at >= ?", walletID, fanInSmallAmount, now.Add(-fanInWindow)).
+		Distinct("sender_wallet_id").
+		Count(&senders).Error
+	if err != nil || senders < fanInMinSenders {
+		return nil, err
+	}
+
+	return &RuleHit{
+		Rule:   "fan_in",
+		Score:  score,
+		Detail: fmt.Sprintf("wallet received small transfers from %d senders in the last %s", senders, fanInWindow),
+	}, nil
+}
+
+// newAccountDrainRule flags freshly created accounts moving out most of their balance
+func newAccountDrainRule(d *Detector, e Event, now time.Time) (*RuleHit, error) {
+	var owner struct {
+		Balance   int
+		CreatedAt time.Time
+	}
+	err := d.db.Table("wallets").
+		Select("wallets.balance, users.created_at").
+		Joins("JOIN users ON users.id = wallets.user_id").
+		Where("wallets.id = ?", e.SenderWalletID).
+		Scan(&owner).Error
+	if err != nil || owner.Balance <= 0 {
+		return nil, err
+	}
+
+	if now.Sub(owner.CreatedAt) > newAccountAge {
+		return nil, nil
+	}
+	if e.Amount*100 < owner.Balance*newAccountDrainPct {
+		return nil, nil
+	}
+
+	return &RuleHit{
+		Rule:   "new_account_drain",
+		Score:  newAccountScore,
+		Detail: fmt.Sprintf("account younger than %s is moving %d of %d points", newAccountAge, e.Amount, owner.Balance),
+	}, nil
+}
+
+// velocityRule flags wallets sending an unusual number of transfers in a short window
+func velocityRule(d *Detector, e Event, now time.Time) (*RuleHit, error) {
+	var count int64
+	err := d.db.Table("transfers").
+		Where("sender_wallet_id = ? AND created_at >= ?", e.SenderWalletID, now.Add(-velocityWindow)).
+		Count(&count).Error
+	if err != nil || count < velocityMaxOutgoing {
+		return nil, err
+	}
+
+	return &RuleHit{
+		Rule:   "velocity",
+		Score:  velocityScore,
+		Detail: fmt.Sprintf("wallet sent %d transfers in the last %s", count, velocityWindow),
+	}, nil
+}
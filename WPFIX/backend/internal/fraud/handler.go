@@ -0,0 +1,129 @@
+package fraud
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service      *Service
+	auditService *audit.AuditService
+}
+
+func NewHandler(service *Service, auditService *audit.AuditService) *Handler {
+	return &Handler{service: service, auditService: auditService}
+}
+
+// GetAlerts handles the fraud review queue
+// @Summary Get fraud alerts
+// @Description Get scored transfers and payments flagged for review (Admin only)
+// @Tags Admin - Fraud
+// @Security BearerAuth
+// @Produce json
+// @Param event_type query string false "Filter by event type" Enums(transfer, payment)
+// @Param action query string false "Filter by action" Enums(flagged, held, blocked)
+// @Param status query string false "Filter by status" Enums(open, approved, rejected, dismissed)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=AlertListResponse}
+// @Router /admin/fraud/alerts [get]
+func (h *Handler) GetAlerts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	params := AlertListParams{
+		EventType: c.Query("event_type"),
+		Action:    c.Query("action"),
+		Status:    c.DefaultQuery("status", "open"),
+		Page:      page,
+		Limit:     limit,
+	}
+
+	response, err := h.service.GetAlerts(params)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve fraud alerts", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Fraud alerts retrieved successfully", response)
+}
+
+// GetAlert handles getting a single fraud alert
+// @Summary Get fraud alert by ID
+// @Tags Admin - Fraud
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Alert ID"
+// @Success 200 {object} utils.Response{data=Alert}
+// @Failure 404 {object} utils.Response
+// @Router /admin/fraud/alerts/{id} [get]
+func (h *Handler) GetAlert(c *gin.Context) {
+	alertID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid alert ID", nil)
+		return
+	}
+
+	alert, err := h.service.GetAlert(uint(alertID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Fraud alert retrieved successfully", alert)
+}
+
+// Review handles closing a flagged or blocked alert
+// @Summary Review fraud alert
+// @Description Mark a flagged or blocked alert as approved, rejected or dismissed (Admin only)
+// @Tags Admin - Fraud
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Alert ID"
+// @Param request body ReviewAlertRequest true "Review decision"
+// @Success 200 {object} utils.Response{data=Alert}
+// @Failure 400 {object} utils.Response
+// @Router /admin/fraud/alerts/{id}/review [post]
+func (h *Handler) Review(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+	alertID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid alert ID", nil)
+		return
+	}
+
+	var req ReviewAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	alert, err := h.service.ReviewAlert(uint(alertID), &req, adminID)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "alert not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Fraud alert reviewed successfully", alert)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "REVIEW_FRAUD_ALERT",
+		Entity:    "FRAUD_ALERT",
+		EntityID:  alert.ID,
+		Details:   fmt.Sprintf("Admin marked fraud alert as %s | Note: %s", req.Status, req.ReviewNote),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
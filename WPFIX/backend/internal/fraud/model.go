@@ -0,0 +1,121 @@
+package fraud
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Event types scored by the detector
+const (
+	EventTransfer = "transfer"
+	EventPayment  = "payment"
+)
+
+// Actions taken on a scored event
+const (
+	ActionAllow = "allow"
+	ActionFlag  = "flagged"
+	ActionHold  = "held"
+	ActionBlock = "blocked"
+)
+
+// RuleHit describes a single rule that contributed to an event's score
+type RuleHit struct {
+	Rule   string `json:"rule"`
+	Score  int    `json:"score"`
+	Detail string `json:"detail"`
+}
+
+type RuleHits []RuleHit
+
+func (rh RuleHits) Value() (driver.Value, error) {
+	return json.Marshal(rh)
+}
+
+func (rh *RuleHits) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, rh)
+}
+
+// Alert is a scored transfer or payment that landed in the admin review queue
+type Alert struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	EventType   string `json:"event_type" gorm:"type:enum('transfer','payment');not null;index"`
+	Channel     string `json:"channel" gorm:"size:50"`    // transfer, qr_merchant, qr_bill, marketplace
+	ReferenceID *uint  `json:"reference_id" gorm:"index"` // ID of the record in the channel's table
+
+	SenderWalletID   uint       `json:"sender_wallet_id" gorm:"not null;index"`
+	ReceiverWalletID uint       `json:"receiver_wallet_id" gorm:"index"`
+	Amount           int        `json:"amount" gorm:"not null"`
+	Score            int        `json:"score" gorm:"not null"`
+	Rules            RuleHits   `json:"rules" gorm:"type:json"`
+	Action           string     `json:"action" gorm:"type:enum('flagged','held','blocked');not null"`
+	Status           string     `json:"status" gorm:"type:enum('open','approved','rejected','dismissed');default:'open';index"`
+	ReviewedBy       *uint      `json:"reviewed_by"`
+	ReviewNote       string     `json:"review_note" gorm:"type:text"`
+	ReviewedAt       *time.Time `json:"reviewed_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+func (Alert) TableName() string {
+	return "fraud_alerts"
+}
+
+// Event is the input scored by the detector
+type Event struct {
+	Type             string
+	Channel          string
+	SenderWalletID   uint
+	ReceiverWalletID uint
+	Amount           int
+}
+
+// Assessment is the detector's verdict on an event
+type Assessment struct {
+	Score  int      `json:"score"`
+	Hits   RuleHits `json:"rules"`
+	Action string   `json:"action"`
+}
+
+// Policy holds the score thresholds that decide what happens to an event.
+// A zero threshold disables that action.
+type Policy struct {
+	FlagScore  int
+	HoldScore  int
+	BlockScore int
+}
+
+type AlertWithDetails struct {
+	Alert
+	SenderName   string `json:"sender_name"`
+	SenderNIM    string `json:"sender_nim"`
+	ReceiverName string `json:"receiver_name"`
+	ReceiverNIM  string `json:"receiver_nim"`
+}
+
+type AlertListParams struct {
+	EventType string
+	Action    string
+	Status    string
+	Page      int
+	Limit     int
+}
+
+type AlertListResponse struct {
+	Alerts     []AlertWithDetails `json:"alerts"`
+	Total      int64              `json:"total"`
+	Page       int                `json:"page"`
+	Limit      int                `json:"limit"`
+	TotalPages int                `json:"total_pages"`
+}
+
+type ReviewAlertRequest struct {
+	Status     string `json:"status" binding:"required,oneof=approved rejected dismissed"`
+	ReviewNote string `json:"review_note"`
+}
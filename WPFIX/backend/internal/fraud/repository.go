@@ -0,0 +1,93 @@
+package fraud
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// Create stores an alert, inside tx when one is given
+func (r *Repository) Create(tx *gorm.DB, alert *Alert) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Create(alert).Error
+}
+
+func (r *Repository) FindByID(id uint) (*Alert, error) {
+	var alert Alert
+	err := r.db.First(&alert, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("alert not found")
+		}
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// FindOpenByReference finds the open alert attached to a record in a channel
+func (r *Repository) FindOpenByReference(tx *gorm.DB, channel string, referenceID uint) (*Alert, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	var alert Alert
+	err := tx.Where("channel = ? AND reference_id = ? AND status = ?", channel, referenceID, "open").First(&alert).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("alert not found")
+		}
+		return nil, err
+	}
+	return &alert, nil
+}
+
+func (r *Repository) FindAll(params AlertListParams) ([]AlertWithDetails, int64, error) {
+	var alerts []AlertWithDetails
+	var total int64
+
+	query := r.db.Table("fraud_alerts").
+		Select("fraud_alerts.*, su.full_name as sender_name, su.nim_nip as sender_nim, ru.full_name as receiver_name, ru.nim_nip as receiver_nim").
+		Joins("LEFT JOIN wallets sw ON sw.id = fraud_alerts.sender_wallet_id").
+		Joins("LEFT JOIN users su ON su.id = sw.user_id").
+		Joins("LEFT JOIN wallets rw ON rw.id = fraud_alerts.receiver_wallet_id").
+		Joins("LEFT JOIN users ru ON ru.id = rw.user_id")
+
+	if params.EventType != "" {
+		query = query.Where("fraud_alerts.event_type = ?", params.EventType)
+	}
+	if params.Action != "" {
+		query = query.Where("fraud_alerts.action = ?", params.Action)
+	}
+	if params.Status != "" {
+		query = query.Where("fraud_alerts.status = ?", params.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Order("fraud_alerts.score DESC, fraud_alerts.created_at DESC").
+		Limit(params.Limit).
+		Offset(offset).
+		Scan(&alerts).Error
+
+	return alerts, total, err
+}
+
+// Update updates an alert, inside tx when one is given
+func (r *Repository) Update(tx *gorm.DB, id uint, updates map[string]interface{}) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Model(&Alert{}).Where("id = ?", id).Updates(updates).Error
+}
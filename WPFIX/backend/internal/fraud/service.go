@@ -0,0 +1,160 @@
+package fraud
+
+import (
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrBlocked is returned when an event scores above the block threshold
+var ErrBlocked = errors.New("transaction blocked by fraud screening, please contact an administrator")
+
+type Service struct {
+	repo     *Repository
+	detector *Detector
+	policy   Policy
+}
+
+func NewService(repo *Repository, detector *Detector, policy Policy) *Service {
+	return &Service{
+		repo:     repo,
+		detector: detector,
+		policy:   policy,
+	}
+}
+
+// Screen scores an event and decides what to do with it. Blocked events are
+// recorded immediately since the caller's transaction will not commit; other
+// non-allowed events must be recorded by the caller via RecordWithTx once the
+// transfer or payment has an ID.
+func (s *Service) Screen(e Event) (*Assessment, error) {
+	score, hits, err := s.detector.Score(e)
+	if err != nil {
+		// Fail open: screening must never take payments down with it
+		log.Printf("[fraud] scoring failed for %s from wallet %d: %v", e.Type, e.SenderWalletID, err)
+		return &Assessment{Action: ActionAllow}, nil
+	}
+
+	assessment := &Assessment{
+		Score:  score,
+		Hits:   hits,
+		Action: s.decide(e.Type, score),
+	}
+
+	if assessment.Action == ActionBlock {
+		if err := s.RecordWithTx(nil, e, assessment, nil); err != nil {
+			log.Printf("[fraud] failed to record blocked %s: %v", e.Type, err)
+		}
+		return assessment, ErrBlocked
+	}
+
+	return assessment, nil
+}
+
+// decide maps a score to an action using the configured policy
+func (s *Service) decide(eventType string, score int) string {
+	switch {
+	case s.policy.BlockScore > 0 && score >= s.policy.BlockScore:
+		return ActionBlock
+	case s.policy.HoldScore > 0 && score >= s.policy.HoldScore:
+		// Only transfers can be held; payments settle instantly and are flagged instead
+		if eventType == EventTransfer {
+			return ActionHold
+		}
+		return ActionFlag
+	case s.policy.FlagScore > 0 && score >= s.policy.FlagScore:
+		return ActionFlag
+	}
+	return ActionAllow
+}
+
+// RecordWithTx stores an alert for a flagged, held or blocked event
+func (s *Service) RecordWithTx(tx *gorm.DB, e Event, assessment *Assessment, referenceID *uint) error {
+	if assessment == nil || assessment.Action == ActionAllow {
+		return nil
+	}
+
+	return s.repo.Create(tx, &Alert{
+		EventType:        e.Type,
+		Channel:          e.Channel,
+		ReferenceID:      referenceID,
+		SenderWalletID:   e.SenderWalletID,
+		ReceiverWalletID: e.ReceiverWalletID,
+		Amount:           e.Amount,
+		Score:            assessment.Score,
+		Rules:            assessment.Hits,
+		Action:           assessment.Action,
+		Status:           "open",
+	})
+}
+
+// GetAlerts returns the review queue
+func (s *Service) GetAlerts(params AlertListParams) (*AlertListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+
+	alerts, total, err := s.repo.FindAll(params)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(params.Limit)))
+
+	return &AlertListResponse{
+		Alerts:     alerts,
+		Total:      total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+func (s *Service) GetAlert(id uint) (*Alert, error) {
+	return s.repo.FindByID(id)
+}
+
+// ReviewAlert closes a flagged or blocked alert. Held transfers carry money in
+// limbo and are resolved through the transfer release/reverse endpoints instead.
+func (s *Service) ReviewAlert(id uint, req *ReviewAlertRequest, reviewerID uint) (*Alert, error) {
+	alert, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if alert.Status != "open" {
+		return nil, errors.New("alert has already been reviewed")
+	}
+	if alert.Action == ActionHold {
+		return nil, errors.New("held transfers must be released or reversed from the transfer review endpoints")
+	}
+
+	if err := s.resolve(nil, alert.ID, req.Status, reviewerID, req.ReviewNote); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindByID(id)
+}
+
+// ResolveByReferenceWithTx closes the open alert attached to a record in a channel
+func (s *Service) ResolveByReferenceWithTx(tx *gorm.DB, channel string, referenceID uint, status string, reviewerID uint, note string) error {
+	alert, err := s.repo.FindOpenByReference(tx, channel, referenceID)
+	if err != nil {
+		return err
+	}
+	return s.resolve(tx, alert.ID, status, reviewerID, note)
+}
+
+func (s *Service) resolve(tx *gorm.DB, id uint, status string, reviewerID uint, note string) error {
+	return s.repo.Update(tx, id, map[string]interface{}{
+		"status":      status,
+		"reviewed_by": reviewerID,
+		"review_note": note,
+		"reviewed_at": time.Now(),
+	})
+}
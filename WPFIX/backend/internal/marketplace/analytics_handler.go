@@ -0,0 +1,243 @@
+package marketplace
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// analyticsParams reads the date range and limits shared by the analytics endpoints
+func analyticsParams(c *gin.Context) (AnalyticsParams, error) {
+	from, to, err := ParseAnalyticsRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return AnalyticsParams{}, err
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	params := AnalyticsParams{
+		From:  from,
+		To:    to,
+		Sort:  c.Query("sort"),
+		Limit: limit,
+	}
+
+	// Merchants only see sales of their own products
+	if c.GetString("role") == "merchant" {
+		params.SellerID = c.GetUint("user_id")
+	}
+	return params, nil
+}
+
+// writeCSV sends rows as a CSV download when format=csv was asked for, and
+// reports whether it did
+func writeCSV(c *gin.Context, name string, params AnalyticsParams, header []string, rows [][]string) bool {
+	if c.Query("format") != "csv" {
+		return false
+	}
+
+	filename := fmt.Sprintf("%s_%s_%s.csv", name, params.From.Format(analyticsDateLayout), params.To.AddDate(0, 0, -1).Format(analyticsDateLayout))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write(header)
+	w.WriteAll(rows)
+	return true
+}
+
+func itoa(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
+
+func ftoa(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// GetSalesSummary handles the sales totals for a date range
+// @Summary Get sales summary
+// @Description Revenue, units, transactions and buyers of completed marketplace sales (Admin; merchants see their own products)
+// @Tags Admin - Marketplace Analytics
+// @Security BearerAuth
+// @Produce json,text/csv
+// @Param from query string false "Start date (YYYY-MM-DD), default 30 days ago"
+// @Param to query string false "End date inclusive (YYYY-MM-DD), default today"
+// @Param format query string false "csv for a CSV download"
+// @Success 200 {object} utils.Response{data=SalesSummary}
+// @Router /admin/marketplace/analytics/summary [get]
+// @Router /merchant/marketplace/analytics/summary [get]
+func (h *MarketplaceHandler) GetSalesSummary(c *gin.Context) {
+	params, err := analyticsParams(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	summary, err := h.service.GetSalesSummary(params)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve sales summary", err.Error())
+		return
+	}
+
+	if writeCSV(c, "sales_summary", params,
+		[]string{"from", "to", "revenue", "units", "transactions", "buyers", "discount", "average_sale"},
+		[][]string{{summary.From, summary.To, itoa(summary.Revenue), itoa(summary.Units), itoa(summary.Transactions), itoa(summary.Buyers), itoa(summary.Discount), ftoa(summary.AverageSale)}},
+	) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Sales summary retrieved successfully", summary)
+}
+
+// GetDailySales handles the sales per day
+// @Summary Get daily sales
+// @Tags Admin - Marketplace Analytics
+// @Security BearerAuth
+// @Produce json,text/csv
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date inclusive (YYYY-MM-DD)"
+// @Param format query string false "csv for a CSV download"
+// @Success 200 {object} utils.Response{data=[]DailySales}
+// @Router /admin/marketplace/analytics/daily [get]
+// @Router /merchant/marketplace/analytics/daily [get]
+func (h *MarketplaceHandler) GetDailySales(c *gin.Context) {
+	params, err := analyticsParams(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	days, err := h.service.GetDailySales(params)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve daily sales", err.Error())
+		return
+	}
+
+	rows := make([][]string, len(days))
+	for i, d := range days {
+		rows[i] = []string{d.Date, itoa(d.Revenue), itoa(d.Units), itoa(d.Transactions)}
+	}
+	if writeCSV(c, "daily_sales", params, []string{"date", "revenue", "units", "transactions"}, rows) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Daily sales retrieved successfully", days)
+}
+
+// GetProductSales handles the sales per product and best sellers
+// @Summary Get product sales
+// @Description Revenue, units and sell-through per product; sort=units with a limit gives the best sellers
+// @Tags Admin - Marketplace Analytics
+// @Security BearerAuth
+// @Produce json,text/csv
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date inclusive (YYYY-MM-DD)"
+// @Param sort query string false "Sort order" Enums(revenue, units)
+// @Param limit query int false "Number of products, 0 for all"
+// @Param format query string false "csv for a CSV download"
+// @Success 200 {object} utils.Response{data=[]ProductSales}
+// @Router /admin/marketplace/analytics/products [get]
+// @Router /merchant/marketplace/analytics/products [get]
+func (h *MarketplaceHandler) GetProductSales(c *gin.Context) {
+	params, err := analyticsParams(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	products, err := h.service.GetProductSales(params)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve product sales", err.Error())
+		return
+	}
+
+	rows := make([][]string, len(products))
+	for i, p := range products {
+		rows[i] = []string{strconv.FormatUint(uint64(p.ProductID), 10), p.ProductName, p.CategoryName, itoa(p.Revenue), itoa(p.Units), itoa(p.Transactions), strconv.Itoa(p.Stock), ftoa(p.SellThrough)}
+	}
+	if writeCSV(c, "product_sales", params, []string{"product_id", "product_name", "category", "revenue", "units", "transactions", "stock", "sell_through_percent"}, rows) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Product sales retrieved successfully", products)
+}
+
+// GetCategorySales handles the sales per category
+// @Summary Get category sales
+// @Tags Admin - Marketplace Analytics
+// @Security BearerAuth
+// @Produce json,text/csv
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date inclusive (YYYY-MM-DD)"
+// @Param format query string false "csv for a CSV download"
+// @Success 200 {object} utils.Response{data=[]CategorySales}
+// @Router /admin/marketplace/analytics/categories [get]
+// @Router /merchant/marketplace/analytics/categories [get]
+func (h *MarketplaceHandler) GetCategorySales(c *gin.Context) {
+	params, err := analyticsParams(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	categories, err := h.service.GetCategorySales(params)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve category sales", err.Error())
+		return
+	}
+
+	rows := make([][]string, len(categories))
+	for i, cat := range categories {
+		id := ""
+		if cat.CategoryID != nil {
+			id = strconv.FormatUint(uint64(*cat.CategoryID), 10)
+		}
+		rows[i] = []string{id, cat.CategoryName, itoa(cat.Revenue), itoa(cat.Units), itoa(cat.Transactions)}
+	}
+	if writeCSV(c, "category_sales", params, []string{"category_id", "category", "revenue", "units", "transactions"}, rows) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Category sales retrieved successfully", categories)
+}
+
+// GetBuyerSegments handles the sales per buyer major or batch
+// @Summary Get buyer breakdown
+// @Tags Admin - Marketplace Analytics
+// @Security BearerAuth
+// @Produce json,text/csv
+// @Param by query string false "Segment buyers by" Enums(major, batch) default(major)
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date inclusive (YYYY-MM-DD)"
+// @Param format query string false "csv for a CSV download"
+// @Success 200 {object} utils.Response{data=[]BuyerSegment}
+// @Router /admin/marketplace/analytics/buyers [get]
+// @Router /merchant/marketplace/analytics/buyers [get]
+func (h *MarketplaceHandler) GetBuyerSegments(c *gin.Context) {
+	params, err := analyticsParams(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	by := c.DefaultQuery("by", "major")
+	segments, err := h.service.GetBuyerSegments(params, by)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	rows := make([][]string, len(segments))
+	for i, seg := range segments {
+		rows[i] = []string{seg.Segment, itoa(seg.Revenue), itoa(seg.Units), itoa(seg.Transactions), itoa(seg.Buyers)}
+	}
+	if writeCSV(c, "buyers_by_"+by, params, []string{by, "revenue", "units", "transactions", "buyers"}, rows) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Buyer breakdown retrieved successfully", segments)
+}
@@ -0,0 +1,61 @@
+package marketplace
+
+import "time"
+
+// AnalyticsParams selects the sales analytics cover: completed sales created
+// in [From, To) and, for merchants, only their own products
+type AnalyticsParams struct {
+	From     time.Time
+	To       time.Time
+	SellerID uint
+	Sort     string // revenue or units
+	Limit    int
+}
+
+type SalesSummary struct {
+	From         string  `json:"from"`
+	To           string  `json:"to"`
+	Revenue      int64   `json:"revenue"`
+	Units        int64   `json:"units"`
+	Transactions int64   `json:"transactions"`
+	Buyers       int64   `json:"buyers"`
+	Discount     int64   `json:"discount"`
+	AverageSale  float64 `json:"average_sale"`
+}
+
+type DailySales struct {
+	Date         string `json:"date"`
+	Revenue      int64  `json:"revenue"`
+	Units        int64  `json:"units"`
+	Transactions int64  `json:"transactions"`
+}
+
+// ProductSales is a product's sales in the range. SellThrough is the share of
+// the units available in the range that sold: units / (units + stock left).
+type ProductSales struct {
+	ProductID    uint    `json:"product_id"`
+	ProductName  string  `json:"product_name"`
+	CategoryName string  `json:"category_name"`
+	Revenue      int64   `json:"revenue"`
+	Units        int64   `json:"units"`
+	Transactions int64   `json:"transactions"`
+	Stock        int     `json:"stock"`
+	SellThrough  float64 `json:"sell_through"` // Percent
+}
+
+type CategorySales struct {
+	CategoryID   *uint  `json:"category_id"`
+	CategoryName string `json:"category_name"`
+	Revenue      int64  `json:"revenue"`
+	Units        int64  `json:"units"`
+	Transactions int64  `json:"transactions"`
+}
+
+// BuyerSegment is the sales to the students of one major or batch
+type BuyerSegment struct {
+	Segment      string `json:"segment"`
+	Revenue      int64  `json:"revenue"`
+	Units        int64  `json:"units"`
+	Transactions int64  `json:"transactions"`
+	Buyers       int64  `json:"buyers"`
+}
@@ -0,0 +1,101 @@
+package marketplace
+
+import (
+	"gorm.io/gorm"
+)
+
+// completedSale is the join condition for sales that count in analytics:
+// successful and not cancelled or refunded
+const completedSale = "mt.status = 'success' AND mt.fulfillment_status NOT IN ('cancelled', 'refunded')"
+
+// salesQuery starts a query over the completed sales in range
+func (r *MarketplaceRepository) salesQuery(params AnalyticsParams) *gorm.DB {
+	query := r.db.Table("marketplace_transactions mt").
+		Joins("JOIN products p ON p.id = mt.product_id").
+		Where(completedSale).
+		Where("mt.created_at >= ? AND mt.created_at < ?", params.From, params.To)
+	if params.SellerID != 0 {
+		query = query.Where("p.created_by = ?", params.SellerID)
+	}
+	return query
+}
+
+// GetSalesSummary totals the sales in range
+func (r *MarketplaceRepository) GetSalesSummary(params AnalyticsParams) (*SalesSummary, error) {
+	var summary SalesSummary
+	err := r.salesQuery(params).
+		Select("COALESCE(SUM(mt.total_amount), 0) AS revenue, COALESCE(SUM(mt.quantity), 0) AS units, COUNT(*) AS transactions, COUNT(DISTINCT mt.wallet_id) AS buyers, COALESCE(SUM(mt.discount_amount), 0) AS discount").
+		Scan(&summary).Error
+	return &summary, err
+}
+
+// GetDailySales totals the sales in range per day; days without sales are missing
+func (r *MarketplaceRepository) GetDailySales(params AnalyticsParams) ([]DailySales, error) {
+	var days []DailySales
+	err := r.salesQuery(params).
+		Select("DATE_FORMAT(mt.created_at, '%Y-%m-%d') AS date, SUM(mt.total_amount) AS revenue, SUM(mt.quantity) AS units, COUNT(*) AS transactions").
+		Group("date").
+		Order("date ASC").
+		Scan(&days).Error
+	return days, err
+}
+
+// GetProductSales totals the sales in range per product, including products
+// that did not sell
+func (r *MarketplaceRepository) GetProductSales(params AnalyticsParams) ([]ProductSales, error) {
+	var products []ProductSales
+
+	query := r.db.Table("products p").
+		Joins("LEFT JOIN categories c ON c.id = p.category_id").
+		Joins("LEFT JOIN marketplace_transactions mt ON mt.product_id = p.id AND "+completedSale+" AND mt.created_at >= ? AND mt.created_at < ?", params.From, params.To).
+		Select("p.id AS product_id, p.name AS product_name, COALESCE(c.name, '') AS category_name, p.stock, COALESCE(SUM(mt.total_amount), 0) AS revenue, COALESCE(SUM(mt.quantity), 0) AS units, COUNT(mt.id) AS transactions").
+		Group("p.id, p.name, c.name, p.stock")
+	if params.SellerID != 0 {
+		query = query.Where("p.created_by = ?", params.SellerID)
+	}
+
+	switch params.Sort {
+	case "units":
+		query = query.Order("units DESC").Order("revenue DESC")
+	default:
+		query = query.Order("revenue DESC").Order("units DESC")
+	}
+	if params.Limit > 0 {
+		query = query.Limit(params.Limit)
+	}
+
+	err := query.Scan(&products).Error
+	return products, err
+}
+
+// GetCategorySales totals the sales in range per category
+func (r *MarketplaceRepository) GetCategorySales(params AnalyticsParams) ([]CategorySales, error) {
+	var categories []CategorySales
+	err := r.salesQuery(params).
+		Joins("LEFT JOIN categories c ON c.id = p.category_id").
+		Select("p.category_id, COALESCE(c.name, 'Uncategorized') AS category_name, SUM(mt.total_amount) AS revenue, SUM(mt.quantity) AS units, COUNT(*) AS transactions").
+		Group("p.category_id, c.name").
+		Order("revenue DESC").
+		Scan(&categories).Error
+	return categories, err
+}
+
+// GetBuyerSegments totals the sales in range per buyer major or batch. The
+// value typed at purchase wins; otherwise the buyer's profile is used.
+func (r *MarketplaceRepository) GetBuyerSegments(params AnalyticsParams, by string) ([]BuyerSegment, error) {
+	column := "major"
+	if by == "batch" {
+		column = "batch"
+	}
+	segment := "COALESCE(NULLIF(mt.student_" + column + ", ''), NULLIF(u." + column + ", ''), 'Unknown')"
+
+	var segments []BuyerSegment
+	err := r.salesQuery(params).
+		Joins("LEFT JOIN wallets w ON w.id = mt.wallet_id").
+		Joins("LEFT JOIN users u ON u.id = w.user_id").
+		Select(segment + " AS segment, SUM(mt.total_amount) AS revenue, SUM(mt.quantity) AS units, COUNT(*) AS transactions, COUNT(DISTINCT mt.wallet_id) AS buyers").
+		Group("segment").
+		Order("revenue DESC").
+		Scan(&segments).Error
+	return segments, err
+}
@@ -0,0 +1,114 @@
+package marketplace
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	analyticsDateLayout = "2006-01-02"
+	maxAnalyticsDays    = 366
+)
+
+// ParseAnalyticsRange turns from/to dates (inclusive, YYYY-MM-DD) into a
+// half-open range. Missing dates default to the last 30 days.
+func ParseAnalyticsRange(from, to string) (time.Time, time.Time, error) {
+	year, month, day := time.Now().Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+
+	end := today
+	if to != "" {
+		parsed, err := time.ParseInLocation(analyticsDateLayout, to, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid to date, use YYYY-MM-DD")
+		}
+		end = parsed
+	}
+	end = end.AddDate(0, 0, 1)
+
+	start := end.AddDate(0, 0, -30)
+	if from != "" {
+		parsed, err := time.ParseInLocation(analyticsDateLayout, from, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid from date, use YYYY-MM-DD")
+		}
+		start = parsed
+	}
+
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, errors.New("from must not be after to")
+	}
+	if end.Sub(start) > maxAnalyticsDays*24*time.Hour {
+		return time.Time{}, time.Time{}, errors.New("date range cannot exceed 366 days")
+	}
+
+	return start, end, nil
+}
+
+// GetSalesSummary totals the sales in range
+func (s *MarketplaceService) GetSalesSummary(params AnalyticsParams) (*SalesSummary, error) {
+	summary, err := s.repo.GetSalesSummary(params)
+	if err != nil {
+		return nil, err
+	}
+
+	summary.From = params.From.Format(analyticsDateLayout)
+	summary.To = params.To.AddDate(0, 0, -1).Format(analyticsDateLayout)
+	if summary.Transactions > 0 {
+		summary.AverageSale = float64(summary.Revenue) / float64(summary.Transactions)
+	}
+	return summary, nil
+}
+
+// GetDailySales gets the sales per day, with days without sales filled in
+func (s *MarketplaceService) GetDailySales(params AnalyticsParams) ([]DailySales, error) {
+	rows, err := s.repo.GetDailySales(params)
+	if err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[string]DailySales, len(rows))
+	for _, row := range rows {
+		byDate[row.Date] = row
+	}
+
+	var days []DailySales
+	for day := params.From; day.Before(params.To); day = day.AddDate(0, 0, 1) {
+		date := day.Format(analyticsDateLayout)
+		row, ok := byDate[date]
+		if !ok {
+			row = DailySales{Date: date}
+		}
+		days = append(days, row)
+	}
+	return days, nil
+}
+
+// GetProductSales gets the sales per product with their sell-through rate
+func (s *MarketplaceService) GetProductSales(params AnalyticsParams) ([]ProductSales, error) {
+	products, err := s.repo.GetProductSales(params)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range products {
+		available := products[i].Units + int64(products[i].Stock)
+		if available > 0 {
+			products[i].SellThrough = float64(products[i].Units) * 100 / float64(available)
+		}
+	}
+	return products, nil
+}
+
+// GetCategorySales gets the sales per category
+func (s *MarketplaceService) GetCategorySales(params AnalyticsParams) ([]CategorySales, error) {
+	return s.repo.GetCategorySales(params)
+}
+
+// GetBuyerSegments gets the sales per buyer major or batch
+func (s *MarketplaceService) GetBuyerSegments(params AnalyticsParams, by string) ([]BuyerSegment, error) {
+	if by != "major" && by != "batch" {
+		return nil, errors.New("by must be major or batch")
+	}
+	return s.repo.GetBuyerSegments(params, by)
+}
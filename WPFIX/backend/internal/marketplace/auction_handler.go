@@ -0,0 +1,261 @@
+package marketplace
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAuction handles opening an auction for an auction product
+// @Summary Create auction
+// @Description Auction one unit of an auction product between starts_at and ends_at (Admin; merchants for their own products)
+// @Tags Admin - Auctions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param request body CreateAuctionRequest true "Auction data"
+// @Success 201 {object} utils.Response{data=Auction}
+// @Failure 400 {object} utils.Response
+// @Router /admin/products/{id}/auctions [post]
+// @Router /merchant/products/{id}/auctions [post]
+func (h *MarketplaceHandler) CreateAuction(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", nil)
+		return
+	}
+
+	var req CreateAuctionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	auction, err := h.service.CreateAuction(uint(productID), &req, userID)
+	if err != nil {
+		h.auctionError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Auction created successfully", auction)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "CREATE_AUCTION",
+		Entity:    "AUCTION",
+		EntityID:  auction.ID,
+		Details:   fmt.Sprintf("%s opened auction for product ID %d from %s to %s, minimum bid %d", actorLabel(c), auction.ProductID, auction.StartsAt.Format("2006-01-02 15:04"), auction.EndsAt.Format("2006-01-02 15:04"), auction.MinimumBid),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// GetAuctions handles listing auctions
+// @Summary Get auctions
+// @Description List auctions; students see open auctions by default and merchants only their own
+// @Tags Marketplace - Auctions
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status" Enums(open, closed, cancelled)
+// @Param product_id query int false "Filter by product"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=AuctionListResponse}
+// @Router /mahasiswa/marketplace/auctions [get]
+// @Router /admin/auctions [get]
+// @Router /merchant/auctions [get]
+func (h *MarketplaceHandler) GetAuctions(c *gin.Context) {
+	productID, _ := strconv.ParseUint(c.Query("product_id"), 10, 32)
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	params := AuctionListParams{
+		Status:    c.Query("status"),
+		ProductID: uint(productID),
+		Page:      page,
+		Limit:     limit,
+	}
+	switch c.GetString("role") {
+	case "mahasiswa":
+		if params.Status == "" {
+			params.Status = "open"
+		}
+	case "merchant":
+		params.SellerID = c.GetUint("user_id")
+	}
+
+	auctions, err := h.service.GetAuctions(params)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve auctions", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Auctions retrieved successfully", auctions)
+}
+
+// GetAuction handles getting an auction with its bids
+// @Summary Get auction
+// @Tags Marketplace - Auctions
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Auction ID"
+// @Success 200 {object} utils.Response{data=Auction}
+// @Failure 404 {object} utils.Response
+// @Router /mahasiswa/marketplace/auctions/{id} [get]
+// @Router /admin/auctions/{id} [get]
+func (h *MarketplaceHandler) GetAuction(c *gin.Context) {
+	auctionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid auction ID", nil)
+		return
+	}
+
+	auction, err := h.service.GetAuction(uint(auctionID))
+	if err != nil {
+		h.auctionError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Auction retrieved successfully", auction)
+}
+
+// GetMyBids handles listing the student's bids
+// @Summary Get my bids
+// @Tags Marketplace - Auctions
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]Bid}
+// @Router /mahasiswa/marketplace/bids [get]
+func (h *MarketplaceHandler) GetMyBids(c *gin.Context) {
+	bids, err := h.service.GetMyBids(c.GetUint("user_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve bids", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Bids retrieved successfully", bids)
+}
+
+// PlaceBid handles bidding on an auction
+// @Summary Place bid
+// @Description Bid on an open auction. The amount is held from the wallet until the bidder is outbid or the auction closes.
+// @Tags Marketplace - Auctions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Auction ID"
+// @Param request body PlaceBidRequest true "Bid amount"
+// @Success 201 {object} utils.Response{data=Bid}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /mahasiswa/marketplace/auctions/{id}/bids [post]
+func (h *MarketplaceHandler) PlaceBid(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	auctionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid auction ID", nil)
+		return
+	}
+
+	var req PlaceBidRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	bid, outbid, err := h.service.PlaceBid(userID, uint(auctionID), &req)
+	if err != nil {
+		h.auctionError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Bid placed successfully", bid)
+
+	// Log activity
+	details := fmt.Sprintf("User bid %d points on auction ID %d", bid.Amount, bid.AuctionID)
+	if outbid != nil {
+		details += fmt.Sprintf(", releasing the %d point hold of user ID %d", outbid.Amount, outbid.UserID)
+	}
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "PLACE_BID",
+		Entity:    "AUCTION",
+		EntityID:  bid.AuctionID,
+		Details:   details,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// CloseAuction handles closing an auction by hand
+// @Summary Close auction
+// @Description Close an auction now: the highest bid wins and is paid to the seller, other holds are released (Admin; merchants for their own products)
+// @Tags Admin - Auctions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Auction ID"
+// @Param request body CloseAuctionRequest false "Note"
+// @Success 200 {object} utils.Response{data=AuctionResult}
+// @Failure 400 {object} utils.Response
+// @Router /admin/auctions/{id}/close [post]
+// @Router /merchant/auctions/{id}/close [post]
+func (h *MarketplaceHandler) CloseAuction(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	auctionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid auction ID", nil)
+		return
+	}
+
+	var req CloseAuctionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ValidationErrorResponse(c, err.Error())
+			return
+		}
+	}
+
+	result, err := h.service.CloseAuction(uint(auctionID), userID, c.GetString("role"), req.Note)
+	if err != nil {
+		h.auctionError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Auction closed successfully", result)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "CLOSE_AUCTION",
+		Entity:    "AUCTION",
+		EntityID:  result.Auction.ID,
+		Details:   auctionCloseDetails(actorLabel(c), result) + " | Note: " + req.Note,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// auctionError maps auction service errors to HTTP status codes
+func (h *MarketplaceHandler) auctionError(c *gin.Context, err error) {
+	statusCode := http.StatusBadRequest
+	var eligibilityErr *EligibilityError
+	if errors.As(err, &eligibilityErr) {
+		statusCode = http.StatusForbidden
+	}
+	switch err.Error() {
+	case "auction not found", "product not found":
+		statusCode = http.StatusNotFound
+	case "you are not allowed to close this auction":
+		statusCode = http.StatusForbidden
+	}
+	utils.ErrorResponse(c, statusCode, err.Error(), nil)
+}
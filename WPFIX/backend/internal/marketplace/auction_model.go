@@ -0,0 +1,93 @@
+package marketplace
+
+import "time"
+
+// AuctionCloseInterval is how often the closer looks for auctions past their end time
+const AuctionCloseInterval = time.Minute
+
+// Auction sells one unit of an auction product to the highest bidder. Every
+// bid holds its amount from the bidder's wallet; a bidder's hold is released
+// when they are outbid, and the winning hold is paid to the seller at close.
+type Auction struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	ProductID     uint       `json:"product_id" gorm:"not null;index"`
+	StartsAt      time.Time  `json:"starts_at" gorm:"not null"`
+	EndsAt        time.Time  `json:"ends_at" gorm:"not null;index:idx_auction_status_end"`
+	MinimumBid    int        `json:"minimum_bid" gorm:"not null"`
+	BidIncrement  int        `json:"bid_increment" gorm:"not null"`
+	Status        string     `json:"status" gorm:"type:enum('open','closed','cancelled');default:'open';index:idx_auction_status_end"`
+	CurrentBid    int        `json:"current_bid" gorm:"default:0;not null"`
+	BidCount      int        `json:"bid_count" gorm:"default:0;not null"`
+	HighestBidID  *uint      `json:"highest_bid_id"`
+	WinnerID      *uint      `json:"winner_id"`      // User who won the auction
+	TransactionID *uint      `json:"transaction_id"` // Sale created for the winner
+	CloseNote     string     `json:"close_note,omitempty" gorm:"size:255"`
+	ClosedAt      *time.Time `json:"closed_at"`
+	ClosedBy      *uint      `json:"closed_by"` // Empty when closed by the scheduler
+	CreatedBy     uint       `json:"created_by" gorm:"not null"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	Product *Product `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+	Bids    []Bid    `json:"bids,omitempty" gorm:"foreignKey:AuctionID"`
+}
+
+func (Auction) TableName() string {
+	return "auctions"
+}
+
+// Bid is one bid on an auction. Its amount is held from the bidder's wallet
+// while it is the highest bid; a bid the same bidder raised passes its hold on
+// to the new bid.
+type Bid struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	AuctionID  uint       `json:"auction_id" gorm:"not null;index:idx_bid_auction_status"`
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	WalletID   uint       `json:"wallet_id" gorm:"not null"`
+	Amount     int        `json:"amount" gorm:"not null"`
+	Status     string     `json:"status" gorm:"type:enum('held','raised','outbid','won','released');default:'held';index:idx_bid_auction_status"`
+	ReleasedAt *time.Time `json:"released_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (Bid) TableName() string {
+	return "auction_bids"
+}
+
+type CreateAuctionRequest struct {
+	StartsAt     *time.Time `json:"starts_at"` // Defaults to now
+	EndsAt       time.Time  `json:"ends_at" binding:"required"`
+	MinimumBid   int        `json:"minimum_bid" binding:"required,gt=0"`
+	BidIncrement int        `json:"bid_increment" binding:"omitempty,gt=0"` // Defaults to 1
+}
+
+type PlaceBidRequest struct {
+	Amount int `json:"amount" binding:"required,gt=0"`
+}
+
+type CloseAuctionRequest struct {
+	Note string `json:"note" binding:"max=255"`
+}
+
+type AuctionListParams struct {
+	Status    string
+	ProductID uint
+	SellerID  uint // Limits the list to auctions of this seller's products
+	Page      int
+	Limit     int
+}
+
+type AuctionListResponse struct {
+	Auctions   []Auction `json:"auctions"`
+	Total      int64     `json:"total"`
+	Page       int       `json:"page"`
+	Limit      int       `json:"limit"`
+	TotalPages int       `json:"total_pages"`
+}
+
+// AuctionResult describes how an auction was closed, for the audit trail
+type AuctionResult struct {
+	Auction  *Auction `json:"auction"`
+	Released int      `json:"released"` // Holds given back to losing bidders
+}
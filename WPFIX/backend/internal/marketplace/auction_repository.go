@@ -0,0 +1,138 @@
+package marketplace
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateAuction creates an auction
+func (r *MarketplaceRepository) CreateAuction(tx *gorm.DB, auction *Auction) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Create(auction).Error
+}
+
+// FindAuction finds an auction with its product and bids, highest first
+func (r *MarketplaceRepository) FindAuction(auctionID uint) (*Auction, error) {
+	var auction Auction
+	err := r.db.Preload("Product").
+		Preload("Bids", func(db *gorm.DB) *gorm.DB {
+			return db.Order("amount DESC, id ASC")
+		}).
+		First(&auction, auctionID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("auction not found")
+		}
+		return nil, err
+	}
+	return &auction, nil
+}
+
+// LockAuctionWithTx finds an auction and locks the row
+func (r *MarketplaceRepository) LockAuctionWithTx(tx *gorm.DB, auctionID uint) (*Auction, error) {
+	var auction Auction
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&auction, auctionID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("auction not found")
+		}
+		return nil, err
+	}
+	return &auction, nil
+}
+
+// HasOpenAuction reports whether a product already has an open auction
+func (r *MarketplaceRepository) HasOpenAuction(tx *gorm.DB, productID uint) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	var count int64
+	err := tx.Model(&Auction{}).Where("product_id = ? AND status = ?", productID, "open").Count(&count).Error
+	return count > 0, err
+}
+
+// UpdateAuctionWithTx updates an auction
+func (r *MarketplaceRepository) UpdateAuctionWithTx(tx *gorm.DB, auctionID uint, updates map[string]interface{}) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Model(&Auction{}).Where("id = ?", auctionID).Updates(updates).Error
+}
+
+// FindEndedAuctionIDs lists open auctions whose end time has passed
+func (r *MarketplaceRepository) FindEndedAuctionIDs(now time.Time) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&Auction{}).
+		Where("status = ? AND ends_at <= ?", "open", now).
+		Order("ends_at ASC").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// GetAuctions lists auctions with their products
+func (r *MarketplaceRepository) GetAuctions(params AuctionListParams) ([]Auction, int64, error) {
+	var auctions []Auction
+	var total int64
+
+	query := r.db.Model(&Auction{})
+	if params.Status != "" {
+		query = query.Where("auctions.status = ?", params.Status)
+	}
+	if params.ProductID != 0 {
+		query = query.Where("auctions.product_id = ?", params.ProductID)
+	}
+	if params.SellerID != 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM products p WHERE p.id = auctions.product_id AND p.created_by = ?)", params.SellerID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Preload("Product").
+		Order("auctions.ends_at ASC").
+		Limit(params.Limit).
+		Offset(offset).
+		Find(&auctions).Error
+
+	return auctions, total, err
+}
+
+// CreateBid creates a bid record
+func (r *MarketplaceRepository) CreateBid(tx *gorm.DB, bid *Bid) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Create(bid).Error
+}
+
+// LockHeldBidsWithTx loads the bids of an auction whose amount is still held and locks them
+func (r *MarketplaceRepository) LockHeldBidsWithTx(tx *gorm.DB, auctionID uint) ([]Bid, error) {
+	var bids []Bid
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("auction_id = ? AND status = ?", auctionID, "held").
+		Order("id ASC").
+		Find(&bids).Error
+	return bids, err
+}
+
+// UpdateBidWithTx updates a bid
+func (r *MarketplaceRepository) UpdateBidWithTx(tx *gorm.DB, bidID uint, updates map[string]interface{}) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Model(&Bid{}).Where("id = ?", bidID).Updates(updates).Error
+}
+
+// GetBidsByUser lists a user's bids, newest first
+func (r *MarketplaceRepository) GetBidsByUser(userID uint) ([]Bid, error) {
+	var bids []Bid
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&bids).Error
+	return bids, err
+}
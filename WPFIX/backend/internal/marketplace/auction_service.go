@@ -0,0 +1,440 @@
+package marketplace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+	"wallet-point/internal/audit"
+	"wallet-point/internal/notification"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+// isAuction reports whether a product is sold to the highest bidder
+func (p *Product) isAuction() bool {
+	return p.Type == "auction"
+}
+
+// CreateAuction opens an auction for one unit of an auction product. A
+// product has at most one open auction at a time.
+func (s *MarketplaceService) CreateAuction(productID uint, req *CreateAuctionRequest, actorID uint) (*Auction, error) {
+	now := time.Now()
+	startsAt := now
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+	}
+	if !req.EndsAt.After(startsAt) || !req.EndsAt.After(now) {
+		return nil, errors.New("ends_at must be in the future and after starts_at")
+	}
+
+	increment := req.BidIncrement
+	if increment <= 0 {
+		increment = 1
+	}
+
+	var auction *Auction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		product, err := s.repo.FindByIDWithTx(tx, productID)
+		if err != nil {
+			return err
+		}
+		if !product.isAuction() {
+			return errors.New("only auction products can be auctioned")
+		}
+		if product.Stock < 1 {
+			return errors.New("product is out of stock")
+		}
+
+		open, err := s.repo.HasOpenAuction(tx, product.ID)
+		if err != nil {
+			return err
+		}
+		if open {
+			return errors.New("product already has an open auction")
+		}
+
+		auction = &Auction{
+			ProductID:    product.ID,
+			StartsAt:     startsAt,
+			EndsAt:       req.EndsAt,
+			MinimumBid:   req.MinimumBid,
+			BidIncrement: increment,
+			Status:       "open",
+			CreatedBy:    actorID,
+		}
+		return s.repo.CreateAuction(tx, auction)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindAuction(auction.ID)
+}
+
+// GetAuctions lists auctions with pagination
+func (s *MarketplaceService) GetAuctions(params AuctionListParams) (*AuctionListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+
+	auctions, total, err := s.repo.GetAuctions(params)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(params.Limit)))
+
+	return &AuctionListResponse{
+		Auctions:   auctions,
+		Total:      total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// GetAuction gets an auction with its bids
+func (s *MarketplaceService) GetAuction(auctionID uint) (*Auction, error) {
+	return s.repo.FindAuction(auctionID)
+}
+
+// GetMyBids gets a student's bids across all auctions
+func (s *MarketplaceService) GetMyBids(userID uint) ([]Bid, error) {
+	return s.repo.GetBidsByUser(userID)
+}
+
+// PlaceBid places a bid and holds its amount from the bidder's wallet. The
+// hold of the previous highest bidder is released; when the highest bidder
+// raises their own bid only the difference is held. Returns the new bid and
+// the bid it outbid, if any.
+func (s *MarketplaceService) PlaceBid(userID, auctionID uint, req *PlaceBidRequest) (*Bid, *Bid, error) {
+	var bid, outbid *Bid
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// 1. Lock the auction so bids are applied one at a time
+		auction, err := s.repo.LockAuctionWithTx(tx, auctionID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if auction.Status != "open" {
+			return fmt.Errorf("auction is %s", auction.Status)
+		}
+		if now.Before(auction.StartsAt) {
+			return errors.New("auction has not started yet")
+		}
+		if !now.Before(auction.EndsAt) {
+			return errors.New("auction has ended")
+		}
+
+		// Lock the bidder's wallet before the product, as purchases do, so
+		// concurrent bids and purchases cannot hold more than the balance
+		bidderWallet, err := s.walletService.LockWalletByUserIDWithTx(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		product, err := s.repo.FindByIDWithTx(tx, auction.ProductID)
+		if err != nil {
+			return err
+		}
+		if product.Status != "active" {
+			return errors.New("product is not active")
+		}
+		if product.CreatedBy == userID {
+			return errors.New("you cannot bid on your own auction")
+		}
+		if err := s.checkEligibility(tx, userID, bidderWallet.ID, product, 1); err != nil {
+			return err
+		}
+
+		// 2. The first bid must reach the minimum, later bids must beat the current one by the increment
+		minimum := auction.MinimumBid
+		if auction.HighestBidID != nil {
+			minimum = auction.CurrentBid + auction.BidIncrement
+		}
+		if req.Amount < minimum {
+			return fmt.Errorf("bid must be at least %d", minimum)
+		}
+
+		// 3. Release the previous highest hold, or carry it over when the bidder raises their own bid
+		held, err := s.repo.LockHeldBidsWithTx(tx, auction.ID)
+		if err != nil {
+			return err
+		}
+		toHold := req.Amount
+		for i := range held {
+			previous := held[i]
+			if previous.UserID == userID {
+				toHold -= previous.Amount
+				if err := s.repo.UpdateBidWithTx(tx, previous.ID, map[string]interface{}{"status": "raised"}); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := s.releaseBid(tx, product, auction, &previous, "outbid"); err != nil {
+				return err
+			}
+			outbid = &previous
+		}
+
+		// 4. Hold the new bid
+		if bidderWallet.Balance < toHold {
+			return errors.New("insufficient balance")
+		}
+		if err := s.walletService.DebitWithTransaction(tx, bidderWallet.ID, toHold, "auction_hold", fmt.Sprintf("Hold for bid of %d on %s (auction #%d)", req.Amount, product.Name, auction.ID)); err != nil {
+			return err
+		}
+
+		bid = &Bid{
+			AuctionID: auction.ID,
+			UserID:    userID,
+			WalletID:  bidderWallet.ID,
+			Amount:    req.Amount,
+			Status:    "held",
+		}
+		if err := s.repo.CreateBid(tx, bid); err != nil {
+			return err
+		}
+
+		if err := s.repo.UpdateAuctionWithTx(tx, auction.ID, map[string]interface{}{
+			"current_bid":    bid.Amount,
+			"highest_bid_id": bid.ID,
+			"bid_count":      gorm.Expr("bid_count + 1"),
+		}); err != nil {
+			return err
+		}
+
+		if outbid != nil {
+			s.notificationService.Notify(tx, notification.Notification{
+				UserID:        outbid.UserID,
+				Type:          notification.TypeOutbid,
+				Title:         "You were outbid on " + product.Name,
+				Message:       fmt.Sprintf("Someone bid %d points. Your hold of %d points was released.", bid.Amount, outbid.Amount),
+				ReferenceType: "auction",
+				ReferenceID:   &auction.ID,
+			})
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return bid, outbid, nil
+}
+
+// releaseBid gives a bid's held points back to the bidder
+func (s *MarketplaceService) releaseBid(tx *gorm.DB, product *Product, auction *Auction, bid *Bid, status string) error {
+	if err := s.walletService.CreditWithTransaction(tx, bid.WalletID, bid.Amount, "auction_release", fmt.Sprintf("Release hold for bid on %s (auction #%d)", product.Name, auction.ID)); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	bid.Status = status
+	bid.ReleasedAt = &now
+	return s.repo.UpdateBidWithTx(tx, bid.ID, map[string]interface{}{
+		"status":      status,
+		"released_at": now,
+	})
+}
+
+// CloseAuction closes an auction before or after its end time (Admin or the product owner)
+func (s *MarketplaceService) CloseAuction(auctionID, actorID uint, role string, note string) (*AuctionResult, error) {
+	var result *AuctionResult
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		auction, err := s.repo.LockAuctionWithTx(tx, auctionID)
+		if err != nil {
+			return err
+		}
+
+		product, err := s.repo.FindByIDWithTx(tx, auction.ProductID)
+		if err != nil {
+			return err
+		}
+		if !canFulfil(product, actorID, role) {
+			return errors.New("you are not allowed to close this auction")
+		}
+		if auction.Status != "open" {
+			return fmt.Errorf("auction is already %s", auction.Status)
+		}
+
+		result, err = s.closeAuction(tx, auction, product, &actorID, note)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CloseEndedAuctions closes every open auction past its end time
+func (s *MarketplaceService) CloseEndedAuctions() {
+	ids, err := s.repo.FindEndedAuctionIDs(time.Now())
+	if err != nil {
+		log.Printf("[auction] failed to find ended auctions: %v", err)
+		return
+	}
+
+	for _, id := range ids {
+		var result *AuctionResult
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			auction, err := s.repo.LockAuctionWithTx(tx, id)
+			if err != nil {
+				return err
+			}
+			// Closed manually or extended since it was listed
+			if auction.Status != "open" || time.Now().Before(auction.EndsAt) {
+				return nil
+			}
+
+			product, err := s.repo.FindByIDWithTx(tx, auction.ProductID)
+			if err != nil {
+				return err
+			}
+
+			result, err = s.closeAuction(tx, auction, product, nil, "")
+			return err
+		})
+		if err != nil {
+			log.Printf("[auction] failed to close auction %d: %v", id, err)
+			continue
+		}
+		if result != nil {
+			s.auditService.LogActivity(audit.CreateAuditParams{
+				Action:   "CLOSE_AUCTION",
+				Entity:   "AUCTION",
+				EntityID: id,
+				Details:  auctionCloseDetails("Scheduler", result),
+			})
+		}
+	}
+}
+
+// RunAuctionCloser closes ended auctions every interval until ctx is cancelled
+func (s *MarketplaceService) RunAuctionCloser(ctx context.Context, interval time.Duration) {
+	utils.RunEvery(ctx, interval, s.CloseEndedAuctions)
+}
+
+// closeAuction settles a locked auction: the winning hold is captured as the
+// sale and paid to the seller, the unit is taken from stock and every other
+// hold is released. If no stock is left the auction is cancelled and the
+// winner is released as well.
+func (s *MarketplaceService) closeAuction(tx *gorm.DB, auction *Auction, product *Product, actorID *uint, note string) (*AuctionResult, error) {
+	held, err := s.repo.LockHeldBidsWithTx(tx, auction.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var winner *Bid
+	for i := range held {
+		if winner == nil || held[i].Amount > winner.Amount {
+			winner = &held[i]
+		}
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":     "closed",
+		"closed_at":  now,
+		"closed_by":  actorID,
+		"close_note": note,
+	}
+	auction.Status = "closed"
+
+	// 1. Take the unit being sold
+	if winner != nil {
+		if err := s.takeStock(tx, product, nil, 1); err != nil {
+			updates["status"] = "cancelled"
+			updates["close_note"] = err.Error()
+			auction.Status = "cancelled"
+			winner = nil
+		}
+	}
+
+	// 2. Release everyone but the winner
+	result := &AuctionResult{Auction: auction}
+	for i := range held {
+		if winner != nil && held[i].ID == winner.ID {
+			continue
+		}
+		if err := s.releaseBid(tx, product, auction, &held[i], "released"); err != nil {
+			return nil, err
+		}
+		result.Released++
+	}
+
+	// 3. Capture the winning hold: pay the seller and record the sale
+	if winner != nil {
+		sellerWallet, err := s.sellerWallet(product)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.walletService.CreditWithTransaction(tx, sellerWallet.ID, winner.Amount, "marketplace_sale", fmt.Sprintf("Auction #%d sale of %s", auction.ID, product.Name)); err != nil {
+			return nil, err
+		}
+
+		txn := &MarketplaceTransaction{
+			WalletID:          winner.WalletID,
+			ProductID:         product.ID,
+			Amount:            winner.Amount,
+			TotalAmount:       winner.Amount,
+			OriginalAmount:    winner.Amount,
+			Quantity:          1,
+			PaymentMethod:     "auction",
+			Status:            "success",
+			FulfillmentStatus: "paid",
+			PickupCode:        newPickupCode(),
+		}
+		if err := s.repo.CreateTransaction(tx, txn); err != nil {
+			return nil, err
+		}
+		if err := s.repo.UpdateBidWithTx(tx, winner.ID, map[string]interface{}{"status": "won"}); err != nil {
+			return nil, err
+		}
+
+		updates["winner_id"] = winner.UserID
+		updates["transaction_id"] = txn.ID
+		auction.WinnerID = &winner.UserID
+		auction.TransactionID = &txn.ID
+
+		s.notificationService.Notify(tx, notification.Notification{
+			UserID:        winner.UserID,
+			Type:          notification.TypeAuctionWon,
+			Title:         "You won the auction for " + product.Name,
+			Message:       fmt.Sprintf("Your bid of %d points won. Show pickup code %s to collect it.", winner.Amount, txn.PickupCode),
+			ReferenceType: "auction",
+			ReferenceID:   &auction.ID,
+		})
+	}
+
+	if err := s.repo.UpdateAuctionWithTx(tx, auction.ID, updates); err != nil {
+		return nil, err
+	}
+	auction.ClosedAt = &now
+	auction.ClosedBy = actorID
+	auction.Product = product
+
+	return result, nil
+}
+
+// auctionCloseDetails describes a closed auction for the audit log
+func auctionCloseDetails(actor string, result *AuctionResult) string {
+	auction := result.Auction
+	if auction.WinnerID == nil {
+		return fmt.Sprintf("%s %s auction ID %d without a winner, released %d holds", actor, auction.Status, auction.ID, result.Released)
+	}
+	return fmt.Sprintf("%s closed auction ID %d: user ID %d won with %d points (transaction ID %d), released %d holds",
+		actor, auction.ID, *auction.WinnerID, auction.CurrentBid, *auction.TransactionID, result.Released)
+}
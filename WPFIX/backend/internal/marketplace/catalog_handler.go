@@ -0,0 +1,316 @@
+package marketplace
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCategories handles listing product categories
+// @Summary Get categories
+// @Tags Marketplace - Catalog
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]Category}
+// @Router /admin/categories [get]
+func (h *MarketplaceHandler) GetCategories(c *gin.Context) {
+	categories, err := h.service.GetCategories()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve categories", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Categories retrieved successfully", categories)
+}
+
+// CreateCategory handles creating a product category
+// @Summary Create category
+// @Description Create a product category (Admin only)
+// @Tags Marketplace - Catalog
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateCategoryRequest true "Category details"
+// @Success 201 {object} utils.Response{data=Category}
+// @Failure 400 {object} utils.Response
+// @Router /admin/categories [post]
+func (h *MarketplaceHandler) CreateCategory(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+
+	var req CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	category, err := h.service.CreateCategory(&req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Category created successfully", category)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "CREATE_CATEGORY",
+		Entity:    "CATEGORY",
+		EntityID:  category.ID,
+		Details:   "Admin created category: " + category.Name,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// UpdateCategory handles updating a product category
+// @Summary Update category
+// @Tags Marketplace - Catalog
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Category ID"
+// @Param request body UpdateCategoryRequest true "Update data"
+// @Success 200 {object} utils.Response{data=Category}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/categories/{id} [put]
+func (h *MarketplaceHandler) UpdateCategory(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID", nil)
+		return
+	}
+
+	var req UpdateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	category, err := h.service.UpdateCategory(uint(categoryID), &req)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "category not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Category updated successfully", category)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "UPDATE_CATEGORY",
+		Entity:    "CATEGORY",
+		EntityID:  category.ID,
+		Details:   "Admin updated category: " + category.Name,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// DeleteCategory handles deleting a product category
+// @Summary Delete category
+// @Description Delete a category; its products become uncategorised (Admin only)
+// @Tags Marketplace - Catalog
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Category ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/categories/{id} [delete]
+func (h *MarketplaceHandler) DeleteCategory(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID", nil)
+		return
+	}
+
+	if err := h.service.DeleteCategory(uint(categoryID)); err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "category not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Category deleted successfully", nil)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "DELETE_CATEGORY",
+		Entity:    "CATEGORY",
+		EntityID:  uint(categoryID),
+		Details:   "Admin deleted category ID: " + strconv.FormatUint(categoryID, 10),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// AddVariant handles adding a variant to a product
+// @Summary Add product variant
+// @Description Add a size/colour variant with its own price and stock (Admin only)
+// @Tags Marketplace - Catalog
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param request body CreateVariantRequest true "Variant details"
+// @Success 201 {object} utils.Response{data=ProductVariant}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/products/{id}/variants [post]
+// @Router /merchant/products/{id}/variants [post]
+func (h *MarketplaceHandler) AddVariant(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", nil)
+		return
+	}
+
+	var req CreateVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	variant, err := h.service.AddVariant(uint(productID), &req)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "product not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Variant created successfully", variant)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "CREATE_VARIANT",
+		Entity:    "PRODUCT",
+		EntityID:  uint(productID),
+		Details:   fmt.Sprintf("%s added variant %s (price %d, stock %d)", actorLabel(c), variant.Name, variant.Price, variant.Stock),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// UpdateVariant handles updating a product variant
+// @Summary Update product variant
+// @Tags Marketplace - Catalog
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param variantId path int true "Variant ID"
+// @Param request body UpdateVariantRequest true "Update data"
+// @Success 200 {object} utils.Response{data=ProductVariant}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/products/{id}/variants/{variantId} [put]
+// @Router /merchant/products/{id}/variants/{variantId} [put]
+func (h *MarketplaceHandler) UpdateVariant(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", nil)
+		return
+	}
+	variantID, err := strconv.ParseUint(c.Param("variantId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid variant ID", nil)
+		return
+	}
+
+	var req UpdateVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	variant, err := h.service.UpdateVariant(uint(productID), uint(variantID), &req)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "variant not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Variant updated successfully", variant)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "UPDATE_VARIANT",
+		Entity:    "PRODUCT",
+		EntityID:  uint(productID),
+		Details:   fmt.Sprintf("%s updated variant %s (price %d, stock %d, %s)", actorLabel(c), variant.Name, variant.Price, variant.Stock, variant.Status),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// DeleteVariant handles removing a product variant
+// @Summary Delete product variant
+// @Description Deactivate a product variant (Admin only)
+// @Tags Marketplace - Catalog
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param variantId path int true "Variant ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/products/{id}/variants/{variantId} [delete]
+// @Router /merchant/products/{id}/variants/{variantId} [delete]
+func (h *MarketplaceHandler) DeleteVariant(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", nil)
+		return
+	}
+	variantID, err := strconv.ParseUint(c.Param("variantId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid variant ID", nil)
+		return
+	}
+
+	if err := h.service.DeleteVariant(uint(productID), uint(variantID)); err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "variant not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Variant deleted successfully", nil)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "DELETE_VARIANT",
+		Entity:    "PRODUCT",
+		EntityID:  uint(productID),
+		Details:   actorLabel(c) + " deactivated variant ID: " + strconv.FormatUint(variantID, 10),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
@@ -0,0 +1,75 @@
+package marketplace
+
+import "time"
+
+type Category struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"size:100;not null;uniqueIndex"`
+	Slug        string    `json:"slug" gorm:"size:120;not null;uniqueIndex"`
+	Description string    `json:"description" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (Category) TableName() string {
+	return "categories"
+}
+
+// ProductVariant is a purchasable option of a product (e.g. size or colour)
+// with its own price and stock. When a product has variants, the product
+// stock is kept as the sum of its variants' stock.
+type ProductVariant struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ProductID uint      `json:"product_id" gorm:"not null;index"`
+	Name      string    `json:"name" gorm:"size:100;not null"`
+	Size      string    `json:"size" gorm:"size:50"`
+	Color     string    `json:"color" gorm:"size:50"`
+	SKU       string    `json:"sku" gorm:"size:100"`
+	Price     int       `json:"price" gorm:"not null"`
+	Stock     int       `json:"stock" gorm:"default:0;not null"`
+	Status    string    `json:"status" gorm:"type:enum('active','inactive');default:'active'"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (ProductVariant) TableName() string {
+	return "product_variants"
+}
+
+type Tag struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name" gorm:"size:50;not null;uniqueIndex"`
+}
+
+func (Tag) TableName() string {
+	return "tags"
+}
+
+type CreateCategoryRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description"`
+}
+
+type UpdateCategoryRequest struct {
+	Name        string `json:"name,omitempty" binding:"omitempty,max=100"`
+	Description string `json:"description,omitempty"`
+}
+
+type CreateVariantRequest struct {
+	Name  string `json:"name" binding:"required,max=100"`
+	Size  string `json:"size" binding:"max=50"`
+	Color string `json:"color" binding:"max=50"`
+	SKU   string `json:"sku" binding:"max=100"`
+	Price int    `json:"price" binding:"required,gt=0"`
+	Stock int    `json:"stock" binding:"gte=0"`
+}
+
+type UpdateVariantRequest struct {
+	Name   string `json:"name,omitempty" binding:"omitempty,max=100"`
+	Size   string `json:"size,omitempty" binding:"omitempty,max=50"`
+	Color  string `json:"color,omitempty" binding:"omitempty,max=50"`
+	SKU    string `json:"sku,omitempty" binding:"omitempty,max=100"`
+	Price  int    `json:"price,omitempty" binding:"omitempty,gt=0"`
+	Stock  *int   `json:"stock,omitempty" binding:"omitempty,gte=0"`
+	Status string `json:"status,omitempty" binding:"omitempty,oneof=active inactive"`
+}
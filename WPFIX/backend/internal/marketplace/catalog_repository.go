@@ -0,0 +1,186 @@
+package marketplace
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// normalizeTag lower-cases and trims a tag name
+func normalizeTag(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// fullTextQuery turns free text into a MySQL boolean-mode query where every
+// word is required and prefix-matched. Boolean operators typed by the user
+// are stripped so they cannot change the meaning of the search.
+func fullTextQuery(search string) string {
+	var terms []string
+	for _, word := range strings.Fields(search) {
+		word = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(`+-<>()~*"@`, r) {
+				return -1
+			}
+			return r
+		}, word)
+		if word != "" {
+			terms = append(terms, "+"+word+"*")
+		}
+	}
+	return strings.Join(terms, " ")
+}
+
+// GetCategories gets all categories ordered by name
+func (r *MarketplaceRepository) GetCategories() ([]Category, error) {
+	var categories []Category
+	err := r.db.Order("name ASC").Find(&categories).Error
+	return categories, err
+}
+
+// FindCategoryByID finds a category by ID
+func (r *MarketplaceRepository) FindCategoryByID(categoryID uint) (*Category, error) {
+	var category Category
+	err := r.db.First(&category, categoryID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("category not found")
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// CreateCategory creates a category
+func (r *MarketplaceRepository) CreateCategory(category *Category) error {
+	return r.db.Create(category).Error
+}
+
+// UpdateCategory updates a category
+func (r *MarketplaceRepository) UpdateCategory(categoryID uint, updates map[string]interface{}) error {
+	return r.db.Model(&Category{}).Where("id = ?", categoryID).Updates(updates).Error
+}
+
+// DeleteCategory removes a category and detaches its products
+func (r *MarketplaceRepository) DeleteCategory(categoryID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Product{}).Where("category_id = ?", categoryID).Update("category_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Category{}, categoryID).Error
+	})
+}
+
+// FindOrCreateTags resolves tag names to tag records, creating missing ones
+func (r *MarketplaceRepository) FindOrCreateTags(names []string) ([]Tag, error) {
+	seen := make(map[string]bool)
+	var tags []Tag
+	for _, name := range names {
+		name = normalizeTag(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		tag := Tag{Name: name}
+		if err := r.db.Where("name = ?", name).FirstOrCreate(&tag).Error; err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// ReplaceTags sets the tags of a product
+func (r *MarketplaceRepository) ReplaceTags(productID uint, tags []Tag) error {
+	return r.db.Model(&Product{ID: productID}).Association("Tags").Replace(tags)
+}
+
+// FindVariant finds a variant belonging to a product
+func (r *MarketplaceRepository) FindVariant(productID, variantID uint) (*ProductVariant, error) {
+	var variant ProductVariant
+	err := r.db.Where("id = ? AND product_id = ?", variantID, productID).First(&variant).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("variant not found")
+		}
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// FindVariantWithTx finds a variant belonging to a product and locks the row
+func (r *MarketplaceRepository) FindVariantWithTx(tx *gorm.DB, productID, variantID uint) (*ProductVariant, error) {
+	var variant ProductVariant
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND product_id = ?", variantID, productID).
+		First(&variant).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("variant not found")
+		}
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// CreateVariant creates a product variant
+func (r *MarketplaceRepository) CreateVariant(tx *gorm.DB, variant *ProductVariant) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Create(variant).Error
+}
+
+// UpdateVariant updates a product variant
+func (r *MarketplaceRepository) UpdateVariant(tx *gorm.DB, variantID uint, updates map[string]interface{}) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Model(&ProductVariant{}).Where("id = ?", variantID).Updates(updates).Error
+}
+
+// DecrementVariantStock takes quantity units out of a variant's stock only if enough remain.
+// It reports false when the guarded update matched no row.
+func (r *MarketplaceRepository) DecrementVariantStock(tx *gorm.DB, variantID uint, quantity int) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	result := tx.Model(&ProductVariant{}).
+		Where("id = ? AND stock >= ?", variantID, quantity).
+		Update("stock", gorm.Expr("stock - ?", quantity))
+	return result.RowsAffected == 1, result.Error
+}
+
+// UpdateVariantStock updates variant stock
+func (r *MarketplaceRepository) UpdateVariantStock(tx *gorm.DB, variantID uint, delta int) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Model(&ProductVariant{}).
+		Where("id = ?", variantID).
+		Update("stock", gorm.Expr("stock + ?", delta)).
+		Error
+}
+
+// SyncStockFromVariants sets a product's stock to the sum of its active variants
+func (r *MarketplaceRepository) SyncStockFromVariants(tx *gorm.DB, productID uint) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Model(&Product{}).
+		Where("id = ?", productID).
+		Update("stock", gorm.Expr("(SELECT COALESCE(SUM(pv.stock), 0) FROM product_variants pv WHERE pv.product_id = ? AND pv.status = 'active')", productID)).
+		Error
+}
+
+// HasActiveVariants reports whether a product must be bought through one of its variants
+func (r *MarketplaceRepository) HasActiveVariants(tx *gorm.DB, productID uint) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	var count int64
+	err := tx.Model(&ProductVariant{}).Where("product_id = ? AND status = ?", productID, "active").Count(&count).Error
+	return count > 0, err
+}
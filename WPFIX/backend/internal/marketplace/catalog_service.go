@@ -0,0 +1,224 @@
+package marketplace
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// slugify builds a URL-friendly slug from a category name
+func slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// GetCategories gets all categories
+func (s *MarketplaceService) GetCategories() ([]Category, error) {
+	return s.repo.GetCategories()
+}
+
+// CreateCategory creates a category
+func (s *MarketplaceService) CreateCategory(req *CreateCategoryRequest) (*Category, error) {
+	category := &Category{
+		Name:        strings.TrimSpace(req.Name),
+		Slug:        slugify(req.Name),
+		Description: req.Description,
+	}
+	if category.Slug == "" {
+		return nil, errors.New("category name must contain letters or digits")
+	}
+
+	if err := s.repo.CreateCategory(category); err != nil {
+		return nil, errors.New("failed to create category, the name may already exist")
+	}
+
+	return category, nil
+}
+
+// UpdateCategory updates a category
+func (s *MarketplaceService) UpdateCategory(categoryID uint, req *UpdateCategoryRequest) (*Category, error) {
+	if _, err := s.repo.FindCategoryByID(categoryID); err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != "" {
+		slug := slugify(req.Name)
+		if slug == "" {
+			return nil, errors.New("category name must contain letters or digits")
+		}
+		updates["name"] = strings.TrimSpace(req.Name)
+		updates["slug"] = slug
+	}
+	if req.Description != "" {
+		updates["description"] = req.Description
+	}
+
+	if len(updates) > 0 {
+		if err := s.repo.UpdateCategory(categoryID, updates); err != nil {
+			return nil, errors.New("failed to update category, the name may already exist")
+		}
+	}
+
+	return s.repo.FindCategoryByID(categoryID)
+}
+
+// DeleteCategory deletes a category, leaving its products uncategorised
+func (s *MarketplaceService) DeleteCategory(categoryID uint) error {
+	if _, err := s.repo.FindCategoryByID(categoryID); err != nil {
+		return err
+	}
+	return s.repo.DeleteCategory(categoryID)
+}
+
+// AddVariant adds a variant to a product and resyncs the product stock
+func (s *MarketplaceService) AddVariant(productID uint, req *CreateVariantRequest) (*ProductVariant, error) {
+	product, err := s.repo.FindByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.isDigital() || product.isAuction() {
+		return nil, fmt.Errorf("%s products cannot have variants", product.Type)
+	}
+
+	variant := &ProductVariant{
+		ProductID: productID,
+		Name:      req.Name,
+		Size:      req.Size,
+		Color:     req.Color,
+		SKU:       req.SKU,
+		Price:     req.Price,
+		Stock:     req.Stock,
+		Status:    "active",
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.repo.CreateVariant(tx, variant); err != nil {
+			return errors.New("failed to create variant")
+		}
+		return s.repo.SyncStockFromVariants(tx, productID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.notifyRestock(product)
+
+	return variant, nil
+}
+
+// UpdateVariant updates a product variant and resyncs the product stock
+func (s *MarketplaceService) UpdateVariant(productID, variantID uint, req *UpdateVariantRequest) (*ProductVariant, error) {
+	if _, err := s.repo.FindVariant(productID, variantID); err != nil {
+		return nil, err
+	}
+	product, err := s.repo.FindByID(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != "" {
+		updates["name"] = req.Name
+	}
+	if req.Size != "" {
+		updates["size"] = req.Size
+	}
+	if req.Color != "" {
+		updates["color"] = req.Color
+	}
+	if req.SKU != "" {
+		updates["sku"] = req.SKU
+	}
+	if req.Price > 0 {
+		updates["price"] = req.Price
+	}
+	if req.Stock != nil {
+		updates["stock"] = *req.Stock
+	}
+	if req.Status != "" {
+		updates["status"] = req.Status
+	}
+
+	if len(updates) > 0 {
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			if err := s.repo.UpdateVariant(tx, variantID, updates); err != nil {
+				return errors.New("failed to update variant")
+			}
+			return s.repo.SyncStockFromVariants(tx, productID)
+		})
+		if err != nil {
+			return nil, err
+		}
+		s.notifyRestock(product)
+	}
+
+	return s.repo.FindVariant(productID, variantID)
+}
+
+// DeleteVariant deactivates a product variant (past purchases keep referring to it)
+func (s *MarketplaceService) DeleteVariant(productID, variantID uint) error {
+	if _, err := s.repo.FindVariant(productID, variantID); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.repo.UpdateVariant(tx, variantID, map[string]interface{}{"status": "inactive"}); err != nil {
+			return err
+		}
+		return s.repo.SyncStockFromVariants(tx, productID)
+	})
+}
+
+// resolveVariant checks the variant chosen for a product line. Products with
+// active variants must be bought through one of them; the returned variant is
+// nil for products sold as a single item. A non-nil tx locks the variant row.
+func (s *MarketplaceService) resolveVariant(tx *gorm.DB, product *Product, variantID *uint) (*ProductVariant, error) {
+	if variantID == nil {
+		hasVariants, err := s.repo.HasActiveVariants(tx, product.ID)
+		if err != nil {
+			return nil, err
+		}
+		if hasVariants {
+			return nil, fmt.Errorf("please choose a variant of %s", product.Name)
+		}
+		return nil, nil
+	}
+
+	var variant *ProductVariant
+	var err error
+	if tx != nil {
+		variant, err = s.repo.FindVariantWithTx(tx, product.ID, *variantID)
+	} else {
+		variant, err = s.repo.FindVariant(product.ID, *variantID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if variant.Status != "active" {
+		return nil, fmt.Errorf("%s (%s) is no longer available", product.Name, variant.Name)
+	}
+
+	return variant, nil
+}
+
+// applyTags resolves tag names and attaches them to a product
+func (s *MarketplaceService) applyTags(productID uint, names []string) error {
+	tags, err := s.repo.FindOrCreateTags(names)
+	if err != nil {
+		return err
+	}
+	return s.repo.ReplaceTags(productID, tags)
+}
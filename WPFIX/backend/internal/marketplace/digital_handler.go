@@ -0,0 +1,188 @@
+package marketplace
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadDigitalCodes handles bulk-uploading codes to a digital product
+// @Summary Upload digital codes
+// @Description Add voucher codes, license keys or certificate links to a digital product's pool, either as JSON or as a CSV file (code[,file_url] per line) in the "file" form field (Admin only)
+// @Tags Marketplace - Catalog
+// @Security BearerAuth
+// @Accept json,mpfd
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param request body UploadDigitalCodesRequest false "Codes"
+// @Param file formData file false "CSV file of codes"
+// @Success 201 {object} utils.Response{data=UploadDigitalCodesResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/products/{id}/codes [post]
+// @Router /merchant/products/{id}/codes [post]
+func (h *MarketplaceHandler) UploadDigitalCodes(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", nil)
+		return
+	}
+
+	var req UploadDigitalCodesRequest
+	if file, _, err := c.Request.FormFile("file"); err == nil {
+		defer file.Close()
+		req.Codes, err = readDigitalCodes(file)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid codes file", err.Error())
+			return
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	result, err := h.service.UploadDigitalCodes(uint(productID), &req, adminID)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "product not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Codes uploaded successfully", result)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "UPLOAD_DIGITAL_CODES",
+		Entity:    "PRODUCT",
+		EntityID:  uint(productID),
+		Details:   fmt.Sprintf("%s uploaded %d codes (%d duplicates skipped)", actorLabel(c), result.Uploaded, result.Duplicates),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// readDigitalCodes parses a CSV of code[,file_url] lines, skipping blank lines
+// and a "code" header row
+func readDigitalCodes(r io.Reader) ([]DigitalCodeInput, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var codes []DigitalCodeInput
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		code := strings.TrimSpace(record[0])
+		if code == "" || (len(codes) == 0 && strings.EqualFold(code, "code")) {
+			continue
+		}
+		input := DigitalCodeInput{Code: code}
+		if len(record) > 1 {
+			input.FileURL = strings.TrimSpace(record[1])
+		}
+		codes = append(codes, input)
+	}
+	return codes, nil
+}
+
+// GetDigitalCodes handles listing a digital product's codes
+// @Summary Get digital codes
+// @Tags Marketplace - Catalog
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param status query string false "Filter by status (available, assigned, revoked)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=DigitalCodeListResponse}
+// @Failure 404 {object} utils.Response
+// @Router /admin/products/{id}/codes [get]
+// @Router /merchant/products/{id}/codes [get]
+func (h *MarketplaceHandler) GetDigitalCodes(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", nil)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	codes, err := h.service.GetDigitalCodes(uint(productID), c.Query("status"), page, limit)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "product not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Codes retrieved successfully", codes)
+}
+
+// RevokeDigitalCode handles withdrawing an unsold code
+// @Summary Revoke digital code
+// @Description Withdraw an unsold code from a digital product's pool (Admin only)
+// @Tags Marketplace - Catalog
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param codeId path int true "Code ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/products/{id}/codes/{codeId} [delete]
+// @Router /merchant/products/{id}/codes/{codeId} [delete]
+func (h *MarketplaceHandler) RevokeDigitalCode(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", nil)
+		return
+	}
+	codeID, err := strconv.ParseUint(c.Param("codeId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid code ID", nil)
+		return
+	}
+
+	if err := h.service.RevokeDigitalCode(uint(productID), uint(codeID)); err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "product not found" || err.Error() == "code not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Code revoked successfully", nil)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "REVOKE_DIGITAL_CODE",
+		Entity:    "PRODUCT",
+		EntityID:  uint(productID),
+		Details:   actorLabel(c) + " revoked code ID: " + strconv.FormatUint(codeID, 10),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
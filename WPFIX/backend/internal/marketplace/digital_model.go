@@ -0,0 +1,51 @@
+package marketplace
+
+import "time"
+
+// MaxDigitalCodesPerUpload caps how many codes one bulk upload may add
+const MaxDigitalCodesPerUpload = 1000
+
+// DigitalCode is one unit of a digital product's inventory: a voucher code,
+// license key or certificate, optionally with a file to download. The
+// product's stock is the number of codes still available.
+type DigitalCode struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	ProductID     uint       `json:"product_id" gorm:"not null;uniqueIndex:idx_digital_product_code;index:idx_digital_product_status"`
+	Code          string     `json:"code" gorm:"size:255;not null;uniqueIndex:idx_digital_product_code"`
+	FileURL       string     `json:"file_url,omitempty" gorm:"size:500"`
+	Status        string     `json:"status" gorm:"type:enum('available','assigned','revoked');default:'available';index:idx_digital_product_status"`
+	TransactionID *uint      `json:"transaction_id" gorm:"index"`
+	WalletID      *uint      `json:"wallet_id"`
+	AssignedAt    *time.Time `json:"assigned_at"`
+	CreatedBy     uint       `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (DigitalCode) TableName() string {
+	return "digital_codes"
+}
+
+type DigitalCodeInput struct {
+	Code    string `json:"code" binding:"required,max=255"`
+	FileURL string `json:"file_url" binding:"omitempty,max=500"`
+}
+
+type UploadDigitalCodesRequest struct {
+	Codes []DigitalCodeInput `json:"codes" binding:"required,min=1,dive"`
+}
+
+type UploadDigitalCodesResponse struct {
+	ProductID  uint `json:"product_id"`
+	Uploaded   int  `json:"uploaded"`
+	Duplicates int  `json:"duplicates"` // Codes already in the pool, skipped
+	Stock      int  `json:"stock"`
+}
+
+type DigitalCodeListResponse struct {
+	Codes      []DigitalCode `json:"codes"`
+	Total      int64         `json:"total"`
+	Page       int           `json:"page"`
+	Limit      int           `json:"limit"`
+	TotalPages int           `json:"total_pages"`
+}
@@ -0,0 +1,81 @@
+package marketplace
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateDigitalCodes adds codes to a product's pool, skipping codes it
+// already holds, and returns how many were inserted
+func (r *MarketplaceRepository) CreateDigitalCodes(tx *gorm.DB, codes []DigitalCode) (int, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(codes, 200)
+	return int(result.RowsAffected), result.Error
+}
+
+// LockAvailableCodesWithTx locks up to limit unassigned codes of a product, oldest first
+func (r *MarketplaceRepository) LockAvailableCodesWithTx(tx *gorm.DB, productID uint, limit int) ([]DigitalCode, error) {
+	var codes []DigitalCode
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND status = ?", productID, "available").
+		Order("id ASC").
+		Limit(limit).
+		Find(&codes).Error
+	return codes, err
+}
+
+// AssignCodesWithTx hands the given codes to a purchase
+func (r *MarketplaceRepository) AssignCodesWithTx(tx *gorm.DB, codeIDs []uint, txnID, walletID uint, at time.Time) error {
+	return tx.Model(&DigitalCode{}).
+		Where("id IN ? AND status = ?", codeIDs, "available").
+		Updates(map[string]interface{}{
+			"status":         "assigned",
+			"transaction_id": txnID,
+			"wallet_id":      walletID,
+			"assigned_at":    at,
+		}).Error
+}
+
+// FindDigitalCodeWithTx finds a product's code and locks the row
+func (r *MarketplaceRepository) FindDigitalCodeWithTx(tx *gorm.DB, productID, codeID uint) (*DigitalCode, error) {
+	var code DigitalCode
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND product_id = ?", codeID, productID).
+		First(&code).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("code not found")
+		}
+		return nil, err
+	}
+	return &code, nil
+}
+
+// UpdateDigitalCodeWithTx updates a digital code inside a transaction
+func (r *MarketplaceRepository) UpdateDigitalCodeWithTx(tx *gorm.DB, codeID uint, updates map[string]interface{}) error {
+	return tx.Model(&DigitalCode{}).Where("id = ?", codeID).Updates(updates).Error
+}
+
+// GetDigitalCodes gets a product's code pool with pagination
+func (r *MarketplaceRepository) GetDigitalCodes(productID uint, status string, page, limit int) ([]DigitalCode, int64, error) {
+	var codes []DigitalCode
+	var total int64
+
+	query := r.db.Model(&DigitalCode{}).Where("product_id = ?", productID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err := query.Order("id ASC").Limit(limit).Offset(offset).Find(&codes).Error
+	return codes, total, err
+}
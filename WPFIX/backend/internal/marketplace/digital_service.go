@@ -0,0 +1,184 @@
+package marketplace
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// isDigital reports whether a product is sold from a pool of codes
+func (p *Product) isDigital() bool {
+	return p.Type == "digital"
+}
+
+// UploadDigitalCodes adds codes to a digital product's pool. Codes already in
+// the pool are skipped and the product stock grows by the codes added.
+func (s *MarketplaceService) UploadDigitalCodes(productID uint, req *UploadDigitalCodesRequest, adminID uint) (*UploadDigitalCodesResponse, error) {
+	if len(req.Codes) > MaxDigitalCodesPerUpload {
+		return nil, fmt.Errorf("at most %d codes can be uploaded at once", MaxDigitalCodesPerUpload)
+	}
+
+	seen := make(map[string]bool)
+	codes := make([]DigitalCode, 0, len(req.Codes))
+	for _, input := range req.Codes {
+		code := strings.TrimSpace(input.Code)
+		if code == "" || seen[code] {
+			continue
+		}
+		if len(code) > 255 || len(input.FileURL) > 500 {
+			return nil, fmt.Errorf("code %.20s... is too long", code)
+		}
+		seen[code] = true
+		codes = append(codes, DigitalCode{
+			ProductID: productID,
+			Code:      code,
+			FileURL:   strings.TrimSpace(input.FileURL),
+			Status:    "available",
+			CreatedBy: adminID,
+		})
+	}
+	if len(codes) == 0 {
+		return nil, errors.New("no codes to upload")
+	}
+
+	var response *UploadDigitalCodesResponse
+	var before *Product
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		product, err := s.repo.FindByIDWithTx(tx, productID)
+		if err != nil {
+			return err
+		}
+		before = product
+		if !product.isDigital() {
+			return errors.New("codes can only be uploaded to digital products")
+		}
+
+		inserted, err := s.repo.CreateDigitalCodes(tx, codes)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.UpdateStock(tx, product.ID, inserted); err != nil {
+			return err
+		}
+
+		response = &UploadDigitalCodesResponse{
+			ProductID:  product.ID,
+			Uploaded:   inserted,
+			Duplicates: len(req.Codes) - inserted,
+			Stock:      product.Stock + inserted,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.notifyRestock(before)
+
+	return response, nil
+}
+
+// GetDigitalCodes lists a digital product's code pool
+func (s *MarketplaceService) GetDigitalCodes(productID uint, status string, page, limit int) (*DigitalCodeListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+
+	if _, err := s.repo.FindByID(productID); err != nil {
+		return nil, err
+	}
+
+	codes, total, err := s.repo.GetDigitalCodes(productID, status, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	return &DigitalCodeListResponse{
+		Codes:      codes,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// RevokeDigitalCode withdraws an unsold code from the pool
+func (s *MarketplaceService) RevokeDigitalCode(productID, codeID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		product, err := s.repo.FindByIDWithTx(tx, productID)
+		if err != nil {
+			return err
+		}
+
+		code, err := s.repo.FindDigitalCodeWithTx(tx, product.ID, codeID)
+		if err != nil {
+			return err
+		}
+		if code.Status != "available" {
+			return fmt.Errorf("code is already %s", code.Status)
+		}
+
+		// Every reserved unit must keep a code behind it
+		ok, err := s.repo.DecrementStock(tx, product.ID, 1)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("all remaining codes are held by pending reservations")
+		}
+
+		return s.repo.UpdateDigitalCodeWithTx(tx, code.ID, map[string]interface{}{"status": "revoked"})
+	})
+}
+
+// deliverDigitalCodes assigns codes to a purchase of a digital product. The
+// caller holds the product row lock, so two purchases can never be handed
+// the same code. Digital purchases need no pickup and are collected at once.
+func (s *MarketplaceService) deliverDigitalCodes(tx *gorm.DB, product *Product, txn *MarketplaceTransaction) error {
+	if !product.isDigital() {
+		return nil
+	}
+
+	codes, err := s.repo.LockAvailableCodesWithTx(tx, product.ID, txn.Quantity)
+	if err != nil {
+		return err
+	}
+	if len(codes) < txn.Quantity {
+		return fmt.Errorf("not enough codes left for %s", product.Name)
+	}
+
+	now := time.Now()
+	ids := make([]uint, len(codes))
+	for i := range codes {
+		ids[i] = codes[i].ID
+		codes[i].Status = "assigned"
+		codes[i].TransactionID = &txn.ID
+		codes[i].WalletID = &txn.WalletID
+		codes[i].AssignedAt = &now
+	}
+	if err := s.repo.AssignCodesWithTx(tx, ids, txn.ID, txn.WalletID, now); err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdateTransactionWithTx(tx, txn.ID, map[string]interface{}{
+		"fulfillment_status": "collected",
+		"collected_at":       now,
+		"pickup_code":        "",
+	}); err != nil {
+		return err
+	}
+
+	txn.FulfillmentStatus = "collected"
+	txn.CollectedAt = &now
+	txn.PickupCode = ""
+	txn.DigitalCodes = codes
+	return nil
+}
@@ -0,0 +1,53 @@
+package marketplace
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetEligibility handles checking whether the student can buy a product
+// @Summary Check purchase eligibility
+// @Description Tell the student whether they may buy a product, why not, and how many units they have left under its limits
+// @Tags Marketplace
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} utils.Response{data=EligibilityResponse}
+// @Failure 404 {object} utils.Response
+// @Router /mahasiswa/marketplace/products/{id}/eligibility [get]
+func (h *MarketplaceHandler) GetEligibility(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", nil)
+		return
+	}
+
+	eligibility, err := h.service.GetEligibility(userID, uint(productID))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "product not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Eligibility retrieved successfully", eligibility)
+}
+
+// purchaseError writes the response for a failed purchase, reservation or checkout
+func (h *MarketplaceHandler) purchaseError(c *gin.Context, err error) {
+	statusCode := http.StatusBadRequest
+	var eligibilityErr *EligibilityError
+	if errors.As(err, &eligibilityErr) {
+		statusCode = http.StatusForbidden
+	} else if err.Error() == "product not found" || err.Error() == "variant not found" {
+		statusCode = http.StatusNotFound
+	}
+	utils.ErrorResponse(c, statusCode, err.Error(), nil)
+}
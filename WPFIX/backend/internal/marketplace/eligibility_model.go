@@ -0,0 +1,73 @@
+package marketplace
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// StringList is a list of strings stored as a JSON column
+type StringList []string
+
+func (sl StringList) Value() (driver.Value, error) {
+	if sl == nil {
+		return nil, nil
+	}
+	return json.Marshal(sl)
+}
+
+func (sl *StringList) Scan(value interface{}) error {
+	if value == nil {
+		*sl = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, sl)
+}
+
+// Eligibility holds the rules a buyer must satisfy to purchase a product.
+// Zero limits and empty lists mean "no restriction".
+type Eligibility struct {
+	MaxPerUser     int        `json:"max_per_user" gorm:"default:0"`    // Units per student, ever
+	PeriodLimit    int        `json:"period_limit" gorm:"default:0"`    // Units per student within PeriodDays
+	PeriodDays     int        `json:"period_days" gorm:"default:0"`     // Length of the rolling period
+	AllowedRoles   StringList `json:"allowed_roles" gorm:"type:json"`   // e.g. ["mahasiswa"]
+	AllowedMajors  StringList `json:"allowed_majors" gorm:"type:json"`  // Matched against the buyer's profile
+	AllowedBatches StringList `json:"allowed_batches" gorm:"type:json"` // Matched against the buyer's profile
+}
+
+// EligibilityError explains why a student may not buy a product
+type EligibilityError struct {
+	Reason string
+}
+
+func (e *EligibilityError) Error() string {
+	return e.Reason
+}
+
+// Buyer is the profile eligibility rules are evaluated against
+type Buyer struct {
+	ID    uint
+	Role  string
+	Major string
+	Batch string
+}
+
+type EligibilityRequest struct {
+	MaxPerUser     int      `json:"max_per_user" binding:"gte=0"`
+	PeriodLimit    int      `json:"period_limit" binding:"gte=0"`
+	PeriodDays     int      `json:"period_days" binding:"gte=0"`
+	AllowedRoles   []string `json:"allowed_roles" binding:"omitempty,dive,oneof=admin dosen mahasiswa merchant"`
+	AllowedMajors  []string `json:"allowed_majors"`
+	AllowedBatches []string `json:"allowed_batches"`
+}
+
+type EligibilityResponse struct {
+	ProductID uint   `json:"product_id"`
+	Eligible  bool   `json:"eligible"`
+	Reason    string `json:"reason,omitempty"`
+	Remaining *int   `json:"remaining,omitempty"` // Units the student may still buy, if limited
+}
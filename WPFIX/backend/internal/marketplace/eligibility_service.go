@@ -0,0 +1,157 @@
+package marketplace
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// containsFold reports whether list holds value, ignoring case and surrounding spaces
+func containsFold(list []string, value string) bool {
+	value = strings.TrimSpace(value)
+	for _, item := range list {
+		if strings.EqualFold(strings.TrimSpace(item), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// toEligibility converts an eligibility request into stored rules
+func toEligibility(req *EligibilityRequest) Eligibility {
+	return Eligibility{
+		MaxPerUser:     req.MaxPerUser,
+		PeriodLimit:    req.PeriodLimit,
+		PeriodDays:     req.PeriodDays,
+		AllowedRoles:   StringList(req.AllowedRoles),
+		AllowedMajors:  StringList(req.AllowedMajors),
+		AllowedBatches: StringList(req.AllowedBatches),
+	}
+}
+
+// loadBuyer loads the profile fields eligibility rules look at
+func (s *MarketplaceService) loadBuyer(tx *gorm.DB, userID uint) (*Buyer, error) {
+	if tx == nil {
+		tx = s.db
+	}
+	var buyer Buyer
+	err := tx.Table("users").Select("id, role, major, batch").Where("id = ?", userID).Scan(&buyer).Error
+	if err != nil {
+		return nil, err
+	}
+	if buyer.ID == 0 {
+		return nil, errors.New("user not found")
+	}
+	return &buyer, nil
+}
+
+// evaluateEligibility checks a buyer against a product's rules for quantity
+// units. It returns how many units the buyer may still buy when the product
+// is limited, and an *EligibilityError when the purchase is not allowed.
+func (s *MarketplaceService) evaluateEligibility(tx *gorm.DB, buyer *Buyer, walletID uint, product *Product, quantity int) (*int, error) {
+	rules := product.Eligibility
+
+	if len(rules.AllowedRoles) > 0 && !containsFold(rules.AllowedRoles, buyer.Role) {
+		return nil, &EligibilityError{Reason: fmt.Sprintf("%s is only available to: %s", product.Name, strings.Join(rules.AllowedRoles, ", "))}
+	}
+	if len(rules.AllowedMajors) > 0 {
+		if buyer.Major == "" {
+			return nil, &EligibilityError{Reason: fmt.Sprintf("%s is restricted by major and your profile has no major set", product.Name)}
+		}
+		if !containsFold(rules.AllowedMajors, buyer.Major) {
+			return nil, &EligibilityError{Reason: fmt.Sprintf("%s is only available to majors: %s", product.Name, strings.Join(rules.AllowedMajors, ", "))}
+		}
+	}
+	if len(rules.AllowedBatches) > 0 {
+		if buyer.Batch == "" {
+			return nil, &EligibilityError{Reason: fmt.Sprintf("%s is restricted by batch and your profile has no batch set", product.Name)}
+		}
+		if !containsFold(rules.AllowedBatches, buyer.Batch) {
+			return nil, &EligibilityError{Reason: fmt.Sprintf("%s is only available to batches: %s", product.Name, strings.Join(rules.AllowedBatches, ", "))}
+		}
+	}
+
+	var remaining *int
+	limit := func(max int, since *time.Time, period string) error {
+		bought, err := s.repo.SumPurchasedQuantity(tx, walletID, product.ID, since)
+		if err != nil {
+			return err
+		}
+		left := max - bought
+		if left < 0 {
+			left = 0
+		}
+		if remaining == nil || left < *remaining {
+			remaining = &left
+		}
+		if quantity > left {
+			if left == 0 {
+				return &EligibilityError{Reason: fmt.Sprintf("you have already bought the maximum of %d %s", max, period)}
+			}
+			return &EligibilityError{Reason: fmt.Sprintf("you can only buy %d more %s", left, period)}
+		}
+		return nil
+	}
+
+	if rules.MaxPerUser > 0 {
+		if err := limit(rules.MaxPerUser, nil, "per student"); err != nil {
+			return remaining, err
+		}
+	}
+	if rules.PeriodLimit > 0 && rules.PeriodDays > 0 {
+		since := time.Now().AddDate(0, 0, -rules.PeriodDays)
+		if err := limit(rules.PeriodLimit, &since, fmt.Sprintf("every %d days", rules.PeriodDays)); err != nil {
+			return remaining, err
+		}
+	}
+
+	return remaining, nil
+}
+
+// checkEligibility enforces a product's rules inside a purchase. The caller
+// holds the product row lock, so concurrent purchases by the same student
+// cannot both slip under a limit.
+func (s *MarketplaceService) checkEligibility(tx *gorm.DB, userID, walletID uint, product *Product, quantity int) error {
+	buyer, err := s.loadBuyer(tx, userID)
+	if err != nil {
+		return err
+	}
+	_, err = s.evaluateEligibility(tx, buyer, walletID, product, quantity)
+	return err
+}
+
+// GetEligibility tells a student whether they can buy a product and why not
+func (s *MarketplaceService) GetEligibility(userID, productID uint) (*EligibilityResponse, error) {
+	product, err := s.repo.FindByID(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	studentWallet, err := s.walletService.GetWalletByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	buyer, err := s.loadBuyer(nil, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &EligibilityResponse{ProductID: product.ID, Eligible: true}
+	remaining, err := s.evaluateEligibility(s.db, buyer, studentWallet.ID, product, 1)
+	response.Remaining = remaining
+	if err != nil {
+		var eligibilityErr *EligibilityError
+		if errors.As(err, &eligibilityErr) {
+			response.Eligible = false
+			response.Reason = eligibilityErr.Reason
+			return response, nil
+		}
+		return nil, err
+	}
+
+	return response, nil
+}
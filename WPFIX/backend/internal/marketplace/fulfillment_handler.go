@@ -0,0 +1,180 @@
+package marketplace
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMyPurchases handles getting the current student's purchases
+// @Summary Get my purchases
+// @Description Get the current student's marketplace purchases with fulfilment status and pickup codes
+// @Tags Marketplace
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by fulfilment status" Enums(paid, ready_for_pickup, collected, cancelled, refunded)
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} utils.Response
+// @Router /mahasiswa/marketplace/transactions [get]
+func (h *MarketplaceHandler) GetMyPurchases(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	status := c.Query("status")
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	transactions, total, err := h.service.GetMyPurchases(userID, status, limit, offset)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve purchases", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Purchases retrieved successfully", gin.H{
+		"transactions": transactions,
+		"total":        total,
+		"limit":        limit,
+		"offset":       offset,
+	})
+}
+
+// MarkReady handles marking a purchase as ready for pickup
+// @Summary Mark purchase ready for pickup
+// @Description Move a paid purchase to ready_for_pickup (product creator or admin)
+// @Tags Admin - Marketplace
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Success 200 {object} utils.Response{data=MarketplaceTransaction}
+// @Failure 400 {object} utils.Response
+// @Router /admin/marketplace/transactions/{id}/ready [post]
+func (h *MarketplaceHandler) MarkReady(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	txnID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid transaction ID", nil)
+		return
+	}
+
+	txn, err := h.service.MarkReady(uint(txnID), userID, c.GetString("role"))
+	if err != nil {
+		h.fulfilmentError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Order is ready for pickup", txn)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "MARK_ORDER_READY",
+		Entity:    "MARKETPLACE_TRANSACTION",
+		EntityID:  txn.ID,
+		Details:   fmt.Sprintf("Marked %dx %s ready for pickup", txn.Quantity, txn.ProductName),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// CollectPickup handles handing over a purchase by its pickup code
+// @Summary Collect purchase
+// @Description Confirm a student collected a ready purchase using their pickup code (product creator or admin)
+// @Tags Admin - Marketplace
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CollectPickupRequest true "Pickup code"
+// @Success 200 {object} utils.Response{data=MarketplaceTransaction}
+// @Failure 400 {object} utils.Response
+// @Router /admin/marketplace/pickup [post]
+func (h *MarketplaceHandler) CollectPickup(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req CollectPickupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	txn, err := h.service.CollectByPickupCode(req.PickupCode, userID, c.GetString("role"))
+	if err != nil {
+		h.fulfilmentError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Order collected successfully", txn)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "COLLECT_ORDER",
+		Entity:    "MARKETPLACE_TRANSACTION",
+		EntityID:  txn.ID,
+		Details:   fmt.Sprintf("Handed over %dx %s", txn.Quantity, txn.ProductName),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// CancelTransaction handles cancelling and refunding a purchase
+// @Summary Cancel purchase
+// @Description Cancel a purchase that has not been collected, refunding points and restoring stock (product creator or admin)
+// @Tags Admin - Marketplace
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Param request body CancelTransactionRequest false "Cancellation reason"
+// @Success 200 {object} utils.Response{data=MarketplaceTransaction}
+// @Failure 400 {object} utils.Response
+// @Router /admin/marketplace/transactions/{id}/cancel [post]
+func (h *MarketplaceHandler) CancelTransaction(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	txnID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid transaction ID", nil)
+		return
+	}
+
+	var req CancelTransactionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ValidationErrorResponse(c, err.Error())
+			return
+		}
+	}
+
+	txn, err := h.service.CancelTransaction(uint(txnID), userID, c.GetString("role"), req.Reason)
+	if err != nil {
+		h.fulfilmentError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Order cancelled successfully", txn)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "CANCEL_ORDER",
+		Entity:    "MARKETPLACE_TRANSACTION",
+		EntityID:  txn.ID,
+		Details:   fmt.Sprintf("Cancelled %dx %s (%s, %d points) | Reason: %s", txn.Quantity, txn.ProductName, txn.FulfillmentStatus, txn.TotalAmount, req.Reason),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// fulfilmentError maps fulfilment service errors to HTTP status codes
+func (h *MarketplaceHandler) fulfilmentError(c *gin.Context, err error) {
+	statusCode := http.StatusBadRequest
+	switch err.Error() {
+	case "transaction not found", "pickup code not found", "product not found":
+		statusCode = http.StatusNotFound
+	case "you are not allowed to fulfil this order":
+		statusCode = http.StatusForbidden
+	}
+	utils.ErrorResponse(c, statusCode, err.Error(), nil)
+}
@@ -0,0 +1,81 @@
+package marketplace
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LockTransactionWithTx finds a marketplace transaction and locks the row
+func (r *MarketplaceRepository) LockTransactionWithTx(tx *gorm.DB, txnID uint) (*MarketplaceTransaction, error) {
+	var txn MarketplaceTransaction
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&txn, txnID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("transaction not found")
+		}
+		return nil, err
+	}
+	return &txn, nil
+}
+
+// LockTransactionByPickupCodeWithTx finds the transaction awaiting pickup for a code and locks the row
+func (r *MarketplaceRepository) LockTransactionByPickupCodeWithTx(tx *gorm.DB, code string) (*MarketplaceTransaction, error) {
+	var txn MarketplaceTransaction
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("pickup_code = ? AND fulfillment_status = ?", code, "ready_for_pickup").
+		First(&txn).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("pickup code not found")
+		}
+		return nil, err
+	}
+	return &txn, nil
+}
+
+// UpdateTransactionWithTx updates a marketplace transaction inside a transaction
+func (r *MarketplaceRepository) UpdateTransactionWithTx(tx *gorm.DB, txnID uint, updates map[string]interface{}) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Model(&MarketplaceTransaction{}).Where("id = ?", txnID).Updates(updates).Error
+}
+
+// SumPurchasedQuantity sums the units of a product a wallet has bought and
+// not had cancelled, optionally only since the given time
+func (r *MarketplaceRepository) SumPurchasedQuantity(tx *gorm.DB, walletID, productID uint, since *time.Time) (int, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	var total int
+	query := tx.Model(&MarketplaceTransaction{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("wallet_id = ? AND product_id = ? AND status = ?", walletID, productID, "success").
+		Where("fulfillment_status NOT IN ?", []string{"cancelled", "refunded"})
+	if since != nil {
+		query = query.Where("created_at >= ?", *since)
+	}
+	err := query.Scan(&total).Error
+	return total, err
+}
+
+// GetTransactionsByWallet gets a wallet's marketplace purchases
+func (r *MarketplaceRepository) GetTransactionsByWallet(walletID uint, status string, limit, offset int) ([]MarketplaceTransaction, int64, error) {
+	var transactions []MarketplaceTransaction
+	var total int64
+
+	query := r.db.Model(&MarketplaceTransaction{}).Where("wallet_id = ?", walletID)
+	if status != "" {
+		query = query.Where("fulfillment_status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("DigitalCodes").Order("created_at DESC").Limit(limit).Offset(offset).Find(&transactions).Error
+	return transactions, total, err
+}
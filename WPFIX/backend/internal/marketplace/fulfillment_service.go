@@ -0,0 +1,212 @@
+package marketplace
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// pickupCodeAlphabet leaves out characters that are easy to misread (0/O, 1/I/L)
+const pickupCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// newPickupCode generates the code a student shows when collecting an item
+func newPickupCode() string {
+	code := make([]byte, 8)
+	max := big.NewInt(int64(len(pickupCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			// crypto/rand failing is unrecoverable; fall back to a time-based character
+			n = big.NewInt(time.Now().UnixNano() % int64(len(pickupCodeAlphabet)))
+		}
+		code[i] = pickupCodeAlphabet[n.Int64()]
+	}
+	return string(code)
+}
+
+// canFulfil reports whether the actor may advance a sale of the product
+func canFulfil(product *Product, actorID uint, role string) bool {
+	return role == "admin" || product.CreatedBy == actorID
+}
+
+// GetMyPurchases gets a student's marketplace purchases, including pickup codes
+func (s *MarketplaceService) GetMyPurchases(userID uint, status string, limit, offset int) ([]MarketplaceTransaction, int64, error) {
+	studentWallet, err := s.walletService.GetWalletByUserID(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	transactions, total, err := s.repo.GetTransactionsByWallet(studentWallet.ID, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := s.populateProductNames(transactions); err != nil {
+		return nil, 0, err
+	}
+
+	return transactions, total, nil
+}
+
+// MarkReady moves a paid purchase to ready_for_pickup
+func (s *MarketplaceService) MarkReady(txnID, actorID uint, role string) (*MarketplaceTransaction, error) {
+	var txn *MarketplaceTransaction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		txn, err = s.repo.LockTransactionWithTx(tx, txnID)
+		if err != nil {
+			return err
+		}
+
+		product, err := s.repo.FindByID(txn.ProductID)
+		if err != nil {
+			return err
+		}
+		if !canFulfil(product, actorID, role) {
+			return errors.New("you are not allowed to fulfil this order")
+		}
+
+		if txn.FulfillmentStatus != "paid" {
+			return fmt.Errorf("cannot mark a %s order as ready", txn.FulfillmentStatus)
+		}
+
+		now := time.Now()
+		txn.FulfillmentStatus = "ready_for_pickup"
+		txn.ReadyAt = &now
+		txn.HandledBy = &actorID
+		txn.ProductName = product.Name
+
+		return s.repo.UpdateTransactionWithTx(tx, txn.ID, map[string]interface{}{
+			"fulfillment_status": txn.FulfillmentStatus,
+			"ready_at":           now,
+			"handled_by":         actorID,
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return txn, nil
+}
+
+// CollectByPickupCode hands over a ready purchase to the student showing the code
+func (s *MarketplaceService) CollectByPickupCode(code string, actorID uint, role string) (*MarketplaceTransaction, error) {
+	var txn *MarketplaceTransaction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		txn, err = s.repo.LockTransactionByPickupCodeWithTx(tx, code)
+		if err != nil {
+			return err
+		}
+
+		product, err := s.repo.FindByID(txn.ProductID)
+		if err != nil {
+			return err
+		}
+		if !canFulfil(product, actorID, role) {
+			return errors.New("you are not allowed to fulfil this order")
+		}
+
+		now := time.Now()
+		txn.FulfillmentStatus = "collected"
+		txn.CollectedAt = &now
+		txn.HandledBy = &actorID
+		txn.ProductName = product.Name
+
+		return s.repo.UpdateTransactionWithTx(tx, txn.ID, map[string]interface{}{
+			"fulfillment_status": txn.FulfillmentStatus,
+			"collected_at":       now,
+			"handled_by":         actorID,
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return txn, nil
+}
+
+// CancelTransaction cancels a purchase that has not been collected yet.
+// Wallet payments are refunded from the seller back to the student and the
+// stock is restored, all in the same database transaction.
+func (s *MarketplaceService) CancelTransaction(txnID, actorID uint, role string, reason string) (*MarketplaceTransaction, error) {
+	var txn *MarketplaceTransaction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		txn, err = s.repo.LockTransactionWithTx(tx, txnID)
+		if err != nil {
+			return err
+		}
+
+		product, err := s.repo.FindByIDWithTx(tx, txn.ProductID)
+		if err != nil {
+			return err
+		}
+		if !canFulfil(product, actorID, role) {
+			return errors.New("you are not allowed to fulfil this order")
+		}
+
+		if txn.FulfillmentStatus != "paid" && txn.FulfillmentStatus != "ready_for_pickup" {
+			return fmt.Errorf("cannot cancel a %s order", txn.FulfillmentStatus)
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"fulfillment_status": "cancelled",
+			"cancelled_at":       now,
+			"cancel_reason":      reason,
+			"handled_by":         actorID,
+		}
+		txn.FulfillmentStatus = "cancelled"
+		txn.CancelledAt = &now
+
+		// 1. Refund points (external QR token purchases never touched the wallet)
+		if txn.PaymentMethod != "qr" {
+			sellerWallet, err := s.sellerWallet(product)
+			if err != nil {
+				return err
+			}
+
+			description := fmt.Sprintf("Refund %dx %s (transaction #%d)", txn.Quantity, product.Name, txn.ID)
+			if err := s.walletService.DebitWithTransaction(tx, sellerWallet.ID, txn.TotalAmount, "marketplace_refund", description); err != nil {
+				return err
+			}
+			if err := s.walletService.CreditWithTransaction(tx, txn.WalletID, txn.TotalAmount, "marketplace_refund", description); err != nil {
+				return err
+			}
+
+			updates["fulfillment_status"] = "refunded"
+			updates["refunded_at"] = now
+			txn.FulfillmentStatus = "refunded"
+			txn.RefundedAt = &now
+		}
+
+		// 2. Restore stock
+		if err := s.restoreStock(tx, product.ID, txn.VariantID, txn.Quantity); err != nil {
+			return err
+		}
+
+		// 3. Give back the flash sale stock and coupon use
+		if err := s.releasePricing(tx, txn); err != nil {
+			return err
+		}
+
+		txn.CancelReason = reason
+		txn.HandledBy = &actorID
+		txn.ProductName = product.Name
+
+		return s.repo.UpdateTransactionWithTx(tx, txn.ID, updates)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return txn, nil
+}
@@ -27,15 +27,25 @@ func NewMarketplaceHandler(service *MarketplaceService, auditService *audit.Audi
 // @Security BearerAuth
 // @Produce json
 // @Param status query string false "Filter by status" Enums(active, inactive)
+// @Param q query string false "Full-text search on name and description"
+// @Param category_id query int false "Filter by category"
+// @Param tag query string false "Filter by tag"
+// @Param min_price query int false "Minimum price (base or any variant)"
+// @Param max_price query int false "Maximum price (base or any variant)"
+// @Param sort query string false "Sort order" Enums(newest, price_asc, price_desc, name_asc, name_desc, rating, relevance)
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
 // @Success 200 {object} utils.Response{data=ProductListResponse}
 // @Failure 401 {object} utils.Response
 // @Router /admin/products [get]
+// @Router /merchant/products [get]
 func (h *MarketplaceHandler) GetAll(c *gin.Context) {
 	status := c.Query("status")
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	categoryID, _ := strconv.ParseUint(c.Query("category_id"), 10, 32)
+	minPrice, _ := strconv.Atoi(c.Query("min_price"))
+	maxPrice, _ := strconv.Atoi(c.Query("max_price"))
 
 	// Security: If role is mahasiswa, force status to active
 	role, _ := c.Get("role")
@@ -43,10 +53,23 @@ func (h *MarketplaceHandler) GetAll(c *gin.Context) {
 		status = "active"
 	}
 
+	// Merchants manage only their own catalog
+	var createdBy uint
+	if role == "merchant" {
+		createdBy = c.GetUint("user_id")
+	}
+
 	params := ProductListParams{
-		Status: status,
-		Page:   page,
-		Limit:  limit,
+		Status:     status,
+		Search:     c.Query("q"),
+		CategoryID: uint(categoryID),
+		CreatedBy:  createdBy,
+		Tag:        c.Query("tag"),
+		MinPrice:   minPrice,
+		MaxPrice:   maxPrice,
+		Sort:       c.Query("sort"),
+		Page:       page,
+		Limit:      limit,
 	}
 
 	response, err := h.service.GetAllProducts(params)
@@ -68,6 +91,7 @@ func (h *MarketplaceHandler) GetAll(c *gin.Context) {
 // @Success 200 {object} utils.Response{data=Product}
 // @Failure 404 {object} utils.Response
 // @Router /admin/products/{id} [get]
+// @Router /merchant/products/{id} [get]
 func (h *MarketplaceHandler) GetByID(c *gin.Context) {
 	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -81,6 +105,18 @@ func (h *MarketplaceHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	// Students only see variants they can buy
+	role, _ := c.Get("role")
+	if role == "mahasiswa" {
+		variants := product.Variants[:0]
+		for _, v := range product.Variants {
+			if v.Status == "active" {
+				variants = append(variants, v)
+			}
+		}
+		product.Variants = variants
+	}
+
 	utils.SuccessResponse(c, http.StatusOK, "Product retrieved successfully", product)
 }
 
@@ -95,6 +131,7 @@ func (h *MarketplaceHandler) GetByID(c *gin.Context) {
 // @Success 201 {object} utils.Response{data=Product}
 // @Failure 400 {object} utils.Response
 // @Router /admin/products [post]
+// @Router /merchant/products [post]
 func (h *MarketplaceHandler) Create(c *gin.Context) {
 	adminID := c.GetUint("user_id")
 
@@ -118,7 +155,7 @@ func (h *MarketplaceHandler) Create(c *gin.Context) {
 		Action:    "CREATE_PRODUCT",
 		Entity:    "PRODUCT",
 		EntityID:  product.ID,
-		Details:   "Admin created new product: " + product.Name,
+		Details:   actorLabel(c) + " created new product: " + product.Name,
 		IPAddress: c.ClientIP(),
 		UserAgent: c.Request.UserAgent(),
 	})
@@ -137,6 +174,7 @@ func (h *MarketplaceHandler) Create(c *gin.Context) {
 // @Failure 400 {object} utils.Response
 // @Failure 404 {object} utils.Response
 // @Router /admin/products/{id} [put]
+// @Router /merchant/products/{id} [put]
 func (h *MarketplaceHandler) Update(c *gin.Context) {
 	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -169,7 +207,7 @@ func (h *MarketplaceHandler) Update(c *gin.Context) {
 		Action:    "UPDATE_PRODUCT",
 		Entity:    "PRODUCT",
 		EntityID:  product.ID,
-		Details:   "Admin updated product: " + product.Name,
+		Details:   actorLabel(c) + " updated product: " + product.Name,
 		IPAddress: c.ClientIP(),
 		UserAgent: c.Request.UserAgent(),
 	})
@@ -185,6 +223,7 @@ func (h *MarketplaceHandler) Update(c *gin.Context) {
 // @Success 200 {object} utils.Response
 // @Failure 404 {object} utils.Response
 // @Router /admin/products/{id} [delete]
+// @Router /merchant/products/{id} [delete]
 func (h *MarketplaceHandler) Delete(c *gin.Context) {
 	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -210,7 +249,7 @@ func (h *MarketplaceHandler) Delete(c *gin.Context) {
 		Action:    "DELETE_PRODUCT",
 		Entity:    "PRODUCT",
 		EntityID:  uint(productID),
-		Details:   "Admin deleted product ID: " + strconv.FormatUint(productID, 10),
+		Details:   actorLabel(c) + " deleted product ID: " + strconv.FormatUint(productID, 10),
 		IPAddress: c.ClientIP(),
 		UserAgent: c.Request.UserAgent(),
 	})
@@ -238,7 +277,7 @@ func (h *MarketplaceHandler) Purchase(c *gin.Context) {
 
 	txn, err := h.service.PurchaseProduct(userID, &req)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		h.purchaseError(c, err)
 		return
 	}
 
@@ -258,7 +297,7 @@ func (h *MarketplaceHandler) Purchase(c *gin.Context) {
 
 // GetTransactions handles getting all marketplace transactions
 // @Summary Get marketplace transactions
-// @Description Get list of all marketplace transactions (Admin)
+// @Description Get list of marketplace transactions (Admin); merchants only see sales of their own products
 // @Tags Admin - Marketplace
 // @Security BearerAuth
 // @Produce json
@@ -267,11 +306,18 @@ func (h *MarketplaceHandler) Purchase(c *gin.Context) {
 // @Success 200 {object} utils.SuccessResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /admin/marketplace/transactions [get]
+// @Router /merchant/marketplace/transactions [get]
 func (h *MarketplaceHandler) GetTransactions(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 
-	transactions, total, err := h.service.GetTransactions(limit, offset)
+	// Merchants only see sales of their own products
+	var sellerID uint
+	if c.GetString("role") == "merchant" {
+		sellerID = c.GetUint("user_id")
+	}
+
+	transactions, total, err := h.service.GetTransactions(sellerID, limit, offset)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
 		return
@@ -0,0 +1,67 @@
+package marketplace
+
+import (
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadProductImage handles uploading a product image
+// @Summary Upload product image
+// @Description Upload a JPEG, PNG or GIF image; it is validated, stripped of metadata and stored in original, medium and thumbnail sizes
+// @Tags Admin - Marketplace
+// @Security BearerAuth
+// @Accept mpfd
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param image formData file true "Product image"
+// @Success 200 {object} utils.Response{data=Product}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/products/{id}/image [post]
+// @Router /merchant/products/{id}/image [post]
+func (h *MarketplaceHandler) UploadProductImage(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", nil)
+		return
+	}
+
+	header, err := c.FormFile("image")
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "No image uploaded", nil)
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to read image", err.Error())
+		return
+	}
+	defer file.Close()
+
+	product, err := h.service.UploadProductImage(uint(productID), file)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "product not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Product image uploaded successfully", product)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    c.GetUint("user_id"),
+		Action:    "UPLOAD_PRODUCT_IMAGE",
+		Entity:    "PRODUCT",
+		EntityID:  product.ID,
+		Details:   actorLabel(c) + " uploaded an image for product: " + product.Name,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
@@ -0,0 +1,126 @@
+package marketplace
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"wallet-point/internal/wallet"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sqlRecorder keeps the statements gorm would have run
+type sqlRecorder struct {
+	logger.Interface
+	statements []string
+}
+
+func (r *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	r.statements = append(r.statements, sql)
+}
+
+// last returns the most recent statement and forgets the rest
+func (r *sqlRecorder) last() string {
+	if len(r.statements) == 0 {
+		return ""
+	}
+	sql := r.statements[len(r.statements)-1]
+	r.statements = nil
+	return sql
+}
+
+// dryRunDB builds MySQL statements without a database, so the guards the
+// concurrency tests rely on are checked even when TEST_DB_DSN is unset
+func dryRunDB(t *testing.T) (*gorm.DB, *sqlRecorder) {
+	t.Helper()
+
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "dry:run@tcp(127.0.0.1:0)/dry_run?parseTime=true",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, SkipDefaultTransaction: true, DisableAutomaticPing: true, Logger: recorder})
+	if err != nil {
+		t.Fatalf("failed to open dry-run database: %v", err)
+	}
+	return db, recorder
+}
+
+func TestStockDecrementsAreGuarded(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	repo := NewMarketplaceRepository(db)
+
+	tests := []struct {
+		name      string
+		decrement func() (bool, error)
+		table     string
+	}{
+		{"product", func() (bool, error) { return repo.DecrementStock(db, 7, 2) }, "`products`"},
+		{"variant", func() (bool, error) { return repo.DecrementVariantStock(db, 7, 2) }, "`product_variants`"},
+	}
+	for _, tt := range tests {
+		if _, err := tt.decrement(); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		sql := recorder.last()
+
+		// The stock is decremented in the database and only while enough is
+		// left, so two buyers can never both take the last unit
+		for _, want := range []string{"UPDATE " + tt.table, "`stock`=stock - 2", "id = 7 AND stock >= 2"} {
+			if !strings.Contains(sql, want) {
+				t.Errorf("%s decrement %q does not contain %q", tt.name, sql, want)
+			}
+		}
+	}
+}
+
+func TestCheckoutRowsAreLocked(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	repo := NewMarketplaceRepository(db)
+	walletRepo := wallet.NewWalletRepository(db)
+
+	tests := []struct {
+		name string
+		lock func()
+		want []string
+	}{
+		{"wallet", func() { walletRepo.LockByUserIDWithTx(db, 3) }, []string{"FROM `wallets`", "user_id = 3"}},
+		{"cart", func() { repo.LockCartWithTx(db, 3) }, []string{"FROM `cart_items`", "ORDER BY product_id ASC, variant_id ASC"}},
+		{"product", func() { repo.FindByIDWithTx(db, 7) }, []string{"FROM `products`", "`products`.`id` = 7"}},
+		{"variant", func() { repo.FindVariantWithTx(db, 7, 9) }, []string{"FROM `product_variants`"}},
+		{"reservations", func() { repo.LockExpiredReservationsWithTx(db, 7, time.Now()) }, []string{"FROM `stock_reservations`", "product_id = 7"}},
+	}
+	for _, tt := range tests {
+		tt.lock()
+		sql := recorder.last()
+		for _, want := range append(tt.want, "FOR UPDATE") {
+			if !strings.Contains(sql, want) {
+				t.Errorf("%s lock %q does not contain %q", tt.name, sql, want)
+			}
+		}
+	}
+}
+
+func TestSortCartItemsLocksInProductThenVariantOrder(t *testing.T) {
+	items := []CartItem{
+		{ID: 1, ProductID: 9, VariantID: 2},
+		{ID: 2, ProductID: 4, VariantID: 0},
+		{ID: 3, ProductID: 9, VariantID: 1},
+		{ID: 4, ProductID: 4, VariantID: 5},
+		{ID: 5, ProductID: 1, VariantID: 3},
+	}
+
+	sortCartItems(items)
+
+	var got []uint
+	for _, item := range items {
+		got = append(got, item.ID)
+	}
+	if want := []uint{5, 2, 4, 3, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sorted cart lines = %v, want %v", got, want)
+	}
+}
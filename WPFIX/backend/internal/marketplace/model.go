@@ -2,19 +2,33 @@ package marketplace
 
 import (
 	"time"
+	"wallet-point/internal/media"
 )
 
 type Product struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`
-	Name        string    `json:"name" gorm:"not null"`
-	Description string    `json:"description" gorm:"type:text"`
-	Price       int       `json:"price" gorm:"not null"`
-	Stock       int       `json:"stock" gorm:"default:0;not null"`
-	ImageURL    string    `json:"image_url" gorm:"size:500"`
-	Status      string    `json:"status" gorm:"type:enum('active','inactive');default:'active'"`
-	CreatedBy   uint      `json:"created_by" gorm:"not null"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"not null"`
+	Description string         `json:"description" gorm:"type:text"`
+	Price       int            `json:"price" gorm:"not null"`
+	Stock       int            `json:"stock" gorm:"default:0;not null"` // Digital products: codes left in the pool
+	Type        string         `json:"type" gorm:"type:enum('physical','digital','auction');not null;default:'physical'"`
+	ImageURL    string         `json:"image_url" gorm:"size:500"` // Medium size of Images, kept for older clients
+	Images      media.ImageSet `json:"images" gorm:"type:json"`
+	Status      string         `json:"status" gorm:"type:enum('active','inactive');default:'active'"`
+	CategoryID  *uint          `json:"category_id" gorm:"index"`
+	Eligibility Eligibility    `json:"eligibility" gorm:"embedded"`
+
+	// Aggregate of published reviews
+	RatingAverage float64 `json:"rating_average" gorm:"type:decimal(3,2);default:0;not null"`
+	RatingCount   int     `json:"rating_count" gorm:"default:0;not null"`
+
+	CreatedBy uint      `json:"created_by" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Category *Category        `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
+	Variants []ProductVariant `json:"variants,omitempty" gorm:"foreignKey:ProductID"`
+	Tags     []Tag            `json:"tags,omitempty" gorm:"many2many:product_tags;"`
 }
 
 func (Product) TableName() string {
@@ -25,6 +39,9 @@ type MarketplaceTransaction struct {
 	ID            uint      `json:"id" gorm:"primaryKey"`
 	WalletID      uint      `json:"wallet_id" gorm:"not null;index"`
 	ProductID     uint      `json:"product_id" gorm:"not null;index"`
+	VariantID     *uint     `json:"variant_id" gorm:"index"`
+	VariantName   string    `json:"variant_name,omitempty" gorm:"size:100"`
+	OrderID       *uint     `json:"order_id" gorm:"index"`
 	Amount        int       `json:"amount" gorm:"not null"`                           // Individual item price
 	TotalAmount   int       `json:"total_amount" gorm:"column:total_amount;not null"` // This fixes the DB constraint error
 	Quantity      int       `json:"quantity" gorm:"default:1;not null"`
@@ -35,13 +52,39 @@ type MarketplaceTransaction struct {
 	PaymentMethod string    `json:"payment_method" gorm:"size:50;default:'wallet'"`
 	Status        string    `json:"status" gorm:"type:enum('success','failed');default:'success'"`
 	CreatedAt     time.Time `json:"created_at"`
+
+	// Pricing: OriginalAmount - DiscountAmount = TotalAmount
+	OriginalAmount int    `json:"original_amount" gorm:"default:0"`
+	DiscountAmount int    `json:"discount_amount" gorm:"default:0"`
+	PromotionID    *uint  `json:"promotion_id" gorm:"index"`
+	CouponID       *uint  `json:"coupon_id" gorm:"index"`
+	CouponCode     string `json:"coupon_code,omitempty" gorm:"size:50"`
+
+	// Fulfilment lifecycle: paid -> ready_for_pickup -> collected, or cancelled -> refunded
+	FulfillmentStatus string     `json:"fulfillment_status" gorm:"type:enum('paid','ready_for_pickup','collected','cancelled','refunded');not null;default:'paid'"`
+	PickupCode        string     `json:"pickup_code,omitempty" gorm:"size:12;index"`
+	ReadyAt           *time.Time `json:"ready_at"`
+	CollectedAt       *time.Time `json:"collected_at"`
+	CancelledAt       *time.Time `json:"cancelled_at"`
+	RefundedAt        *time.Time `json:"refunded_at"`
+	CancelReason      string     `json:"cancel_reason,omitempty" gorm:"size:255"`
+	HandledBy         *uint      `json:"handled_by"`
+
+	// Codes delivered for a digital product
+	DigitalCodes []DigitalCode `json:"digital_codes,omitempty" gorm:"foreignKey:TransactionID"`
+
+	// Virtual fields for response
+	ProductName string `json:"product_name,omitempty" gorm:"-"`
 }
 
 type PurchaseRequest struct {
 	ProductID     uint   `json:"product_id" binding:"required"`
+	VariantID     *uint  `json:"variant_id"`
+	ReservationID *uint  `json:"reservation_id"` // Stock held for a pending QR payment
 	Quantity      int    `json:"quantity" binding:"omitempty,gt=0"`
 	PaymentMethod string `json:"payment_method" binding:"omitempty,oneof=wallet qr"`
 	PaymentToken  string `json:"payment_token"`
+	CouponCode    string `json:"coupon_code"`
 	StudentName   string `json:"student_name"`
 	StudentNPM    string `json:"student_npm"`
 	StudentMajor  string `json:"student_major"`
@@ -53,45 +96,82 @@ func (MarketplaceTransaction) TableName() string {
 }
 
 type MarketplaceTransactionWithDetails struct {
-	ID            uint      `json:"id"`
-	WalletID      uint      `json:"wallet_id"`
-	ProductID     uint      `json:"product_id"`
-	Amount        int       `json:"amount"`
-	TotalAmount   int       `json:"total_amount"`
-	Quantity      int       `json:"quantity"`
-	StudentName   string    `json:"student_name"`
-	StudentNPM    string    `json:"student_npm"`
-	StudentMajor  string    `json:"student_major"`
-	StudentBatch  string    `json:"student_batch"`
-	PaymentMethod string    `json:"payment_method"`
-	Status        string    `json:"status"`
-	CreatedAt     time.Time `json:"created_at"`
-	ProductName   string    `json:"product_name"`
-	UserName      string    `json:"user_name"`
-	UserEmail     string    `json:"user_email"`
+	ID                uint       `json:"id"`
+	WalletID          uint       `json:"wallet_id"`
+	ProductID         uint       `json:"product_id"`
+	VariantID         *uint      `json:"variant_id"`
+	VariantName       string     `json:"variant_name"`
+	OrderID           *uint      `json:"order_id"`
+	Amount            int        `json:"amount"`
+	TotalAmount       int        `json:"total_amount"`
+	Quantity          int        `json:"quantity"`
+	StudentName       string     `json:"student_name"`
+	StudentNPM        string     `json:"student_npm"`
+	StudentMajor      string     `json:"student_major"`
+	StudentBatch      string     `json:"student_batch"`
+	PaymentMethod     string     `json:"payment_method"`
+	Status            string     `json:"status"`
+	OriginalAmount    int        `json:"original_amount"`
+	DiscountAmount    int        `json:"discount_amount"`
+	PromotionID       *uint      `json:"promotion_id"`
+	CouponCode        string     `json:"coupon_code"`
+	FulfillmentStatus string     `json:"fulfillment_status"`
+	ReadyAt           *time.Time `json:"ready_at"`
+	CollectedAt       *time.Time `json:"collected_at"`
+	CancelledAt       *time.Time `json:"cancelled_at"`
+	RefundedAt        *time.Time `json:"refunded_at"`
+	CancelReason      string     `json:"cancel_reason"`
+	CreatedAt         time.Time  `json:"created_at"`
+	ProductName       string     `json:"product_name"`
+	UserName          string     `json:"user_name"`
+	UserEmail         string     `json:"user_email"`
+}
+
+type CollectPickupRequest struct {
+	PickupCode string `json:"pickup_code" binding:"required"`
+}
+
+type CancelTransactionRequest struct {
+	Reason string `json:"reason" binding:"max=255"`
 }
 
 type CreateProductRequest struct {
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description"`
-	Price       int    `json:"price" binding:"required,gt=0"`
-	Stock       int    `json:"stock" binding:"gte=0"`
-	ImageURL    string `json:"image_url"`
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Price       int      `json:"price" binding:"required,gt=0"`
+	Stock       int      `json:"stock" binding:"gte=0"` // Ignored for digital products
+	Type        string   `json:"type" binding:"omitempty,oneof=physical digital auction"`
+	ImageURL    string   `json:"image_url"`
+	CategoryID  *uint    `json:"category_id"`
+	Tags        []string `json:"tags" binding:"omitempty,dive,max=50"`
+
+	Eligibility *EligibilityRequest `json:"eligibility"`
 }
 
 type UpdateProductRequest struct {
-	Name        string `json:"name,omitempty"`
-	Description string `json:"description,omitempty"`
-	Price       int    `json:"price,omitempty" binding:"omitempty,gt=0"`
-	Stock       int    `json:"stock,omitempty" binding:"omitempty,gte=0"`
-	ImageURL    string `json:"image_url,omitempty"`
-	Status      string `json:"status,omitempty" binding:"omitempty,oneof=active inactive"`
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Price       int      `json:"price,omitempty" binding:"omitempty,gt=0"`
+	Stock       int      `json:"stock,omitempty" binding:"omitempty,gte=0"`
+	ImageURL    string   `json:"image_url,omitempty"`
+	Status      string   `json:"status,omitempty" binding:"omitempty,oneof=active inactive"`
+	CategoryID  *uint    `json:"category_id,omitempty"`
+	Tags        []string `json:"tags,omitempty" binding:"omitempty,dive,max=50"` // nil keeps the current tags
+
+	Eligibility *EligibilityRequest `json:"eligibility,omitempty"` // nil keeps the current rules
 }
 
 type ProductListParams struct {
-	Status string
-	Page   int
-	Limit  int
+	Status     string
+	Search     string
+	CategoryID uint
+	CreatedBy  uint // Owner's catalog only (merchants)
+	Tag        string
+	MinPrice   int
+	MaxPrice   int
+	Sort       string // newest, price_asc, price_desc, name_asc, name_desc, rating, relevance
+	Page       int
+	Limit      int
 }
 
 type ProductListResponse struct {
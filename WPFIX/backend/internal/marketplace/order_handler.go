@@ -0,0 +1,241 @@
+package marketplace
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCart handles getting the current student's cart
+// @Summary Get cart
+// @Description Get the current student's shopping cart
+// @Tags Marketplace - Cart
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=CartResponse}
+// @Router /mahasiswa/cart [get]
+func (h *MarketplaceHandler) GetCart(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	cart, err := h.service.GetCart(userID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve cart", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Cart retrieved successfully", cart)
+}
+
+// AddToCart handles adding a product to the cart
+// @Summary Add to cart
+// @Description Add a product to the cart, merging with an existing line
+// @Tags Marketplace - Cart
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body AddCartItemRequest true "Cart item"
+// @Success 200 {object} utils.Response{data=CartResponse}
+// @Failure 400 {object} utils.Response
+// @Router /mahasiswa/cart/items [post]
+func (h *MarketplaceHandler) AddToCart(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req AddCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	cart, err := h.service.AddToCart(userID, &req)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "product not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Product added to cart", cart)
+}
+
+// UpdateCartItem handles changing the quantity of a cart line
+// @Summary Update cart item
+// @Tags Marketplace - Cart
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Cart item ID"
+// @Param request body UpdateCartItemRequest true "New quantity"
+// @Success 200 {object} utils.Response{data=CartResponse}
+// @Failure 400 {object} utils.Response
+// @Router /mahasiswa/cart/items/{id} [put]
+func (h *MarketplaceHandler) UpdateCartItem(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	itemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid cart item ID", nil)
+		return
+	}
+
+	var req UpdateCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	cart, err := h.service.UpdateCartItem(userID, uint(itemID), &req)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "cart item not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Cart updated successfully", cart)
+}
+
+// RemoveCartItem handles removing a line from the cart
+// @Summary Remove cart item
+// @Tags Marketplace - Cart
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Cart item ID"
+// @Success 200 {object} utils.Response{data=CartResponse}
+// @Failure 404 {object} utils.Response
+// @Router /mahasiswa/cart/items/{id} [delete]
+func (h *MarketplaceHandler) RemoveCartItem(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	itemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid cart item ID", nil)
+		return
+	}
+
+	cart, err := h.service.RemoveCartItem(userID, uint(itemID))
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "cart item not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Item removed from cart", cart)
+}
+
+// ClearCart handles emptying the cart
+// @Summary Clear cart
+// @Tags Marketplace - Cart
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /mahasiswa/cart [delete]
+func (h *MarketplaceHandler) ClearCart(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	if err := h.service.ClearCart(userID); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to clear cart", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Cart cleared", nil)
+}
+
+// Checkout handles buying everything in the cart
+// @Summary Checkout cart
+// @Description Buy every item in the cart as a single atomic order
+// @Tags Marketplace - Cart
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CheckoutRequest false "Buyer details"
+// @Success 200 {object} utils.Response{data=OrderReceipt}
+// @Failure 400 {object} utils.Response
+// @Router /mahasiswa/cart/checkout [post]
+func (h *MarketplaceHandler) Checkout(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req CheckoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ValidationErrorResponse(c, err.Error())
+			return
+		}
+	}
+
+	receipt, err := h.service.Checkout(userID, &req)
+	if err != nil {
+		h.purchaseError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Checkout successful", receipt)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "CHECKOUT_ORDER",
+		Entity:    "ORDER",
+		EntityID:  receipt.OrderID,
+		Details:   fmt.Sprintf("User checked out %d items for %d points", receipt.ItemCount, receipt.TotalAmount),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// GetMyOrders handles getting the current student's orders
+// @Summary Get my orders
+// @Tags Marketplace - Cart
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=OrderListResponse}
+// @Router /mahasiswa/orders [get]
+func (h *MarketplaceHandler) GetMyOrders(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	response, err := h.service.GetMyOrders(userID, page, limit)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve orders", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Orders retrieved successfully", response)
+}
+
+// GetMyOrder handles getting a single order
+// @Summary Get order by ID
+// @Tags Marketplace - Cart
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} utils.Response{data=Order}
+// @Failure 404 {object} utils.Response
+// @Router /mahasiswa/orders/{id} [get]
+func (h *MarketplaceHandler) GetMyOrder(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid order ID", nil)
+		return
+	}
+
+	order, err := h.service.GetMyOrder(userID, uint(orderID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Order retrieved successfully", order)
+}
@@ -0,0 +1,137 @@
+package marketplace
+
+import (
+	"sort"
+	"time"
+)
+
+// CartItem is a line in a student's cart. VariantID is 0 for products without
+// variants rather than NULL, since MySQL unique indexes treat NULLs as distinct
+// and would allow the same product twice.
+type CartItem struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_cart_user_product_variant"`
+	ProductID uint      `json:"product_id" gorm:"not null;uniqueIndex:idx_cart_user_product_variant"`
+	VariantID uint      `json:"variant_id" gorm:"not null;default:0;uniqueIndex:idx_cart_user_product_variant"`
+	Quantity  int       `json:"quantity" gorm:"not null;default:1"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (CartItem) TableName() string {
+	return "cart_items"
+}
+
+// cartVariantID is the variant_id stored for a line of a variant, or of a
+// product without variants when variantID is nil
+func cartVariantID(variantID *uint) uint {
+	if variantID == nil {
+		return 0
+	}
+	return *variantID
+}
+
+// variant returns the line's variant ID, or nil for a product without variants
+func (c *CartItem) variant() *uint {
+	if c.VariantID == 0 {
+		return nil
+	}
+	return &c.VariantID
+}
+
+// sortCartItems puts cart lines in the order checkout locks their products and
+// variants in, so two checkouts sharing products cannot deadlock
+func sortCartItems(items []CartItem) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].ProductID != items[j].ProductID {
+			return items[i].ProductID < items[j].ProductID
+		}
+		return items[i].VariantID < items[j].VariantID
+	})
+}
+
+// Order groups the marketplace transactions of a single cart checkout
+type Order struct {
+	ID            uint                     `json:"id" gorm:"primaryKey"`
+	WalletID      uint                     `json:"wallet_id" gorm:"not null;index"`
+	TotalAmount   int                      `json:"total_amount" gorm:"not null"`
+	ItemCount     int                      `json:"item_count" gorm:"not null"`
+	PaymentMethod string                   `json:"payment_method" gorm:"size:50;default:'wallet'"`
+	Status        string                   `json:"status" gorm:"type:enum('success','failed');default:'success'"`
+	Items         []MarketplaceTransaction `json:"items,omitempty" gorm:"foreignKey:OrderID"`
+	CreatedAt     time.Time                `json:"created_at"`
+	UpdatedAt     time.Time                `json:"updated_at"`
+}
+
+func (Order) TableName() string {
+	return "orders"
+}
+
+type CartItemWithProduct struct {
+	ID            uint   `json:"id"`
+	ProductID     uint   `json:"product_id"`
+	VariantID     *uint  `json:"variant_id"`
+	Quantity      int    `json:"quantity"`
+	ProductName   string `json:"product_name"`
+	VariantName   string `json:"variant_name"`
+	ImageURL      string `json:"image_url"`
+	Price         int    `json:"price"`
+	Stock         int    `json:"stock"`
+	ProductStatus string `json:"product_status"`
+	Subtotal      int    `json:"subtotal"`
+}
+
+type CartResponse struct {
+	Items      []CartItemWithProduct `json:"items"`
+	TotalItems int                   `json:"total_items"`
+	Total      int                   `json:"total"`
+}
+
+type AddCartItemRequest struct {
+	ProductID uint  `json:"product_id" binding:"required"`
+	VariantID *uint `json:"variant_id"`
+	Quantity  int   `json:"quantity" binding:"omitempty,gt=0"`
+}
+
+type UpdateCartItemRequest struct {
+	Quantity int `json:"quantity" binding:"required,gt=0"`
+}
+
+type CheckoutRequest struct {
+	StudentName  string `json:"student_name"`
+	StudentNPM   string `json:"student_npm"`
+	StudentMajor string `json:"student_major"`
+	StudentBatch string `json:"student_batch"`
+}
+
+type ReceiptLine struct {
+	TransactionID uint   `json:"transaction_id"`
+	ProductID     uint   `json:"product_id"`
+	ProductName   string `json:"product_name"`
+	VariantName   string `json:"variant_name,omitempty"`
+	Quantity      int    `json:"quantity"`
+	UnitPrice     int    `json:"unit_price"`
+	Subtotal      int    `json:"subtotal"`
+
+	DigitalCodes []DigitalCode `json:"digital_codes,omitempty"`
+}
+
+// OrderReceipt is the consolidated summary of a checkout
+type OrderReceipt struct {
+	OrderID       uint          `json:"order_id"`
+	Lines         []ReceiptLine `json:"lines"`
+	ItemCount     int           `json:"item_count"`
+	TotalAmount   int           `json:"total_amount"`
+	BalanceBefore int           `json:"balance_before"`
+	BalanceAfter  int           `json:"balance_after"`
+	PaymentMethod string        `json:"payment_method"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+type OrderListResponse struct {
+	Orders     []Order `json:"orders"`
+	Total      int64   `json:"total"`
+	Page       int     `json:"page"`
+	Limit      int     `json:"limit"`
+	TotalPages int     `json:"total_pages"`
+}
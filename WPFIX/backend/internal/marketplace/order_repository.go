@@ -0,0 +1,140 @@
+package marketplace
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetCart gets a user's cart items joined with current product data
+func (r *MarketplaceRepository) GetCart(userID uint) ([]CartItemWithProduct, error) {
+	var items []CartItemWithProduct
+	err := r.db.Table("cart_items ci").
+		Select("ci.id, ci.product_id, NULLIF(ci.variant_id, 0) as variant_id, ci.quantity, p.name as product_name, pv.name as variant_name, p.image_url, "+
+			"COALESCE(pv.price, p.price) as price, COALESCE(pv.stock, p.stock) as stock, "+
+			"CASE WHEN pv.id IS NOT NULL AND pv.status <> 'active' THEN pv.status ELSE p.status END as product_status, "+
+			"COALESCE(pv.price, p.price) * ci.quantity as subtotal").
+		Joins("JOIN products p ON p.id = ci.product_id").
+		Joins("LEFT JOIN product_variants pv ON pv.id = ci.variant_id").
+		Where("ci.user_id = ?", userID).
+		Order("ci.created_at ASC").
+		Scan(&items).Error
+	return items, err
+}
+
+// FindCartItem finds a cart item owned by a user
+func (r *MarketplaceRepository) FindCartItem(userID, itemID uint) (*CartItem, error) {
+	var item CartItem
+	err := r.db.Where("id = ? AND user_id = ?", itemID, userID).First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("cart item not found")
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// FindCartItemByProduct finds the cart line for a product variant, if any;
+// variantID is 0 for a product without variants
+func (r *MarketplaceRepository) FindCartItemByProduct(userID, productID, variantID uint) (*CartItem, error) {
+	var item CartItem
+	err := r.db.Where("user_id = ? AND product_id = ? AND variant_id = ?", userID, productID, variantID).First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("cart item not found")
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// SaveCartItem creates or updates a cart item
+func (r *MarketplaceRepository) SaveCartItem(item *CartItem) error {
+	return r.db.Save(item).Error
+}
+
+// DeleteCartItem removes a cart item owned by a user
+func (r *MarketplaceRepository) DeleteCartItem(userID, itemID uint) error {
+	return r.db.Where("id = ? AND user_id = ?", itemID, userID).Delete(&CartItem{}).Error
+}
+
+// ClearCart removes every item in a user's cart
+func (r *MarketplaceRepository) ClearCart(tx *gorm.DB, userID uint) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Where("user_id = ?", userID).Delete(&CartItem{}).Error
+}
+
+// LockCartWithTx loads a user's cart items and locks them for the checkout
+func (r *MarketplaceRepository) LockCartWithTx(tx *gorm.DB, userID uint) ([]CartItem, error) {
+	var items []CartItem
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", userID).
+		Order("product_id ASC, variant_id ASC").
+		Find(&items).Error
+	return items, err
+}
+
+// FindByIDWithTx finds a product inside a transaction and locks the row
+func (r *MarketplaceRepository) FindByIDWithTx(tx *gorm.DB, productID uint) (*Product, error) {
+	var product Product
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, productID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+	return &product, nil
+}
+
+// DecrementStock takes quantity units out of stock only if enough remain.
+// It reports false when the guarded update matched no row.
+func (r *MarketplaceRepository) DecrementStock(tx *gorm.DB, productID uint, quantity int) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	result := tx.Model(&Product{}).
+		Where("id = ? AND stock >= ?", productID, quantity).
+		Update("stock", gorm.Expr("stock - ?", quantity))
+	return result.RowsAffected == 1, result.Error
+}
+
+// CreateOrder creates an order record
+func (r *MarketplaceRepository) CreateOrder(tx *gorm.DB, order *Order) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Create(order).Error
+}
+
+// GetOrdersByWallet gets a wallet's orders with their line items
+func (r *MarketplaceRepository) GetOrdersByWallet(walletID uint, page, limit int) ([]Order, int64, error) {
+	var orders []Order
+	var total int64
+
+	query := r.db.Model(&Order{}).Where("wallet_id = ?", walletID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err := query.Preload("Items.DigitalCodes").Order("created_at DESC").Limit(limit).Offset(offset).Find(&orders).Error
+	return orders, total, err
+}
+
+// FindOrderByID finds an order with its line items
+func (r *MarketplaceRepository) FindOrderByID(orderID uint) (*Order, error) {
+	var order Order
+	err := r.db.Preload("Items.DigitalCodes").First(&order, orderID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("order not found")
+		}
+		return nil, err
+	}
+	return &order, nil
+}
@@ -0,0 +1,398 @@
+package marketplace
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"wallet-point/internal/fraud"
+	"wallet-point/internal/wallet"
+
+	"gorm.io/gorm"
+)
+
+// GetCart gets a user's cart with totals
+func (s *MarketplaceService) GetCart(userID uint) (*CartResponse, error) {
+	items, err := s.repo.GetCart(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &CartResponse{Items: items}
+	for _, item := range items {
+		response.TotalItems += item.Quantity
+		response.Total += item.Subtotal
+	}
+
+	return response, nil
+}
+
+// AddToCart adds a product to the cart, merging with an existing line
+func (s *MarketplaceService) AddToCart(userID uint, req *AddCartItemRequest) (*CartResponse, error) {
+	product, err := s.repo.FindByID(req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if product.Status != "active" {
+		return nil, errors.New("product is not active")
+	}
+	if product.isAuction() {
+		return nil, errors.New("auction products can only be won by bidding")
+	}
+
+	variant, err := s.resolveVariant(nil, product, req.VariantID)
+	if err != nil {
+		return nil, err
+	}
+	stock := product.Stock
+	if variant != nil {
+		stock = variant.Stock
+	}
+
+	quantity := req.Quantity
+	if quantity <= 0 {
+		quantity = 1
+	}
+
+	item, err := s.repo.FindCartItemByProduct(userID, product.ID, cartVariantID(req.VariantID))
+	if err != nil {
+		item = &CartItem{UserID: userID, ProductID: product.ID, VariantID: cartVariantID(req.VariantID)}
+	}
+	item.Quantity += quantity
+
+	if item.Quantity > stock {
+		return nil, fmt.Errorf("only %d left in stock", stock)
+	}
+
+	if err := s.repo.SaveCartItem(item); err != nil {
+		return nil, errors.New("failed to update cart")
+	}
+
+	return s.GetCart(userID)
+}
+
+// UpdateCartItem sets the quantity of a cart line
+func (s *MarketplaceService) UpdateCartItem(userID, itemID uint, req *UpdateCartItemRequest) (*CartResponse, error) {
+	item, err := s.repo.FindCartItem(userID, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := s.repo.FindByID(item.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	stock := product.Stock
+	if item.VariantID != 0 {
+		variant, err := s.repo.FindVariant(product.ID, item.VariantID)
+		if err != nil {
+			return nil, err
+		}
+		stock = variant.Stock
+	}
+	if req.Quantity > stock {
+		return nil, fmt.Errorf("only %d left in stock", stock)
+	}
+
+	item.Quantity = req.Quantity
+	if err := s.repo.SaveCartItem(item); err != nil {
+		return nil, errors.New("failed to update cart")
+	}
+
+	return s.GetCart(userID)
+}
+
+// RemoveCartItem removes a line from the cart
+func (s *MarketplaceService) RemoveCartItem(userID, itemID uint) (*CartResponse, error) {
+	if _, err := s.repo.FindCartItem(userID, itemID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.DeleteCartItem(userID, itemID); err != nil {
+		return nil, err
+	}
+	return s.GetCart(userID)
+}
+
+// ClearCart empties the cart
+func (s *MarketplaceService) ClearCart(userID uint) error {
+	return s.repo.ClearCart(nil, userID)
+}
+
+// Checkout buys everything in the cart as one atomic order: stock is reserved
+// for every line, the student is debited once, each seller is credited once,
+// and one marketplace transaction is recorded per line.
+func (s *MarketplaceService) Checkout(userID uint, req *CheckoutRequest) (*OrderReceipt, error) {
+	var receipt *OrderReceipt
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the wallet first so the balance checked and shown on the
+		// receipt is the one debited
+		studentWallet, err := s.walletService.LockWalletByUserIDWithTx(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		items, err := s.repo.LockCartWithTx(tx, userID)
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return errors.New("cart is empty")
+		}
+		sortCartItems(items)
+
+		// 1. Reserve stock for every line
+		products := make([]*Product, len(items))
+		variants := make([]*ProductVariant, len(items))
+		lines := make([]*LinePrice, len(items))
+		total := 0
+		itemCount := 0
+		productQuantity := make(map[uint]int) // variants of one product share its limits
+		for i, item := range items {
+			product, err := s.repo.FindByIDWithTx(tx, item.ProductID)
+			if err != nil {
+				return err
+			}
+			if product.Status != "active" || product.isAuction() {
+				return fmt.Errorf("%s is no longer available", product.Name)
+			}
+			if err := s.releaseExpiredReservations(tx, product); err != nil {
+				return err
+			}
+
+			variant, err := s.resolveVariant(tx, product, item.variant())
+			if err != nil {
+				return err
+			}
+			productQuantity[product.ID] += item.Quantity
+			if err := s.checkEligibility(tx, userID, studentWallet.ID, product, productQuantity[product.ID]); err != nil {
+				return err
+			}
+			if err := s.takeStock(tx, product, variant, item.Quantity); err != nil {
+				return err
+			}
+
+			// Running promotions apply to cart lines too
+			line, err := s.priceLine(tx, userID, product, variant, item.Quantity, "")
+			if err != nil {
+				return err
+			}
+			lines[i] = line
+
+			products[i] = product
+			variants[i] = variant
+			total += line.TotalAmount
+			itemCount += item.Quantity
+		}
+
+		if studentWallet.Balance < total {
+			return fmt.Errorf("insufficient balance. Required: %d", total)
+		}
+
+		// 2. Screen the debit for suspicious patterns
+		event := fraud.Event{
+			Type:           fraud.EventPayment,
+			Channel:        "order",
+			SenderWalletID: studentWallet.ID,
+			Amount:         total,
+		}
+		assessment, err := s.fraudService.Screen(event)
+		if err != nil {
+			return err
+		}
+
+		// 3. Create the order
+		order := &Order{
+			WalletID:      studentWallet.ID,
+			TotalAmount:   total,
+			ItemCount:     itemCount,
+			PaymentMethod: "wallet",
+			Status:        "success",
+		}
+		if err := s.repo.CreateOrder(tx, order); err != nil {
+			return err
+		}
+
+		// 4. Single debit for the whole order
+		if err := s.walletService.DebitWithTransaction(tx, studentWallet.ID, total, "marketplace", fmt.Sprintf("Checkout order #%d (%d items)", order.ID, itemCount)); err != nil {
+			return err
+		}
+
+		// 5. Credit each seller once with the sum of their lines
+		sellerTotals := make(map[uint]int)
+		var sellerOrder []uint
+		for i, product := range products {
+			sellerWallet, err := s.sellerWallet(product)
+			if err != nil {
+				return err
+			}
+			if _, ok := sellerTotals[sellerWallet.ID]; !ok {
+				sellerOrder = append(sellerOrder, sellerWallet.ID)
+			}
+			sellerTotals[sellerWallet.ID] += lines[i].TotalAmount
+		}
+		for _, walletID := range sellerOrder {
+			if err := s.walletService.CreditWithTransaction(tx, walletID, sellerTotals[walletID], "marketplace_sale", fmt.Sprintf("Sale from order #%d to %s", order.ID, req.StudentName)); err != nil {
+				return err
+			}
+		}
+
+		// 6. One marketplace transaction per line
+		receipt = &OrderReceipt{
+			OrderID:       order.ID,
+			ItemCount:     itemCount,
+			TotalAmount:   total,
+			BalanceBefore: studentWallet.Balance,
+			BalanceAfter:  studentWallet.Balance - total,
+			PaymentMethod: order.PaymentMethod,
+			CreatedAt:     order.CreatedAt,
+		}
+		for i, product := range products {
+			txn := &MarketplaceTransaction{
+				WalletID:      studentWallet.ID,
+				ProductID:     product.ID,
+				OrderID:       &order.ID,
+				Quantity:      items[i].Quantity,
+				StudentName:   req.StudentName,
+				StudentNPM:    req.StudentNPM,
+				StudentMajor:  req.StudentMajor,
+				StudentBatch:  req.StudentBatch,
+				PaymentMethod: order.PaymentMethod,
+				Status:        "success",
+
+				FulfillmentStatus: "paid",
+				PickupCode:        newPickupCode(),
+			}
+			if variants[i] != nil {
+				txn.VariantID = &variants[i].ID
+				txn.VariantName = variants[i].Name
+			}
+			lines[i].applyTo(txn)
+			if err := s.repo.CreateTransaction(tx, txn); err != nil {
+				return err
+			}
+			if err := s.deliverDigitalCodes(tx, product, txn); err != nil {
+				return err
+			}
+
+			receipt.Lines = append(receipt.Lines, ReceiptLine{
+				TransactionID: txn.ID,
+				ProductID:     product.ID,
+				ProductName:   product.Name,
+				VariantName:   txn.VariantName,
+				Quantity:      txn.Quantity,
+				UnitPrice:     txn.Amount,
+				Subtotal:      txn.TotalAmount,
+				DigitalCodes:  txn.DigitalCodes,
+			})
+		}
+
+		// 7. Queue flagged orders for review
+		if err := s.fraudService.RecordWithTx(tx, event, assessment, &order.ID); err != nil {
+			return err
+		}
+
+		// 8. Empty the cart
+		return s.repo.ClearCart(tx, userID)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return receipt, nil
+}
+
+// GetMyOrders gets a student's orders with their line items
+func (s *MarketplaceService) GetMyOrders(userID uint, page, limit int) (*OrderListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+
+	studentWallet, err := s.walletService.GetWalletByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, total, err := s.repo.GetOrdersByWallet(studentWallet.ID, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range orders {
+		if err := s.populateProductNames(orders[i].Items); err != nil {
+			return nil, err
+		}
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	return &OrderListResponse{
+		Orders:     orders,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// GetMyOrder gets a single order owned by the student
+func (s *MarketplaceService) GetMyOrder(userID, orderID uint) (*Order, error) {
+	studentWallet, err := s.walletService.GetWalletByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.repo.FindOrderByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.WalletID != studentWallet.ID {
+		return nil, errors.New("order not found")
+	}
+
+	if err := s.populateProductNames(order.Items); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// sellerWallet resolves the wallet credited for a product sale: the wallet of
+// the admin or merchant who owns the product
+func (s *MarketplaceService) sellerWallet(product *Product) (*wallet.Wallet, error) {
+	creatorWallet, err := s.walletService.GetWalletByUserID(product.CreatedBy)
+	if err != nil {
+		return nil, fmt.Errorf("seller wallet not found for %s", product.Name)
+	}
+	return creatorWallet, nil
+}
+
+// populateProductNames fills in product names on marketplace transactions
+func (s *MarketplaceService) populateProductNames(txns []MarketplaceTransaction) error {
+	if len(txns) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(txns))
+	for _, t := range txns {
+		ids = append(ids, t.ProductID)
+	}
+
+	var products []Product
+	if err := s.db.Select("id, name").Where("id IN ?", ids).Find(&products).Error; err != nil {
+		return err
+	}
+
+	names := make(map[uint]string)
+	for _, p := range products {
+		names[p.ID] = p.Name
+	}
+
+	for i := range txns {
+		txns[i].ProductName = names[txns[i].ProductID]
+	}
+
+	return nil
+}
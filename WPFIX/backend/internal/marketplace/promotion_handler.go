@@ -0,0 +1,326 @@
+package marketplace
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPromotions handles listing promotions
+// @Summary Get promotions
+// @Description Get all promotions and flash sales (Admin only)
+// @Tags Admin - Promotions
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]Promotion}
+// @Router /admin/promotions [get]
+func (h *MarketplaceHandler) GetPromotions(c *gin.Context) {
+	promotions, err := h.service.GetPromotions()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve promotions", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Promotions retrieved successfully", promotions)
+}
+
+// GetRunningPromotions handles listing the promotions running right now
+// @Summary Get running promotions
+// @Description Get promotions and flash sales students can use right now
+// @Tags Marketplace
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]Promotion}
+// @Router /mahasiswa/marketplace/promotions [get]
+func (h *MarketplaceHandler) GetRunningPromotions(c *gin.Context) {
+	promotions, err := h.service.GetRunningPromotions()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve promotions", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Promotions retrieved successfully", promotions)
+}
+
+// CreatePromotion handles creating a promotion or flash sale
+// @Summary Create promotion
+// @Description Create a percentage or fixed discount on a product, a category or the whole marketplace; set stock_cap for a flash sale (Admin only)
+// @Tags Admin - Promotions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreatePromotionRequest true "Promotion details"
+// @Success 201 {object} utils.Response{data=Promotion}
+// @Failure 400 {object} utils.Response
+// @Router /admin/promotions [post]
+func (h *MarketplaceHandler) CreatePromotion(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+
+	var req CreatePromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	promotion, err := h.service.CreatePromotion(&req, adminID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Promotion created successfully", promotion)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "CREATE_PROMOTION",
+		Entity:    "PROMOTION",
+		EntityID:  promotion.ID,
+		Details:   fmt.Sprintf("Admin created promotion %s (%d %s)", promotion.Name, promotion.DiscountValue, promotion.DiscountType),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// UpdatePromotion handles updating a promotion
+// @Summary Update promotion
+// @Tags Admin - Promotions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Promotion ID"
+// @Param request body UpdatePromotionRequest true "Update data"
+// @Success 200 {object} utils.Response{data=Promotion}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/promotions/{id} [put]
+func (h *MarketplaceHandler) UpdatePromotion(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+	promotionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid promotion ID", nil)
+		return
+	}
+
+	var req UpdatePromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	promotion, err := h.service.UpdatePromotion(uint(promotionID), &req)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "promotion not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Promotion updated successfully", promotion)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "UPDATE_PROMOTION",
+		Entity:    "PROMOTION",
+		EntityID:  promotion.ID,
+		Details:   "Admin updated promotion: " + promotion.Name,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// DeletePromotion handles ending a promotion
+// @Summary Delete promotion
+// @Description End a promotion early (Admin only)
+// @Tags Admin - Promotions
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Promotion ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/promotions/{id} [delete]
+func (h *MarketplaceHandler) DeletePromotion(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+	promotionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid promotion ID", nil)
+		return
+	}
+
+	if err := h.service.DeletePromotion(uint(promotionID)); err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "promotion not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Promotion deleted successfully", nil)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "DELETE_PROMOTION",
+		Entity:    "PROMOTION",
+		EntityID:  uint(promotionID),
+		Details:   "Admin ended promotion ID: " + strconv.FormatUint(promotionID, 10),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// GetCoupons handles listing coupons
+// @Summary Get coupons
+// @Tags Admin - Promotions
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]Coupon}
+// @Router /admin/coupons [get]
+func (h *MarketplaceHandler) GetCoupons(c *gin.Context) {
+	coupons, err := h.service.GetCoupons()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve coupons", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Coupons retrieved successfully", coupons)
+}
+
+// CreateCoupon handles creating a coupon code
+// @Summary Create coupon
+// @Description Create a coupon code with optional global and per-user redemption limits (Admin only)
+// @Tags Admin - Promotions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateCouponRequest true "Coupon details"
+// @Success 201 {object} utils.Response{data=Coupon}
+// @Failure 400 {object} utils.Response
+// @Router /admin/coupons [post]
+func (h *MarketplaceHandler) CreateCoupon(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+
+	var req CreateCouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	coupon, err := h.service.CreateCoupon(&req, adminID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Coupon created successfully", coupon)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "CREATE_COUPON",
+		Entity:    "COUPON",
+		EntityID:  coupon.ID,
+		Details:   fmt.Sprintf("Admin created coupon %s (%d %s)", coupon.Code, coupon.DiscountValue, coupon.DiscountType),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// UpdateCoupon handles updating a coupon
+// @Summary Update coupon
+// @Tags Admin - Promotions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Coupon ID"
+// @Param request body UpdateCouponRequest true "Update data"
+// @Success 200 {object} utils.Response{data=Coupon}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/coupons/{id} [put]
+func (h *MarketplaceHandler) UpdateCoupon(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+	couponID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid coupon ID", nil)
+		return
+	}
+
+	var req UpdateCouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	coupon, err := h.service.UpdateCoupon(uint(couponID), &req)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "coupon not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Coupon updated successfully", coupon)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "UPDATE_COUPON",
+		Entity:    "COUPON",
+		EntityID:  coupon.ID,
+		Details:   "Admin updated coupon: " + coupon.Code,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// DeleteCoupon handles disabling a coupon
+// @Summary Delete coupon
+// @Description Disable a coupon code (Admin only)
+// @Tags Admin - Promotions
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Coupon ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/coupons/{id} [delete]
+func (h *MarketplaceHandler) DeleteCoupon(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+	couponID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid coupon ID", nil)
+		return
+	}
+
+	if err := h.service.DeleteCoupon(uint(couponID)); err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "coupon not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Coupon deleted successfully", nil)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "DELETE_COUPON",
+		Entity:    "COUPON",
+		EntityID:  uint(couponID),
+		Details:   "Admin disabled coupon ID: " + strconv.FormatUint(couponID, 10),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
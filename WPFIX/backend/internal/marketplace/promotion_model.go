@@ -0,0 +1,125 @@
+package marketplace
+
+import "time"
+
+// Promotion is an automatic discount on a product or a whole category.
+// Setting a stock cap turns it into a flash sale: only that many units are
+// sold at the promotional price.
+type Promotion struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	Name          string    `json:"name" gorm:"size:150;not null"`
+	Description   string    `json:"description" gorm:"type:text"`
+	DiscountType  string    `json:"discount_type" gorm:"type:enum('percentage','fixed');not null"`
+	DiscountValue int       `json:"discount_value" gorm:"not null"`
+	ProductID     *uint     `json:"product_id" gorm:"index"`
+	CategoryID    *uint     `json:"category_id" gorm:"index"`
+	StartsAt      time.Time `json:"starts_at" gorm:"not null"`
+	EndsAt        time.Time `json:"ends_at" gorm:"not null"`
+	StockCap      *int      `json:"stock_cap"`
+	SoldCount     int       `json:"sold_count" gorm:"default:0;not null"`
+	Status        string    `json:"status" gorm:"type:enum('active','inactive');default:'active'"`
+	CreatedBy     uint      `json:"created_by" gorm:"not null"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (Promotion) TableName() string {
+	return "promotions"
+}
+
+// Coupon is a discount code typed in by the student at purchase time
+type Coupon struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	Code          string     `json:"code" gorm:"size:50;not null;uniqueIndex"`
+	Description   string     `json:"description" gorm:"type:text"`
+	DiscountType  string     `json:"discount_type" gorm:"type:enum('percentage','fixed');not null"`
+	DiscountValue int        `json:"discount_value" gorm:"not null"`
+	MaxDiscount   int        `json:"max_discount" gorm:"default:0"` // 0 means no cap
+	MinPurchase   int        `json:"min_purchase" gorm:"default:0"`
+	ProductID     *uint      `json:"product_id" gorm:"index"`
+	CategoryID    *uint      `json:"category_id" gorm:"index"`
+	StartsAt      *time.Time `json:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at"`
+	UsageLimit    int        `json:"usage_limit" gorm:"default:0"`   // 0 means unlimited
+	PerUserLimit  int        `json:"per_user_limit" gorm:"not null"` // 0 means unlimited
+	UsedCount     int        `json:"used_count" gorm:"default:0;not null"`
+	Status        string     `json:"status" gorm:"type:enum('active','inactive');default:'active'"`
+	CreatedBy     uint       `json:"created_by" gorm:"not null"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (Coupon) TableName() string {
+	return "coupons"
+}
+
+type CouponRedemption struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	CouponID       uint      `json:"coupon_id" gorm:"not null;index:idx_redemption_coupon_user"`
+	UserID         uint      `json:"user_id" gorm:"not null;index:idx_redemption_coupon_user"`
+	TransactionID  uint      `json:"transaction_id" gorm:"not null;index"`
+	DiscountAmount int       `json:"discount_amount" gorm:"not null"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (CouponRedemption) TableName() string {
+	return "coupon_redemptions"
+}
+
+// LinePrice is the outcome of pricing one product line
+type LinePrice struct {
+	UnitPrice      int // Unit price after the promotion
+	OriginalAmount int // List price times quantity
+	TotalAmount    int // What the student pays
+	DiscountAmount int
+	Promotion      *Promotion
+	Coupon         *Coupon
+}
+
+type CreatePromotionRequest struct {
+	Name          string    `json:"name" binding:"required,max=150"`
+	Description   string    `json:"description"`
+	DiscountType  string    `json:"discount_type" binding:"required,oneof=percentage fixed"`
+	DiscountValue int       `json:"discount_value" binding:"required,gt=0"`
+	ProductID     *uint     `json:"product_id"`
+	CategoryID    *uint     `json:"category_id"`
+	StartsAt      time.Time `json:"starts_at" binding:"required"`
+	EndsAt        time.Time `json:"ends_at" binding:"required,gtfield=StartsAt"`
+	StockCap      *int      `json:"stock_cap" binding:"omitempty,gt=0"`
+}
+
+type UpdatePromotionRequest struct {
+	Name          string     `json:"name,omitempty" binding:"omitempty,max=150"`
+	Description   string     `json:"description,omitempty"`
+	DiscountValue int        `json:"discount_value,omitempty" binding:"omitempty,gt=0"`
+	StartsAt      *time.Time `json:"starts_at,omitempty"`
+	EndsAt        *time.Time `json:"ends_at,omitempty"`
+	StockCap      *int       `json:"stock_cap,omitempty" binding:"omitempty,gt=0"`
+	Status        string     `json:"status,omitempty" binding:"omitempty,oneof=active inactive"`
+}
+
+type CreateCouponRequest struct {
+	Code          string     `json:"code" binding:"required,max=50"`
+	Description   string     `json:"description"`
+	DiscountType  string     `json:"discount_type" binding:"required,oneof=percentage fixed"`
+	DiscountValue int        `json:"discount_value" binding:"required,gt=0"`
+	MaxDiscount   int        `json:"max_discount" binding:"gte=0"`
+	MinPurchase   int        `json:"min_purchase" binding:"gte=0"`
+	ProductID     *uint      `json:"product_id"`
+	CategoryID    *uint      `json:"category_id"`
+	StartsAt      *time.Time `json:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at"`
+	UsageLimit    int        `json:"usage_limit" binding:"gte=0"`
+	PerUserLimit  *int       `json:"per_user_limit" binding:"omitempty,gte=0"` // Defaults to 1, 0 means unlimited
+}
+
+type UpdateCouponRequest struct {
+	Description  string     `json:"description,omitempty"`
+	MaxDiscount  *int       `json:"max_discount,omitempty" binding:"omitempty,gte=0"`
+	MinPurchase  *int       `json:"min_purchase,omitempty" binding:"omitempty,gte=0"`
+	StartsAt     *time.Time `json:"starts_at,omitempty"`
+	EndsAt       *time.Time `json:"ends_at,omitempty"`
+	UsageLimit   *int       `json:"usage_limit,omitempty" binding:"omitempty,gte=0"`
+	PerUserLimit *int       `json:"per_user_limit,omitempty" binding:"omitempty,gte=0"`
+	Status       string     `json:"status,omitempty" binding:"omitempty,oneof=active inactive"`
+}
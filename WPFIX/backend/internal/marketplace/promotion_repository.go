@@ -0,0 +1,148 @@
+package marketplace
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetPromotions gets promotions, optionally only those running at the given time
+func (r *MarketplaceRepository) GetPromotions(runningAt *time.Time) ([]Promotion, error) {
+	var promotions []Promotion
+	query := r.db.Model(&Promotion{})
+	if runningAt != nil {
+		query = query.Where("status = ? AND starts_at <= ? AND ends_at > ?", "active", *runningAt, *runningAt).
+			Where("stock_cap IS NULL OR sold_count < stock_cap")
+	}
+	err := query.Order("starts_at DESC").Find(&promotions).Error
+	return promotions, err
+}
+
+// FindPromotionByID finds a promotion by ID
+func (r *MarketplaceRepository) FindPromotionByID(promotionID uint) (*Promotion, error) {
+	var promotion Promotion
+	err := r.db.First(&promotion, promotionID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("promotion not found")
+		}
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// CreatePromotion creates a promotion
+func (r *MarketplaceRepository) CreatePromotion(promotion *Promotion) error {
+	return r.db.Create(promotion).Error
+}
+
+// UpdatePromotion updates a promotion
+func (r *MarketplaceRepository) UpdatePromotion(promotionID uint, updates map[string]interface{}) error {
+	return r.db.Model(&Promotion{}).Where("id = ?", promotionID).Updates(updates).Error
+}
+
+// FindApplicablePromotionsWithTx finds running promotions for a product that
+// still have room for quantity units under their stock cap
+func (r *MarketplaceRepository) FindApplicablePromotionsWithTx(tx *gorm.DB, product *Product, quantity int, now time.Time) ([]Promotion, error) {
+	var promotions []Promotion
+	err := tx.Where("status = ? AND starts_at <= ? AND ends_at > ?", "active", now, now).
+		Where("product_id = ? OR (product_id IS NULL AND category_id = ?) OR (product_id IS NULL AND category_id IS NULL)", product.ID, product.CategoryID).
+		Where("stock_cap IS NULL OR sold_count + ? <= stock_cap", quantity).
+		Find(&promotions).Error
+	return promotions, err
+}
+
+// ClaimPromotionStock counts quantity units against a promotion's stock cap.
+// It reports false when the cap would be exceeded.
+func (r *MarketplaceRepository) ClaimPromotionStock(tx *gorm.DB, promotionID uint, quantity int) (bool, error) {
+	result := tx.Model(&Promotion{}).
+		Where("id = ? AND (stock_cap IS NULL OR sold_count + ? <= stock_cap)", promotionID, quantity).
+		Update("sold_count", gorm.Expr("sold_count + ?", quantity))
+	return result.RowsAffected == 1, result.Error
+}
+
+// ReleasePromotionStock gives quantity units back to a promotion's stock cap
+func (r *MarketplaceRepository) ReleasePromotionStock(tx *gorm.DB, promotionID uint, quantity int) error {
+	return tx.Model(&Promotion{}).
+		Where("id = ?", promotionID).
+		Update("sold_count", gorm.Expr("GREATEST(sold_count - ?, 0)", quantity)).
+		Error
+}
+
+// GetCoupons gets all coupons
+func (r *MarketplaceRepository) GetCoupons() ([]Coupon, error) {
+	var coupons []Coupon
+	err := r.db.Order("created_at DESC").Find(&coupons).Error
+	return coupons, err
+}
+
+// FindCouponByID finds a coupon by ID
+func (r *MarketplaceRepository) FindCouponByID(couponID uint) (*Coupon, error) {
+	var coupon Coupon
+	err := r.db.First(&coupon, couponID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("coupon not found")
+		}
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+// CreateCoupon creates a coupon
+func (r *MarketplaceRepository) CreateCoupon(coupon *Coupon) error {
+	return r.db.Create(coupon).Error
+}
+
+// UpdateCoupon updates a coupon
+func (r *MarketplaceRepository) UpdateCoupon(couponID uint, updates map[string]interface{}) error {
+	return r.db.Model(&Coupon{}).Where("id = ?", couponID).Updates(updates).Error
+}
+
+// LockCouponByCodeWithTx finds a coupon by code and locks the row, which
+// serialises concurrent redemptions of the same code
+func (r *MarketplaceRepository) LockCouponByCodeWithTx(tx *gorm.DB, code string) (*Coupon, error) {
+	var coupon Coupon
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("code = ?", code).First(&coupon).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("coupon not found")
+		}
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+// CountRedemptionsWithTx counts how many times a user has redeemed a coupon
+func (r *MarketplaceRepository) CountRedemptionsWithTx(tx *gorm.DB, couponID, userID uint) (int64, error) {
+	var count int64
+	err := tx.Model(&CouponRedemption{}).Where("coupon_id = ? AND user_id = ?", couponID, userID).Count(&count).Error
+	return count, err
+}
+
+// ClaimCouponUse counts one use against a coupon's global limit.
+// It reports false when the limit has been reached.
+func (r *MarketplaceRepository) ClaimCouponUse(tx *gorm.DB, couponID uint) (bool, error) {
+	result := tx.Model(&Coupon{}).
+		Where("id = ? AND (usage_limit = 0 OR used_count < usage_limit)", couponID).
+		Update("used_count", gorm.Expr("used_count + 1"))
+	return result.RowsAffected == 1, result.Error
+}
+
+// CreateRedemption records a coupon redemption
+func (r *MarketplaceRepository) CreateRedemption(tx *gorm.DB, redemption *CouponRedemption) error {
+	return tx.Create(redemption).Error
+}
+
+// ReleaseCouponUse removes the redemption of a cancelled purchase and frees the use
+func (r *MarketplaceRepository) ReleaseCouponUse(tx *gorm.DB, couponID, transactionID uint) error {
+	if err := tx.Where("coupon_id = ? AND transaction_id = ?", couponID, transactionID).Delete(&CouponRedemption{}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&Coupon{}).
+		Where("id = ?", couponID).
+		Update("used_count", gorm.Expr("GREATEST(used_count - 1, 0)")).
+		Error
+}
@@ -0,0 +1,398 @@
+package marketplace
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// discountFor works out a percentage or fixed discount on an amount
+func discountFor(discountType string, value, amount int) int {
+	discount := value
+	if discountType == "percentage" {
+		discount = amount * value / 100
+	}
+	if discount > amount {
+		discount = amount
+	}
+	return discount
+}
+
+// normalizeCouponCode upper-cases and trims a coupon code
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// appliesTo reports whether a product/category scope covers the product
+func appliesTo(productID, categoryID *uint, product *Product) bool {
+	if productID != nil {
+		return *productID == product.ID
+	}
+	if categoryID != nil {
+		return product.CategoryID != nil && *categoryID == *product.CategoryID
+	}
+	return true
+}
+
+// priceLine prices quantity units of a product (or variant) for a student.
+// The best running promotion is applied to the unit price and its stock cap
+// is claimed; a coupon is then applied to the line total and its global and
+// per-user limits are claimed. Everything happens inside tx, so the limits
+// are released again if the purchase fails.
+func (s *MarketplaceService) priceLine(tx *gorm.DB, userID uint, product *Product, variant *ProductVariant, quantity int, couponCode string) (*LinePrice, error) {
+	listPrice := product.Price
+	if variant != nil {
+		listPrice = variant.Price
+	}
+
+	line := &LinePrice{
+		UnitPrice:      listPrice,
+		OriginalAmount: listPrice * quantity,
+	}
+
+	// 1. Best running promotion
+	promotions, err := s.repo.FindApplicablePromotionsWithTx(tx, product, quantity, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	var best *Promotion
+	bestDiscount := 0
+	for i := range promotions {
+		discount := discountFor(promotions[i].DiscountType, promotions[i].DiscountValue, listPrice)
+		if discount > bestDiscount {
+			best = &promotions[i]
+			bestDiscount = discount
+		}
+	}
+	if best != nil {
+		ok, err := s.repo.ClaimPromotionStock(tx, best.ID, quantity)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s has sold out, please try again", best.Name)
+		}
+		line.UnitPrice = listPrice - bestDiscount
+		line.Promotion = best
+	}
+	line.TotalAmount = line.UnitPrice * quantity
+
+	// 2. Coupon code
+	if code := normalizeCouponCode(couponCode); code != "" {
+		coupon, err := s.repo.LockCouponByCodeWithTx(tx, code)
+		if err != nil {
+			return nil, errors.New("invalid coupon code")
+		}
+
+		now := time.Now()
+		if coupon.Status != "active" ||
+			(coupon.StartsAt != nil && now.Before(*coupon.StartsAt)) ||
+			(coupon.EndsAt != nil && !now.Before(*coupon.EndsAt)) {
+			return nil, errors.New("coupon is not active")
+		}
+		if !appliesTo(coupon.ProductID, coupon.CategoryID, product) {
+			return nil, errors.New("coupon does not apply to this product")
+		}
+		if line.TotalAmount < coupon.MinPurchase {
+			return nil, fmt.Errorf("coupon requires a minimum purchase of %d", coupon.MinPurchase)
+		}
+
+		if coupon.PerUserLimit > 0 {
+			used, err := s.repo.CountRedemptionsWithTx(tx, coupon.ID, userID)
+			if err != nil {
+				return nil, err
+			}
+			if used >= int64(coupon.PerUserLimit) {
+				return nil, errors.New("you have already used this coupon")
+			}
+		}
+
+		ok, err := s.repo.ClaimCouponUse(tx, coupon.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, errors.New("coupon has been fully redeemed")
+		}
+
+		discount := discountFor(coupon.DiscountType, coupon.DiscountValue, line.TotalAmount)
+		if coupon.MaxDiscount > 0 && discount > coupon.MaxDiscount {
+			discount = coupon.MaxDiscount
+		}
+		line.TotalAmount -= discount
+		line.Coupon = coupon
+	}
+
+	line.DiscountAmount = line.OriginalAmount - line.TotalAmount
+	return line, nil
+}
+
+// applyTo copies a line price onto a marketplace transaction
+func (line *LinePrice) applyTo(txn *MarketplaceTransaction) {
+	txn.Amount = line.UnitPrice
+	txn.TotalAmount = line.TotalAmount
+	txn.OriginalAmount = line.OriginalAmount
+	txn.DiscountAmount = line.DiscountAmount
+	if line.Promotion != nil {
+		txn.PromotionID = &line.Promotion.ID
+	}
+	if line.Coupon != nil {
+		txn.CouponID = &line.Coupon.ID
+		txn.CouponCode = line.Coupon.Code
+	}
+}
+
+// recordRedemption stores the coupon redemption of a priced line
+func (s *MarketplaceService) recordRedemption(tx *gorm.DB, line *LinePrice, userID uint, txn *MarketplaceTransaction) error {
+	if line.Coupon == nil {
+		return nil
+	}
+	return s.repo.CreateRedemption(tx, &CouponRedemption{
+		CouponID:       line.Coupon.ID,
+		UserID:         userID,
+		TransactionID:  txn.ID,
+		DiscountAmount: line.UnitPrice*txn.Quantity - line.TotalAmount,
+	})
+}
+
+// releasePricing gives back the promotion stock and coupon use of a cancelled purchase
+func (s *MarketplaceService) releasePricing(tx *gorm.DB, txn *MarketplaceTransaction) error {
+	if txn.PromotionID != nil {
+		if err := s.repo.ReleasePromotionStock(tx, *txn.PromotionID, txn.Quantity); err != nil {
+			return err
+		}
+	}
+	if txn.CouponID != nil {
+		if err := s.repo.ReleaseCouponUse(tx, *txn.CouponID, txn.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateScope checks the product or category a discount is limited to
+func (s *MarketplaceService) validateScope(productID, categoryID *uint) error {
+	if productID != nil && categoryID != nil {
+		return errors.New("a discount applies to either a product or a category, not both")
+	}
+	if productID != nil {
+		if _, err := s.repo.FindByID(*productID); err != nil {
+			return err
+		}
+	}
+	if categoryID != nil {
+		if _, err := s.repo.FindCategoryByID(*categoryID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPromotions gets all promotions (Admin)
+func (s *MarketplaceService) GetPromotions() ([]Promotion, error) {
+	return s.repo.GetPromotions(nil)
+}
+
+// GetRunningPromotions gets promotions students can currently benefit from
+func (s *MarketplaceService) GetRunningPromotions() ([]Promotion, error) {
+	now := time.Now()
+	return s.repo.GetPromotions(&now)
+}
+
+// CreatePromotion creates a promotion or flash sale
+func (s *MarketplaceService) CreatePromotion(req *CreatePromotionRequest, adminID uint) (*Promotion, error) {
+	if req.DiscountType == "percentage" && req.DiscountValue > 100 {
+		return nil, errors.New("percentage discount cannot exceed 100")
+	}
+	if err := s.validateScope(req.ProductID, req.CategoryID); err != nil {
+		return nil, err
+	}
+
+	promotion := &Promotion{
+		Name:          req.Name,
+		Description:   req.Description,
+		DiscountType:  req.DiscountType,
+		DiscountValue: req.DiscountValue,
+		ProductID:     req.ProductID,
+		CategoryID:    req.CategoryID,
+		StartsAt:      req.StartsAt,
+		EndsAt:        req.EndsAt,
+		StockCap:      req.StockCap,
+		Status:        "active",
+		CreatedBy:     adminID,
+	}
+
+	if err := s.repo.CreatePromotion(promotion); err != nil {
+		return nil, errors.New("failed to create promotion")
+	}
+
+	return promotion, nil
+}
+
+// UpdatePromotion updates a promotion
+func (s *MarketplaceService) UpdatePromotion(promotionID uint, req *UpdatePromotionRequest) (*Promotion, error) {
+	promotion, err := s.repo.FindPromotionByID(promotionID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != "" {
+		updates["name"] = req.Name
+	}
+	if req.Description != "" {
+		updates["description"] = req.Description
+	}
+	if req.DiscountValue > 0 {
+		if promotion.DiscountType == "percentage" && req.DiscountValue > 100 {
+			return nil, errors.New("percentage discount cannot exceed 100")
+		}
+		updates["discount_value"] = req.DiscountValue
+	}
+
+	startsAt, endsAt := promotion.StartsAt, promotion.EndsAt
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+		updates["starts_at"] = startsAt
+	}
+	if req.EndsAt != nil {
+		endsAt = *req.EndsAt
+		updates["ends_at"] = endsAt
+	}
+	if !endsAt.After(startsAt) {
+		return nil, errors.New("promotion must end after it starts")
+	}
+
+	if req.StockCap != nil {
+		if *req.StockCap < promotion.SoldCount {
+			return nil, fmt.Errorf("stock cap cannot be below the %d units already sold", promotion.SoldCount)
+		}
+		updates["stock_cap"] = *req.StockCap
+	}
+	if req.Status != "" {
+		updates["status"] = req.Status
+	}
+
+	if len(updates) > 0 {
+		if err := s.repo.UpdatePromotion(promotionID, updates); err != nil {
+			return nil, errors.New("failed to update promotion")
+		}
+	}
+
+	return s.repo.FindPromotionByID(promotionID)
+}
+
+// DeletePromotion ends a promotion (soft delete by setting status to inactive)
+func (s *MarketplaceService) DeletePromotion(promotionID uint) error {
+	if _, err := s.repo.FindPromotionByID(promotionID); err != nil {
+		return err
+	}
+	return s.repo.UpdatePromotion(promotionID, map[string]interface{}{"status": "inactive"})
+}
+
+// GetCoupons gets all coupons (Admin)
+func (s *MarketplaceService) GetCoupons() ([]Coupon, error) {
+	return s.repo.GetCoupons()
+}
+
+// CreateCoupon creates a coupon code
+func (s *MarketplaceService) CreateCoupon(req *CreateCouponRequest, adminID uint) (*Coupon, error) {
+	if req.DiscountType == "percentage" && req.DiscountValue > 100 {
+		return nil, errors.New("percentage discount cannot exceed 100")
+	}
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return nil, errors.New("coupon must end after it starts")
+	}
+	if err := s.validateScope(req.ProductID, req.CategoryID); err != nil {
+		return nil, err
+	}
+
+	perUserLimit := 1
+	if req.PerUserLimit != nil {
+		perUserLimit = *req.PerUserLimit
+	}
+
+	coupon := &Coupon{
+		Code:          normalizeCouponCode(req.Code),
+		Description:   req.Description,
+		DiscountType:  req.DiscountType,
+		DiscountValue: req.DiscountValue,
+		MaxDiscount:   req.MaxDiscount,
+		MinPurchase:   req.MinPurchase,
+		ProductID:     req.ProductID,
+		CategoryID:    req.CategoryID,
+		StartsAt:      req.StartsAt,
+		EndsAt:        req.EndsAt,
+		UsageLimit:    req.UsageLimit,
+		PerUserLimit:  perUserLimit,
+		Status:        "active",
+		CreatedBy:     adminID,
+	}
+
+	if err := s.repo.CreateCoupon(coupon); err != nil {
+		return nil, errors.New("failed to create coupon, the code may already exist")
+	}
+
+	return coupon, nil
+}
+
+// UpdateCoupon updates a coupon
+func (s *MarketplaceService) UpdateCoupon(couponID uint, req *UpdateCouponRequest) (*Coupon, error) {
+	coupon, err := s.repo.FindCouponByID(couponID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.Description != "" {
+		updates["description"] = req.Description
+	}
+	if req.MaxDiscount != nil {
+		updates["max_discount"] = *req.MaxDiscount
+	}
+	if req.MinPurchase != nil {
+		updates["min_purchase"] = *req.MinPurchase
+	}
+
+	startsAt, endsAt := coupon.StartsAt, coupon.EndsAt
+	if req.StartsAt != nil {
+		startsAt = req.StartsAt
+		updates["starts_at"] = *req.StartsAt
+	}
+	if req.EndsAt != nil {
+		endsAt = req.EndsAt
+		updates["ends_at"] = *req.EndsAt
+	}
+	if startsAt != nil && endsAt != nil && !endsAt.After(*startsAt) {
+		return nil, errors.New("coupon must end after it starts")
+	}
+
+	if req.UsageLimit != nil {
+		updates["usage_limit"] = *req.UsageLimit
+	}
+	if req.PerUserLimit != nil {
+		updates["per_user_limit"] = *req.PerUserLimit
+	}
+	if req.Status != "" {
+		updates["status"] = req.Status
+	}
+
+	if len(updates) > 0 {
+		if err := s.repo.UpdateCoupon(couponID, updates); err != nil {
+			return nil, errors.New("failed to update coupon")
+		}
+	}
+
+	return s.repo.FindCouponByID(couponID)
+}
+
+// DeleteCoupon disables a coupon (soft delete by setting status to inactive)
+func (s *MarketplaceService) DeleteCoupon(couponID uint) error {
+	if _, err := s.repo.FindCouponByID(couponID); err != nil {
+		return err
+	}
+	return s.repo.UpdateCoupon(couponID, map[string]interface{}{"status": "inactive"})
+}
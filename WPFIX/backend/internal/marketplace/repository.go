@@ -2,8 +2,11 @@ package marketplace
 
 import (
 	"errors"
+	"math"
+	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MarketplaceRepository struct {
@@ -23,7 +26,36 @@ func (r *MarketplaceRepository) GetAll(params ProductListParams) ([]Product, int
 
 	// Apply filters
 	if params.Status != "" {
-		query = query.Where("status = ?", params.Status)
+		query = query.Where("products.status = ?", params.Status)
+	}
+	if params.CategoryID != 0 {
+		query = query.Where("products.category_id = ?", params.CategoryID)
+	}
+	if params.CreatedBy != 0 {
+		query = query.Where("products.created_by = ?", params.CreatedBy)
+	}
+	if params.Tag != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM product_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.product_id = products.id AND t.name = ?)", normalizeTag(params.Tag))
+	}
+
+	// A price filter matches the base price or the price of any active variant
+	if params.MinPrice > 0 || params.MaxPrice > 0 {
+		maxPrice := params.MaxPrice
+		if maxPrice <= 0 {
+			maxPrice = math.MaxInt32
+		}
+		query = query.Where(
+			"((products.price BETWEEN ? AND ?) OR EXISTS (SELECT 1 FROM product_variants pv WHERE pv.product_id = products.id AND pv.status = 'active' AND pv.price BETWEEN ? AND ?))",
+			params.MinPrice, maxPrice, params.MinPrice, maxPrice,
+		)
+	}
+
+	searchTerm := fullTextQuery(params.Search)
+	if searchTerm != "" {
+		query = query.Where(
+			"(MATCH(products.name, products.description) AGAINST (? IN BOOLEAN MODE) OR products.name LIKE ?)",
+			searchTerm, "%"+strings.TrimSpace(params.Search)+"%",
+		)
 	}
 
 	// Count total
@@ -31,9 +63,41 @@ func (r *MarketplaceRepository) GetAll(params ProductListParams) ([]Product, int
 		return nil, 0, err
 	}
 
+	// Apply sorting
+	switch params.Sort {
+	case "price_asc":
+		query = query.Order("products.price ASC")
+	case "price_desc":
+		query = query.Order("products.price DESC")
+	case "name_asc":
+		query = query.Order("products.name ASC")
+	case "name_desc":
+		query = query.Order("products.name DESC")
+	case "rating":
+		query = query.Order("products.rating_average DESC").Order("products.rating_count DESC")
+	case "relevance":
+		if searchTerm != "" {
+			query = query.Order(clause.OrderBy{Expression: clause.Expr{
+				SQL:                "MATCH(products.name, products.description) AGAINST (? IN BOOLEAN MODE) DESC",
+				Vars:               []interface{}{searchTerm},
+				WithoutParentheses: true,
+			}})
+		}
+	}
+	query = query.Order("products.created_at DESC")
+
+	// Only show active variants when listing active products
+	variantScope := func(db *gorm.DB) *gorm.DB {
+		if params.Status != "" {
+			return db.Where("status = ?", params.Status)
+		}
+		return db
+	}
+
 	// Apply pagination
 	offset := (params.Page - 1) * params.Limit
-	query = query.Limit(params.Limit).Offset(offset).Order("created_at DESC")
+	query = query.Preload("Category").Preload("Tags").Preload("Variants", variantScope).
+		Limit(params.Limit).Offset(offset)
 
 	if err := query.Find(&products).Error; err != nil {
 		return nil, 0, err
@@ -45,7 +109,7 @@ func (r *MarketplaceRepository) GetAll(params ProductListParams) ([]Product, int
 // FindByID finds product by ID
 func (r *MarketplaceRepository) FindByID(productID uint) (*Product, error) {
 	var product Product
-	err := r.db.First(&product, productID).Error
+	err := r.db.Preload("Category").Preload("Tags").Preload("Variants").First(&product, productID).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("product not found")
@@ -89,8 +153,9 @@ func (r *MarketplaceRepository) CreateTransaction(tx *gorm.DB, transaction *Mark
 	return tx.Create(transaction).Error
 }
 
-// GetAllTransactions retrieves marketplace transactions for admin
-func (r *MarketplaceRepository) GetAllTransactions(limit, offset int) ([]MarketplaceTransactionWithDetails, int64, error) {
+// GetAllTransactions retrieves marketplace transactions for admin, or for a
+// single seller when sellerID is set
+func (r *MarketplaceRepository) GetAllTransactions(sellerID uint, limit, offset int) ([]MarketplaceTransactionWithDetails, int64, error) {
 	var transactions []MarketplaceTransactionWithDetails
 	var total int64
 
@@ -99,6 +164,9 @@ func (r *MarketplaceRepository) GetAllTransactions(limit, offset int) ([]Marketp
 		Joins("LEFT JOIN products p ON mt.product_id = p.id").
 		Joins("LEFT JOIN wallets w ON mt.wallet_id = w.id").
 		Joins("LEFT JOIN users u ON w.user_id = u.id")
+	if sellerID != 0 {
+		query = query.Where("p.created_by = ?", sellerID)
+	}
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
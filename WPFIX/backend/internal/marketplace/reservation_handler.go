@@ -0,0 +1,80 @@
+package marketplace
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReserveStock handles holding stock while a QR payment is pending
+// @Summary Reserve stock
+// @Description Hold stock for a few minutes while a QR payment is completed; pass the reservation_id to the purchase
+// @Tags Marketplace
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body ReserveStockRequest true "Reservation details"
+// @Success 201 {object} utils.Response{data=StockReservation}
+// @Failure 400 {object} utils.Response
+// @Router /mahasiswa/marketplace/reservations [post]
+func (h *MarketplaceHandler) ReserveStock(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req ReserveStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	reservation, err := h.service.ReserveStock(userID, &req)
+	if err != nil {
+		h.purchaseError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Stock reserved successfully", reservation)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "RESERVE_STOCK",
+		Entity:    "STOCK_RESERVATION",
+		EntityID:  reservation.ID,
+		Details:   fmt.Sprintf("User reserved %d units of product ID %d until %s", reservation.Quantity, reservation.ProductID, reservation.ExpiresAt.Format("15:04:05")),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// ReleaseReservation handles giving back reserved stock
+// @Summary Release reservation
+// @Tags Marketplace
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /mahasiswa/marketplace/reservations/{id} [delete]
+func (h *MarketplaceHandler) ReleaseReservation(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	reservationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid reservation ID", nil)
+		return
+	}
+
+	if err := h.service.ReleaseReservation(userID, uint(reservationID)); err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "reservation not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Reservation released", nil)
+}
@@ -0,0 +1,32 @@
+package marketplace
+
+import "time"
+
+// ReservationTTL is how long reserved stock is held for a pending QR payment
+const ReservationTTL = 10 * time.Minute
+
+// StockReservation holds stock for a student while a QR payment is pending.
+// The stock is taken out when the reservation is made and given back if it
+// is released or runs out before the purchase goes through.
+type StockReservation struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	UserID        uint      `json:"user_id" gorm:"not null;index"`
+	ProductID     uint      `json:"product_id" gorm:"not null;index"`
+	VariantID     *uint     `json:"variant_id"`
+	Quantity      int       `json:"quantity" gorm:"not null"`
+	Status        string    `json:"status" gorm:"type:enum('active','consumed','released','expired');default:'active';index:idx_reservation_status_expiry"`
+	ExpiresAt     time.Time `json:"expires_at" gorm:"not null;index:idx_reservation_status_expiry"`
+	TransactionID *uint     `json:"transaction_id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (StockReservation) TableName() string {
+	return "stock_reservations"
+}
+
+type ReserveStockRequest struct {
+	ProductID uint  `json:"product_id" binding:"required"`
+	VariantID *uint `json:"variant_id"`
+	Quantity  int   `json:"quantity" binding:"omitempty,gt=0"`
+}
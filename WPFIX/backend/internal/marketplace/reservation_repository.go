@@ -0,0 +1,49 @@
+package marketplace
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateReservation creates a stock reservation
+func (r *MarketplaceRepository) CreateReservation(tx *gorm.DB, reservation *StockReservation) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Create(reservation).Error
+}
+
+// LockReservationWithTx finds a reservation and locks the row
+func (r *MarketplaceRepository) LockReservationWithTx(tx *gorm.DB, reservationID uint) (*StockReservation, error) {
+	var reservation StockReservation
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&reservation, reservationID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("reservation not found")
+		}
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// LockExpiredReservationsWithTx loads a product's active reservations past
+// their expiry and locks them
+func (r *MarketplaceRepository) LockExpiredReservationsWithTx(tx *gorm.DB, productID uint, now time.Time) ([]StockReservation, error) {
+	var reservations []StockReservation
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND status = ? AND expires_at <= ?", productID, "active", now).
+		Order("id ASC").
+		Find(&reservations).Error
+	return reservations, err
+}
+
+// UpdateReservationWithTx updates a reservation inside a transaction
+func (r *MarketplaceRepository) UpdateReservationWithTx(tx *gorm.DB, reservationID uint, updates map[string]interface{}) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.Model(&StockReservation{}).Where("id = ?", reservationID).Updates(updates).Error
+}
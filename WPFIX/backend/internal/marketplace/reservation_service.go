@@ -0,0 +1,168 @@
+package marketplace
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// takeStock removes quantity units from a product (and its variant) with
+// guarded updates, failing instead of letting stock go negative
+func (s *MarketplaceService) takeStock(tx *gorm.DB, product *Product, variant *ProductVariant, quantity int) error {
+	if variant != nil {
+		ok, err := s.repo.DecrementVariantStock(tx, variant.ID, quantity)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("insufficient stock for %s (%s) (available: %d)", product.Name, variant.Name, variant.Stock)
+		}
+	}
+
+	ok, err := s.repo.DecrementStock(tx, product.ID, quantity)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("insufficient stock for %s (available: %d)", product.Name, product.Stock)
+	}
+
+	return nil
+}
+
+// restoreStock puts quantity units back on a product (and its variant)
+func (s *MarketplaceService) restoreStock(tx *gorm.DB, productID uint, variantID *uint, quantity int) error {
+	if variantID != nil {
+		if err := s.repo.UpdateVariantStock(tx, *variantID, quantity); err != nil {
+			return err
+		}
+	}
+	return s.repo.UpdateStock(tx, productID, quantity)
+}
+
+// checkReservation verifies a reservation can pay for the given product line
+func checkReservation(reservation *StockReservation, userID uint, product *Product, variant *ProductVariant) error {
+	if reservation.UserID != userID {
+		return errors.New("reservation not found")
+	}
+	if reservation.Status != "active" {
+		return fmt.Errorf("reservation is %s", reservation.Status)
+	}
+	if time.Now().After(reservation.ExpiresAt) {
+		return errors.New("reservation has expired")
+	}
+	if reservation.ProductID != product.ID {
+		return errors.New("reservation is for a different product")
+	}
+	if (variant == nil) != (reservation.VariantID == nil) || (variant != nil && *reservation.VariantID != variant.ID) {
+		return errors.New("reservation is for a different variant")
+	}
+	return nil
+}
+
+// releaseExpiredReservations gives back the stock of a product's reservations
+// that ran out. The product must already be locked by the transaction, so
+// purchases of other products are never held up; its Stock is updated too.
+func (s *MarketplaceService) releaseExpiredReservations(tx *gorm.DB, product *Product) error {
+	reservations, err := s.repo.LockExpiredReservationsWithTx(tx, product.ID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, reservation := range reservations {
+		if err := s.restoreStock(tx, reservation.ProductID, reservation.VariantID, reservation.Quantity); err != nil {
+			return err
+		}
+		if err := s.repo.UpdateReservationWithTx(tx, reservation.ID, map[string]interface{}{"status": "expired"}); err != nil {
+			return err
+		}
+		product.Stock += reservation.Quantity
+	}
+
+	return nil
+}
+
+// ReserveStock holds stock for a student while a QR payment is pending
+func (s *MarketplaceService) ReserveStock(userID uint, req *ReserveStockRequest) (*StockReservation, error) {
+	var reservation *StockReservation
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		product, err := s.repo.FindByIDWithTx(tx, req.ProductID)
+		if err != nil {
+			return err
+		}
+		if product.Status == "inactive" {
+			return errors.New("product is not active")
+		}
+		if product.isAuction() {
+			return errors.New("auction products can only be won by bidding")
+		}
+		if err := s.releaseExpiredReservations(tx, product); err != nil {
+			return err
+		}
+
+		variant, err := s.resolveVariant(tx, product, req.VariantID)
+		if err != nil {
+			return err
+		}
+
+		quantity := req.Quantity
+		if quantity <= 0 {
+			quantity = 1
+		}
+
+		studentWallet, err := s.walletService.GetWalletByUserID(userID)
+		if err != nil {
+			return err
+		}
+		if err := s.checkEligibility(tx, userID, studentWallet.ID, product, quantity); err != nil {
+			return err
+		}
+
+		if err := s.takeStock(tx, product, variant, quantity); err != nil {
+			return err
+		}
+
+		reservation = &StockReservation{
+			UserID:    userID,
+			ProductID: product.ID,
+			Quantity:  quantity,
+			Status:    "active",
+			ExpiresAt: time.Now().Add(ReservationTTL),
+		}
+		if variant != nil {
+			reservation.VariantID = &variant.ID
+		}
+
+		return s.repo.CreateReservation(tx, reservation)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+// ReleaseReservation gives back the stock of a reservation the student no longer needs
+func (s *MarketplaceService) ReleaseReservation(userID, reservationID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		reservation, err := s.repo.LockReservationWithTx(tx, reservationID)
+		if err != nil {
+			return err
+		}
+		if reservation.UserID != userID {
+			return errors.New("reservation not found")
+		}
+		if reservation.Status != "active" {
+			return fmt.Errorf("reservation is %s", reservation.Status)
+		}
+
+		if err := s.restoreStock(tx, reservation.ProductID, reservation.VariantID, reservation.Quantity); err != nil {
+			return err
+		}
+
+		return s.repo.UpdateReservationWithTx(tx, reservation.ID, map[string]interface{}{"status": "released"})
+	})
+}
@@ -0,0 +1,250 @@
+package marketplace
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetProductReviews handles listing the published reviews of a product
+// @Summary Get product reviews
+// @Tags Marketplace - Reviews
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=ReviewListResponse}
+// @Router /mahasiswa/marketplace/products/{id}/reviews [get]
+func (h *MarketplaceHandler) GetProductReviews(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", nil)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	reviews, err := h.service.GetReviews(ReviewListParams{
+		ProductID: uint(productID),
+		Status:    "published",
+		Page:      page,
+		Limit:     limit,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve reviews", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Reviews retrieved successfully", reviews)
+}
+
+// SubmitReview handles a student reviewing a product they bought
+// @Summary Review product
+// @Description Rate a purchased product from 1 to 5 with an optional comment; reviewing again replaces the previous review, which stays hidden if an admin hid it
+// @Tags Marketplace - Reviews
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param request body CreateReviewRequest true "Review"
+// @Success 201 {object} utils.Response{data=Review}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /mahasiswa/marketplace/products/{id}/reviews [post]
+func (h *MarketplaceHandler) SubmitReview(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", nil)
+		return
+	}
+
+	var req CreateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	review, err := h.service.SubmitReview(userID, uint(productID), &req)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		switch err.Error() {
+		case "product not found":
+			statusCode = http.StatusNotFound
+		case "only students who bought this product can review it":
+			statusCode = http.StatusForbidden
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Review submitted successfully", review)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "SUBMIT_REVIEW",
+		Entity:    "REVIEW",
+		EntityID:  review.ID,
+		Details:   fmt.Sprintf("User rated product ID %d with %d stars", productID, review.Rating),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// DeleteMyReview handles a student removing their own review
+// @Summary Delete my review
+// @Tags Marketplace - Reviews
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Review ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /mahasiswa/marketplace/reviews/{id} [delete]
+func (h *MarketplaceHandler) DeleteMyReview(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	reviewID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid review ID", nil)
+		return
+	}
+
+	if err := h.service.DeleteMyReview(userID, uint(reviewID)); err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "review not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Review deleted successfully", nil)
+}
+
+// GetReviews handles listing reviews for moderation
+// @Summary Get reviews
+// @Description List all reviews, including hidden ones (Admin only)
+// @Tags Admin - Reviews
+// @Security BearerAuth
+// @Produce json
+// @Param product_id query int false "Filter by product"
+// @Param status query string false "Filter by status" Enums(published, hidden)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=ReviewListResponse}
+// @Router /admin/reviews [get]
+func (h *MarketplaceHandler) GetReviews(c *gin.Context) {
+	productID, _ := strconv.ParseUint(c.Query("product_id"), 10, 32)
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	reviews, err := h.service.GetReviews(ReviewListParams{
+		ProductID: uint(productID),
+		Status:    c.Query("status"),
+		Page:      page,
+		Limit:     limit,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve reviews", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Reviews retrieved successfully", reviews)
+}
+
+// ModerateReview handles publishing or hiding a review
+// @Summary Moderate review
+// @Description Publish or hide a review; hidden reviews do not count towards the product rating (Admin only)
+// @Tags Admin - Reviews
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Review ID"
+// @Param request body ModerateReviewRequest true "Moderation decision"
+// @Success 200 {object} utils.Response{data=Review}
+// @Failure 404 {object} utils.Response
+// @Router /admin/reviews/{id}/moderate [put]
+func (h *MarketplaceHandler) ModerateReview(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+	reviewID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid review ID", nil)
+		return
+	}
+
+	var req ModerateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	review, err := h.service.ModerateReview(uint(reviewID), adminID, &req)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "review not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Review moderated successfully", review)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "MODERATE_REVIEW",
+		Entity:    "REVIEW",
+		EntityID:  review.ID,
+		Details:   fmt.Sprintf("Admin set review ID %d to %s", review.ID, review.Status),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// DeleteReview handles removing a review
+// @Summary Delete review
+// @Tags Admin - Reviews
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Review ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/reviews/{id} [delete]
+func (h *MarketplaceHandler) DeleteReview(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+	reviewID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid review ID", nil)
+		return
+	}
+
+	if err := h.service.DeleteReview(uint(reviewID)); err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "review not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Review deleted successfully", nil)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    "DELETE_REVIEW",
+		Entity:    "REVIEW",
+		EntityID:  uint(reviewID),
+		Details:   "Admin deleted review ID: " + strconv.FormatUint(reviewID, 10),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
@@ -0,0 +1,53 @@
+package marketplace
+
+import "time"
+
+// Review is a student's rating of a product they bought. Each student has at
+// most one review per product; writing again replaces it.
+type Review struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	ProductID      uint      `json:"product_id" gorm:"not null;uniqueIndex:idx_review_product_user;index:idx_review_product_status"`
+	UserID         uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_review_product_user"`
+	Rating         int       `json:"rating" gorm:"not null"`
+	Comment        string    `json:"comment" gorm:"type:text"`
+	Status         string    `json:"status" gorm:"type:enum('published','hidden');default:'published';index:idx_review_product_status"`
+	ModeratedBy    *uint     `json:"moderated_by"`
+	ModerationNote string    `json:"moderation_note,omitempty" gorm:"size:255"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (Review) TableName() string {
+	return "reviews"
+}
+
+type ReviewWithUser struct {
+	Review
+	UserName    string `json:"user_name"`
+	ProductName string `json:"product_name,omitempty"`
+}
+
+type CreateReviewRequest struct {
+	Rating  int    `json:"rating" binding:"required,min=1,max=5"`
+	Comment string `json:"comment" binding:"max=2000"`
+}
+
+type ModerateReviewRequest struct {
+	Status string `json:"status" binding:"required,oneof=published hidden"`
+	Note   string `json:"note" binding:"max=255"`
+}
+
+type ReviewListParams struct {
+	ProductID uint
+	Status    string
+	Page      int
+	Limit     int
+}
+
+type ReviewListResponse struct {
+	Reviews    []ReviewWithUser `json:"reviews"`
+	Total      int64            `json:"total"`
+	Page       int              `json:"page"`
+	Limit      int              `json:"limit"`
+	TotalPages int              `json:"total_pages"`
+}
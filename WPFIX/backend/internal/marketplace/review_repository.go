@@ -0,0 +1,97 @@
+package marketplace
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// FindReview finds a review by ID
+func (r *MarketplaceRepository) FindReview(reviewID uint) (*Review, error) {
+	var review Review
+	err := r.db.First(&review, reviewID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("review not found")
+		}
+		return nil, err
+	}
+	return &review, nil
+}
+
+// FindReviewByUser finds a student's review of a product
+func (r *MarketplaceRepository) FindReviewByUser(productID, userID uint) (*Review, error) {
+	var review Review
+	err := r.db.Where("product_id = ? AND user_id = ?", productID, userID).First(&review).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("review not found")
+		}
+		return nil, err
+	}
+	return &review, nil
+}
+
+// SaveReviewWithTx creates or updates a review
+func (r *MarketplaceRepository) SaveReviewWithTx(tx *gorm.DB, review *Review) error {
+	return tx.Save(review).Error
+}
+
+// UpdateReviewWithTx updates a review inside a transaction
+func (r *MarketplaceRepository) UpdateReviewWithTx(tx *gorm.DB, reviewID uint, updates map[string]interface{}) error {
+	return tx.Model(&Review{}).Where("id = ?", reviewID).Updates(updates).Error
+}
+
+// DeleteReviewWithTx deletes a review inside a transaction
+func (r *MarketplaceRepository) DeleteReviewWithTx(tx *gorm.DB, reviewID uint) error {
+	return tx.Delete(&Review{}, reviewID).Error
+}
+
+// RefreshRating recomputes a product's aggregate rating from its published reviews
+func (r *MarketplaceRepository) RefreshRating(tx *gorm.DB, productID uint) error {
+	if tx == nil {
+		tx = r.db
+	}
+	var aggregate struct {
+		Average float64
+		Count   int
+	}
+	err := tx.Model(&Review{}).
+		Select("COALESCE(AVG(rating), 0) AS average, COUNT(*) AS count").
+		Where("product_id = ? AND status = ?", productID, "published").
+		Scan(&aggregate).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(&Product{}).Where("id = ?", productID).Updates(map[string]interface{}{
+		"rating_average": aggregate.Average,
+		"rating_count":   aggregate.Count,
+	}).Error
+}
+
+// GetReviews gets reviews with the reviewer's name, newest first
+func (r *MarketplaceRepository) GetReviews(params ReviewListParams) ([]ReviewWithUser, int64, error) {
+	var reviews []ReviewWithUser
+	var total int64
+
+	query := r.db.Table("reviews r").
+		Select("r.*, u.full_name as user_name, p.name as product_name").
+		Joins("LEFT JOIN users u ON r.user_id = u.id").
+		Joins("LEFT JOIN products p ON r.product_id = p.id")
+
+	if params.ProductID != 0 {
+		query = query.Where("r.product_id = ?", params.ProductID)
+	}
+	if params.Status != "" {
+		query = query.Where("r.status = ?", params.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Order("r.created_at DESC").Limit(params.Limit).Offset(offset).Scan(&reviews).Error
+	return reviews, total, err
+}
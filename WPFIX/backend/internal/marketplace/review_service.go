@@ -0,0 +1,130 @@
+package marketplace
+
+import (
+	"errors"
+	"math"
+
+	"gorm.io/gorm"
+)
+
+// GetReviews lists reviews; students only ever get published ones
+func (s *MarketplaceService) GetReviews(params ReviewListParams) (*ReviewListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+
+	reviews, total, err := s.repo.GetReviews(params)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(params.Limit)))
+
+	return &ReviewListResponse{
+		Reviews:    reviews,
+		Total:      total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// SubmitReview writes a student's review of a product. Only students with a
+// successful, non-cancelled purchase of the product may review it. A second
+// review replaces the first but keeps its moderation, so a review an admin hid
+// stays hidden.
+func (s *MarketplaceService) SubmitReview(userID, productID uint, req *CreateReviewRequest) (*Review, error) {
+	if _, err := s.repo.FindByID(productID); err != nil {
+		return nil, err
+	}
+
+	studentWallet, err := s.walletService.GetWalletByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	bought, err := s.repo.SumPurchasedQuantity(nil, studentWallet.ID, productID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if bought == 0 {
+		return nil, errors.New("only students who bought this product can review it")
+	}
+
+	review, err := s.repo.FindReviewByUser(productID, userID)
+	if err != nil {
+		review = &Review{ProductID: productID, UserID: userID, Status: "published"}
+	}
+	review.Rating = req.Rating
+	review.Comment = req.Comment
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.repo.SaveReviewWithTx(tx, review); err != nil {
+			return err
+		}
+		return s.repo.RefreshRating(tx, productID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return review, nil
+}
+
+// DeleteMyReview removes a student's own review
+func (s *MarketplaceService) DeleteMyReview(userID, reviewID uint) error {
+	review, err := s.repo.FindReview(reviewID)
+	if err != nil {
+		return err
+	}
+	if review.UserID != userID {
+		return errors.New("review not found")
+	}
+
+	return s.deleteReview(review)
+}
+
+// ModerateReview publishes or hides a review (Admin)
+func (s *MarketplaceService) ModerateReview(reviewID, adminID uint, req *ModerateReviewRequest) (*Review, error) {
+	review, err := s.repo.FindReview(reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.repo.UpdateReviewWithTx(tx, review.ID, map[string]interface{}{
+			"status":          req.Status,
+			"moderated_by":    adminID,
+			"moderation_note": req.Note,
+		}); err != nil {
+			return err
+		}
+		return s.repo.RefreshRating(tx, review.ProductID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindReview(review.ID)
+}
+
+// DeleteReview removes any review (Admin)
+func (s *MarketplaceService) DeleteReview(reviewID uint) error {
+	review, err := s.repo.FindReview(reviewID)
+	if err != nil {
+		return err
+	}
+
+	return s.deleteReview(review)
+}
+
+func (s *MarketplaceService) deleteReview(review *Review) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.repo.DeleteReviewWithTx(tx, review.ID); err != nil {
+			return err
+		}
+		return s.repo.RefreshRating(tx, review.ProductID)
+	})
+}
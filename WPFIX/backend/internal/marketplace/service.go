@@ -3,23 +3,36 @@ package marketplace
 import (
 	"errors"
 	"fmt"
+	"io"
 	"math"
+	"wallet-point/internal/audit"
+	"wallet-point/internal/fraud"
+	"wallet-point/internal/media"
+	"wallet-point/internal/notification"
 	"wallet-point/internal/wallet"
 
 	"gorm.io/gorm"
 )
 
 type MarketplaceService struct {
-	repo          *MarketplaceRepository
-	walletService *wallet.WalletService
-	db            *gorm.DB
+	repo                *MarketplaceRepository
+	walletService       *wallet.WalletService
+	fraudService        *fraud.Service
+	notificationService *notification.Service
+	imageService        *media.ImageService
+	auditService        *audit.AuditService
+	db                  *gorm.DB
 }
 
-func NewMarketplaceService(repo *MarketplaceRepository, walletService *wallet.WalletService, db *gorm.DB) *MarketplaceService {
+func NewMarketplaceService(repo *MarketplaceRepository, walletService *wallet.WalletService, fraudService *fraud.Service, notificationService *notification.Service, imageService *media.ImageService, auditService *audit.AuditService, db *gorm.DB) *MarketplaceService {
 	return &MarketplaceService{
-		repo:          repo,
-		walletService: walletService,
-		db:            db,
+		repo:                repo,
+		walletService:       walletService,
+		fraudService:        fraudService,
+		notificationService: notificationService,
+		imageService:        imageService,
+		auditService:        auditService,
+		db:                  db,
 	}
 }
 
@@ -63,24 +76,56 @@ func (s *MarketplaceService) CreateProduct(req *CreateProductRequest, adminID ui
 		Stock:       req.Stock,
 		ImageURL:    req.ImageURL,
 		Status:      "active",
+		Type:        "physical",
+		CategoryID:  req.CategoryID,
 		CreatedBy:   adminID,
 	}
+	// Digital stock only grows as codes are uploaded
+	if req.Type == "digital" {
+		product.Type = "digital"
+		product.Stock = 0
+	}
+	// Auction products are only sold through auctions
+	if req.Type == "auction" {
+		product.Type = "auction"
+	}
+	if req.Eligibility != nil {
+		product.Eligibility = toEligibility(req.Eligibility)
+	}
+
+	if req.CategoryID != nil {
+		if _, err := s.repo.FindCategoryByID(*req.CategoryID); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := s.repo.Create(product); err != nil {
 		return nil, errors.New("failed to create product")
 	}
 
-	return product, nil
+	if len(req.Tags) > 0 {
+		if err := s.applyTags(product.ID, req.Tags); err != nil {
+			return nil, errors.New("failed to tag product")
+		}
+	}
+
+	return s.repo.FindByID(product.ID)
 }
 
 // UpdateProduct updates product
 func (s *MarketplaceService) UpdateProduct(productID uint, req *UpdateProductRequest) (*Product, error) {
 	// Check if product exists
-	_, err := s.repo.FindByID(productID)
+	product, err := s.repo.FindByID(productID)
 	if err != nil {
 		return nil, err
 	}
 
+	if req.CategoryID != nil {
+		if _, err := s.repo.FindCategoryByID(*req.CategoryID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Prepare updates
 	updates := make(map[string]interface{})
 
@@ -93,7 +138,16 @@ func (s *MarketplaceService) UpdateProduct(productID uint, req *UpdateProductReq
 	if req.Price > 0 {
 		updates["price"] = req.Price
 	}
-	if req.Stock >= 0 {
+	// Stock of a digital product is its code pool
+	if product.isDigital() && req.Stock > 0 {
+		return nil, errors.New("stock of a digital product follows its codes; upload codes instead")
+	}
+	// Stock of a product with variants is derived from the variants
+	hasVariants, err := s.repo.HasActiveVariants(nil, product.ID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Stock >= 0 && !hasVariants && !product.isDigital() {
 		updates["stock"] = req.Stock
 	}
 	if req.ImageURL != "" {
@@ -102,6 +156,18 @@ func (s *MarketplaceService) UpdateProduct(productID uint, req *UpdateProductReq
 	if req.Status != "" {
 		updates["status"] = req.Status
 	}
+	if req.CategoryID != nil {
+		updates["category_id"] = *req.CategoryID
+	}
+	if req.Eligibility != nil {
+		rules := toEligibility(req.Eligibility)
+		updates["max_per_user"] = rules.MaxPerUser
+		updates["period_limit"] = rules.PeriodLimit
+		updates["period_days"] = rules.PeriodDays
+		updates["allowed_roles"] = rules.AllowedRoles
+		updates["allowed_majors"] = rules.AllowedMajors
+		updates["allowed_batches"] = rules.AllowedBatches
+	}
 
 	// Update product
 	if len(updates) > 0 {
@@ -110,130 +176,227 @@ func (s *MarketplaceService) UpdateProduct(productID uint, req *UpdateProductReq
 		}
 	}
 
-	// Return updated product
-	return s.repo.FindByID(productID)
-}
+	if req.Tags != nil {
+		if err := s.applyTags(productID, req.Tags); err != nil {
+			return nil, errors.New("failed to tag product")
+		}
+	}
 
-// DeleteProduct deletes product
-func (s *MarketplaceService) DeleteProduct(productID uint) error {
-	// Check if product exists
-	_, err := s.repo.FindByID(productID)
+	// Return updated product, telling wishlist subscribers about restocks and price drops
+	updated, err := s.repo.FindByID(productID)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	s.notifyWatchers(product, updated)
 
-	return s.repo.Delete(productID)
+	return updated, nil
 }
 
-// PurchaseProduct handles product purchase
-func (s *MarketplaceService) PurchaseProduct(userID uint, req *PurchaseRequest) (*MarketplaceTransaction, error) {
-	// Start transaction
-	tx := s.db.Begin()
-	var err error
-
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		} else if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
-		}
-	}()
-
-	// 1. Get Product
-	var product *Product
-	product, err = s.repo.FindByID(req.ProductID)
+// UploadProductImage validates and resizes an uploaded image and makes it the
+// product's image, removing the files of the image it replaces
+func (s *MarketplaceService) UploadProductImage(productID uint, r io.Reader) (*Product, error) {
+	product, err := s.repo.FindByID(productID)
 	if err != nil {
 		return nil, err
 	}
 
-	if product.Status == "inactive" {
-		err = errors.New("product is not active")
+	images, err := s.imageService.ProcessImage(r, fmt.Sprintf("products/%d", product.ID))
+	if err != nil {
 		return nil, err
 	}
 
-	if product.Stock < 1 {
-		err = errors.New("product out of stock")
-		return nil, err
+	if err := s.repo.Update(product.ID, map[string]interface{}{
+		"images":    *images,
+		"image_url": images.Medium,
+	}); err != nil {
+		s.imageService.DeleteImage(images)
+		return nil, errors.New("failed to update product image")
 	}
+	s.imageService.DeleteImage(&product.Images)
 
-	// 2. Validate QR if needed (Legacy / External QR Token)
-	if req.PaymentMethod == "qr" && req.PaymentToken != "" {
-		if err = s.walletService.ValidateAndConsumeToken(req.PaymentToken, userID, product.Price); err != nil {
-			return nil, err
-		}
-	}
+	return s.repo.FindByID(product.ID)
+}
 
-	// 3. Get Wallets
-	var studentWallet *wallet.Wallet
-	studentWallet, err = s.walletService.GetWalletByUserID(userID)
+// DeleteProduct deletes product
+func (s *MarketplaceService) DeleteProduct(productID uint) error {
+	// Check if product exists
+	_, err := s.repo.FindByID(productID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var creatorWallet *wallet.Wallet
-	creatorWallet, err = s.walletService.GetWalletByUserID(product.CreatedBy)
-	if err != nil {
-		// Fallback to a system admin if creator wallet not found
-		err = s.db.Table("users").Where("role = ?", "admin").Select("id").First(&product.CreatedBy).Error
-		if err == nil {
-			creatorWallet, _ = s.walletService.GetWalletByUserID(product.CreatedBy)
+	return s.repo.Delete(productID)
+}
+
+// PurchaseProduct handles product purchase. Stock is taken with a guarded
+// update inside the same transaction as the payment, so concurrent buyers can
+// never push it below zero; a purchase made against a reservation uses the
+// stock already held for it.
+func (s *MarketplaceService) PurchaseProduct(userID uint, req *PurchaseRequest) (*MarketplaceTransaction, error) {
+	var txn *MarketplaceTransaction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the wallet so the balance checked is the one debited
+		studentWallet, err := s.walletService.LockWalletByUserIDWithTx(tx, userID)
+		if err != nil {
+			return err
 		}
-	}
 
-	// Default quantity to 1 if not provided
-	quantity := req.Quantity
-	if quantity <= 0 {
-		quantity = 1
-	}
+		// 1. Lock the product and give back stock held by its reservations that ran out
+		product, err := s.repo.FindByIDWithTx(tx, req.ProductID)
+		if err != nil {
+			return err
+		}
+		if product.Status == "inactive" {
+			return errors.New("product is not active")
+		}
+		if product.isAuction() {
+			return errors.New("auction products can only be won by bidding")
+		}
+		if err := s.releaseExpiredReservations(tx, product); err != nil {
+			return err
+		}
 
-	totalPrice := product.Price * quantity
+		// Products with variants are priced and stocked per variant
+		variant, err := s.resolveVariant(tx, product, req.VariantID)
+		if err != nil {
+			return err
+		}
 
-	// 4. Check Balance (Only if not already paid via external QR token)
-	if req.PaymentToken == "" {
-		if studentWallet.Balance < totalPrice {
-			err = fmt.Errorf("insufficient balance. Required: %d", totalPrice)
-			return nil, err
+		// Default quantity to 1 if not provided
+		quantity := req.Quantity
+		if quantity <= 0 {
+			quantity = 1
 		}
 
-		// 5. Debit Student Wallet
-		err = s.walletService.DebitWithTransaction(tx, studentWallet.ID, totalPrice, "marketplace", fmt.Sprintf("Buy %dx %s", quantity, product.Name))
+		// 2. Take the stock, either from a reservation or with a guarded decrement,
+		// once the buyer's eligibility rules have been checked
+		var reservation *StockReservation
+		if req.ReservationID != nil {
+			reservation, err = s.repo.LockReservationWithTx(tx, *req.ReservationID)
+			if err != nil {
+				return err
+			}
+			if err := checkReservation(reservation, userID, product, variant); err != nil {
+				return err
+			}
+			quantity = reservation.Quantity
+		}
+		if err := s.checkEligibility(tx, userID, studentWallet.ID, product, quantity); err != nil {
+			return err
+		}
+		if reservation == nil {
+			if err := s.takeStock(tx, product, variant, quantity); err != nil {
+				return err
+			}
+		}
+
+		// 3. Apply promotions and coupon, claiming their limits
+		line, err := s.priceLine(tx, userID, product, variant, quantity, req.CouponCode)
 		if err != nil {
-			return nil, err
+			return err
 		}
+		totalPrice := line.TotalAmount
+
+		// Without an external QR token the purchase is always settled from the wallet
+		paidByToken := req.PaymentMethod == "qr" && req.PaymentToken != ""
+		paymentMethod := req.PaymentMethod
+		if !paidByToken {
+			paymentMethod = "wallet"
+		}
+
+		// 4. Pay (only if not already paid via external QR token)
+		var event fraud.Event
+		var assessment *fraud.Assessment
+		if !paidByToken {
+			if studentWallet.Balance < totalPrice {
+				return fmt.Errorf("insufficient balance. Required: %d", totalPrice)
+			}
+
+			// Revenue goes to the product owner's wallet
+			creatorWallet, err := s.sellerWallet(product)
+			if err != nil {
+				return err
+			}
 
-		// 6. Credit Creator Wallet (Admin/Merchant)
-		if creatorWallet != nil {
-			err = s.walletService.CreditWithTransaction(tx, creatorWallet.ID, totalPrice, "marketplace_sale", fmt.Sprintf("Sale %dx %s to %s", quantity, product.Name, req.StudentName))
+			// Screen the wallet debit for suspicious patterns
+			event = fraud.Event{
+				Type:             fraud.EventPayment,
+				Channel:          "marketplace",
+				SenderWalletID:   studentWallet.ID,
+				ReceiverWalletID: creatorWallet.ID,
+				Amount:           totalPrice,
+			}
+			assessment, err = s.fraudService.Screen(event)
 			if err != nil {
-				return nil, err
+				return err
+			}
+
+			// Debit Student Wallet
+			if err := s.walletService.DebitWithTransaction(tx, studentWallet.ID, totalPrice, "marketplace", fmt.Sprintf("Buy %dx %s", quantity, product.Name)); err != nil {
+				return err
+			}
+
+			// Credit Creator Wallet (Admin/Merchant)
+			if err := s.walletService.CreditWithTransaction(tx, creatorWallet.ID, totalPrice, "marketplace_sale", fmt.Sprintf("Sale %dx %s to %s", quantity, product.Name, req.StudentName)); err != nil {
+				return err
 			}
 		}
-	}
 
-	// 7. Reduce Stock
-	err = s.repo.UpdateStock(tx, product.ID, -quantity)
-	if err != nil {
-		return nil, err
-	}
+		// 5. Create Transaction Record with Student Data
+		txn = &MarketplaceTransaction{
+			WalletID:      studentWallet.ID,
+			ProductID:     product.ID,
+			Quantity:      quantity,
+			StudentName:   req.StudentName,
+			StudentNPM:    req.StudentNPM,
+			StudentMajor:  req.StudentMajor,
+			StudentBatch:  req.StudentBatch,
+			PaymentMethod: paymentMethod,
+			Status:        "success",
+
+			FulfillmentStatus: "paid",
+			PickupCode:        newPickupCode(),
+		}
+		if variant != nil {
+			txn.VariantID = &variant.ID
+			txn.VariantName = variant.Name
+		}
+		line.applyTo(txn)
 
-	// 8. Create Transaction Record with Student Data
-	txn := &MarketplaceTransaction{
-		WalletID:      studentWallet.ID,
-		ProductID:     product.ID,
-		Amount:        product.Price,
-		TotalAmount:   totalPrice,
-		Quantity:      quantity,
-		StudentName:   req.StudentName,
-		StudentNPM:    req.StudentNPM,
-		StudentMajor:  req.StudentMajor,
-		StudentBatch:  req.StudentBatch,
-		PaymentMethod: req.PaymentMethod,
-		Status:        "success",
-	}
+		if err := s.repo.CreateTransaction(tx, txn); err != nil {
+			return err
+		}
+		if err := s.deliverDigitalCodes(tx, product, txn); err != nil {
+			return err
+		}
+		if err := s.recordRedemption(tx, line, userID, txn); err != nil {
+			return err
+		}
+
+		if reservation != nil {
+			if err := s.repo.UpdateReservationWithTx(tx, reservation.ID, map[string]interface{}{
+				"status":         "consumed",
+				"transaction_id": txn.ID,
+			}); err != nil {
+				return err
+			}
+		}
+
+		// 6. Queue flagged purchases for review
+		if err := s.fraudService.RecordWithTx(tx, event, assessment, &txn.ID); err != nil {
+			return err
+		}
+
+		// 7. Consume the external QR token last, once everything else has succeeded
+		if paidByToken {
+			if err := s.walletService.ValidateAndConsumeToken(req.PaymentToken, userID, totalPrice); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 
-	err = s.repo.CreateTransaction(tx, txn)
 	if err != nil {
 		return nil, err
 	}
@@ -241,7 +404,8 @@ func (s *MarketplaceService) PurchaseProduct(userID uint, req *PurchaseRequest)
 	return txn, nil
 }
 
-// GetTransactions retrieves all marketplace transactions (Admin)
-func (s *MarketplaceService) GetTransactions(limit, offset int) ([]MarketplaceTransactionWithDetails, int64, error) {
-	return s.repo.GetAllTransactions(limit, offset)
+// GetTransactions retrieves marketplace transactions; a non-zero sellerID
+// limits them to sales of that seller's products (Admin/Merchant)
+func (s *MarketplaceService) GetTransactions(sellerID uint, limit, offset int) ([]MarketplaceTransactionWithDetails, int64, error) {
+	return s.repo.GetAllTransactions(sellerID, limit, offset)
 }
@@ -0,0 +1,164 @@
+package marketplace_test
+
+import (
+	"sync"
+	"testing"
+	"wallet-point/internal/audit"
+	"wallet-point/internal/database"
+	"wallet-point/internal/fraud"
+	"wallet-point/internal/marketplace"
+	"wallet-point/internal/notification"
+	"wallet-point/internal/wallet"
+
+	"gorm.io/gorm"
+)
+
+func newService(db *gorm.DB) *marketplace.MarketplaceService {
+	fraudService := fraud.NewService(fraud.NewRepository(db), fraud.NewDetector(db), fraud.Policy{})
+	walletService := wallet.NewWalletService(wallet.NewWalletRepository(db), fraudService, db)
+	notificationService := notification.NewService(notification.NewRepository(db))
+	auditService := audit.NewAuditService(audit.NewAuditRepository(db))
+	return marketplace.NewMarketplaceService(marketplace.NewMarketplaceRepository(db), walletService, fraudService, notificationService, nil, auditService, db)
+}
+
+func createProduct(t *testing.T, db *gorm.DB, sellerID uint, price, stock int) *marketplace.Product {
+	t.Helper()
+	product := &marketplace.Product{
+		Name:      "Test product",
+		Price:     price,
+		Stock:     stock,
+		Type:      "physical",
+		Status:    "active",
+		CreatedBy: sellerID,
+	}
+	if err := db.Create(product).Error; err != nil {
+		t.Fatalf("failed to create product: %v", err)
+	}
+	return product
+}
+
+// purchaseConcurrently runs one purchase per request at the same time and
+// returns how many went through
+func purchaseConcurrently(t *testing.T, service *marketplace.MarketplaceService, buyers []uint, req marketplace.PurchaseRequest) int {
+	t.Helper()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	sold := 0
+	start := make(chan struct{})
+	for _, buyerID := range buyers {
+		wg.Add(1)
+		go func(buyerID uint) {
+			defer wg.Done()
+			<-start
+			r := req
+			if _, err := service.PurchaseProduct(buyerID, &r); err != nil {
+				t.Logf("purchase by user %d failed: %v", buyerID, err)
+				return
+			}
+			mu.Lock()
+			sold++
+			mu.Unlock()
+		}(buyerID)
+	}
+	close(start)
+	wg.Wait()
+
+	return sold
+}
+
+func stockOf(t *testing.T, db *gorm.DB, model interface{}, id uint) int {
+	t.Helper()
+	var stock int
+	if err := db.Model(model).Where("id = ?", id).Pluck("stock", &stock).Error; err != nil {
+		t.Fatalf("failed to load stock: %v", err)
+	}
+	return stock
+}
+
+func TestPurchaseProductConcurrently(t *testing.T) {
+	db := database.OpenTestDB(t)
+	service := newService(db)
+
+	const stock, buyerCount = 5, 20
+	seller, _ := database.CreateTestUser(t, db, "merchant", 0)
+	product := createProduct(t, db, seller.ID, 10, stock)
+
+	buyers := make([]uint, buyerCount)
+	for i := range buyers {
+		buyer, _ := database.CreateTestUser(t, db, "mahasiswa", 100)
+		buyers[i] = buyer.ID
+	}
+
+	sold := purchaseConcurrently(t, service, buyers, marketplace.PurchaseRequest{ProductID: product.ID, Quantity: 1})
+
+	if sold > stock {
+		t.Errorf("sold %d units of a product with %d in stock", sold, stock)
+	}
+	left := stockOf(t, db, &marketplace.Product{}, product.ID)
+	if left < 0 || left != stock-sold {
+		t.Errorf("stock is %d after selling %d of %d", left, sold, stock)
+	}
+
+	var recorded int64
+	db.Model(&marketplace.MarketplaceTransaction{}).Where("product_id = ?", product.ID).Count(&recorded)
+	if int(recorded) != sold {
+		t.Errorf("recorded %d transactions for %d sales", recorded, sold)
+	}
+}
+
+func TestPurchaseVariantConcurrently(t *testing.T) {
+	db := database.OpenTestDB(t)
+	service := newService(db)
+
+	const productStock, variantStock, buyerCount = 10, 3, 20
+	seller, _ := database.CreateTestUser(t, db, "merchant", 0)
+	product := createProduct(t, db, seller.ID, 10, productStock)
+	variant := &marketplace.ProductVariant{ProductID: product.ID, Name: "Large", Price: 12, Stock: variantStock, Status: "active"}
+	if err := db.Create(variant).Error; err != nil {
+		t.Fatalf("failed to create variant: %v", err)
+	}
+
+	buyers := make([]uint, buyerCount)
+	for i := range buyers {
+		buyer, _ := database.CreateTestUser(t, db, "mahasiswa", 100)
+		buyers[i] = buyer.ID
+	}
+
+	sold := purchaseConcurrently(t, service, buyers, marketplace.PurchaseRequest{ProductID: product.ID, VariantID: &variant.ID, Quantity: 1})
+
+	if sold > variantStock {
+		t.Errorf("sold %d units of a variant with %d in stock", sold, variantStock)
+	}
+	if left := stockOf(t, db, &marketplace.ProductVariant{}, variant.ID); left < 0 || left != variantStock-sold {
+		t.Errorf("variant stock is %d after selling %d of %d", left, sold, variantStock)
+	}
+	if left := stockOf(t, db, &marketplace.Product{}, product.ID); left < 0 || left != productStock-sold {
+		t.Errorf("product stock is %d after selling %d of %d", left, sold, productStock)
+	}
+}
+
+func TestPurchaseConcurrentlyFromOneWallet(t *testing.T) {
+	db := database.OpenTestDB(t)
+	service := newService(db)
+
+	seller, _ := database.CreateTestUser(t, db, "merchant", 0)
+	product := createProduct(t, db, seller.ID, 10, 20)
+	buyer, buyerWallet := database.CreateTestUser(t, db, "mahasiswa", 30)
+
+	buyers := make([]uint, 10)
+	for i := range buyers {
+		buyers[i] = buyer.ID
+	}
+
+	sold := purchaseConcurrently(t, service, buyers, marketplace.PurchaseRequest{ProductID: product.ID, Quantity: 1})
+
+	if sold > 3 {
+		t.Errorf("a wallet of 30 points paid for %d purchases of 10", sold)
+	}
+	var balance int
+	db.Model(&wallet.Wallet{}).Where("id = ?", buyerWallet.ID).Pluck("balance", &balance)
+	if balance < 0 || balance != 30-10*sold {
+		t.Errorf("balance is %d after %d purchases of 10 from 30", balance, sold)
+	}
+}
@@ -0,0 +1,85 @@
+package marketplace
+
+import (
+	"net/http"
+	"strconv"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetWishlist handles getting the student's wishlist
+// @Summary Get wishlist
+// @Tags Marketplace - Wishlist
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]WishlistItem}
+// @Router /mahasiswa/wishlist [get]
+func (h *MarketplaceHandler) GetWishlist(c *gin.Context) {
+	items, err := h.service.GetWishlist(c.GetUint("user_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve wishlist", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Wishlist retrieved successfully", items)
+}
+
+// AddToWishlist handles adding a product to the wishlist
+// @Summary Add to wishlist
+// @Description Watch a product: get notified when it is back in stock and, with price_threshold, when its price drops to that value or lower
+// @Tags Marketplace - Wishlist
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body AddWishlistItemRequest true "Product and subscription"
+// @Success 201 {object} utils.Response{data=WishlistItem}
+// @Failure 404 {object} utils.Response
+// @Router /mahasiswa/wishlist [post]
+func (h *MarketplaceHandler) AddToWishlist(c *gin.Context) {
+	var req AddWishlistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	item, err := h.service.AddToWishlist(c.GetUint("user_id"), &req)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "product not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Product added to wishlist", item)
+}
+
+// RemoveFromWishlist handles removing a product from the wishlist
+// @Summary Remove from wishlist
+// @Tags Marketplace - Wishlist
+// @Security BearerAuth
+// @Produce json
+// @Param productId path int true "Product ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /mahasiswa/wishlist/{productId} [delete]
+func (h *MarketplaceHandler) RemoveFromWishlist(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("productId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", nil)
+		return
+	}
+
+	if err := h.service.RemoveFromWishlist(c.GetUint("user_id"), uint(productID)); err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "wishlist item not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Product removed from wishlist", nil)
+}
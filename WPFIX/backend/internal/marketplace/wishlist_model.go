@@ -0,0 +1,28 @@
+package marketplace
+
+import "time"
+
+// WishlistItem is a product a student is watching. Besides keeping the
+// product at hand, it subscribes the student to back-in-stock and price-drop
+// notifications.
+type WishlistItem struct {
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	UserID            uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_wishlist_user_product"`
+	ProductID         uint      `json:"product_id" gorm:"not null;uniqueIndex:idx_wishlist_user_product;index"`
+	NotifyBackInStock bool      `json:"notify_back_in_stock" gorm:"not null"`
+	PriceThreshold    *int      `json:"price_threshold"` // Notify once the price drops to this or lower
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+
+	Product *Product `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+}
+
+func (WishlistItem) TableName() string {
+	return "wishlist_items"
+}
+
+type AddWishlistItemRequest struct {
+	ProductID         uint  `json:"product_id" binding:"required"`
+	NotifyBackInStock *bool `json:"notify_back_in_stock"` // Defaults to true
+	PriceThreshold    *int  `json:"price_threshold" binding:"omitempty,gt=0"`
+}
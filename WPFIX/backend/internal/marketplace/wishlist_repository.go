@@ -0,0 +1,53 @@
+package marketplace
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// GetWishlist gets a student's wishlist with the products
+func (r *MarketplaceRepository) GetWishlist(userID uint) ([]WishlistItem, error) {
+	var items []WishlistItem
+	err := r.db.Where("user_id = ?", userID).Preload("Product").Order("created_at DESC").Find(&items).Error
+	return items, err
+}
+
+// FindWishlistItem finds a student's wishlist entry for a product
+func (r *MarketplaceRepository) FindWishlistItem(userID, productID uint) (*WishlistItem, error) {
+	var item WishlistItem
+	err := r.db.Where("user_id = ? AND product_id = ?", userID, productID).First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("wishlist item not found")
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// SaveWishlistItem creates or updates a wishlist entry
+func (r *MarketplaceRepository) SaveWishlistItem(item *WishlistItem) error {
+	return r.db.Save(item).Error
+}
+
+// DeleteWishlistItem removes a product from a student's wishlist
+func (r *MarketplaceRepository) DeleteWishlistItem(userID, productID uint) error {
+	result := r.db.Where("user_id = ? AND product_id = ?", userID, productID).Delete(&WishlistItem{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("wishlist item not found")
+	}
+	return nil
+}
+
+// GetWatchers gets the wishlist entries subscribed to a product
+func (r *MarketplaceRepository) GetWatchers(productID uint) ([]WishlistItem, error) {
+	var items []WishlistItem
+	err := r.db.Where("product_id = ?", productID).
+		Where("notify_back_in_stock = ? OR price_threshold IS NOT NULL", true).
+		Find(&items).Error
+	return items, err
+}
@@ -0,0 +1,99 @@
+package marketplace
+
+import (
+	"fmt"
+	"log"
+	"wallet-point/internal/notification"
+)
+
+// GetWishlist gets a student's wishlist
+func (s *MarketplaceService) GetWishlist(userID uint) ([]WishlistItem, error) {
+	return s.repo.GetWishlist(userID)
+}
+
+// AddToWishlist adds a product to a student's wishlist, or updates the
+// subscription if it is already there
+func (s *MarketplaceService) AddToWishlist(userID uint, req *AddWishlistItemRequest) (*WishlistItem, error) {
+	product, err := s.repo.FindByID(req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.repo.FindWishlistItem(userID, req.ProductID)
+	if err != nil {
+		item = &WishlistItem{UserID: userID, ProductID: req.ProductID}
+	}
+	item.NotifyBackInStock = req.NotifyBackInStock == nil || *req.NotifyBackInStock
+	item.PriceThreshold = req.PriceThreshold
+
+	if err := s.repo.SaveWishlistItem(item); err != nil {
+		return nil, err
+	}
+
+	item.Product = product
+	return item, nil
+}
+
+// RemoveFromWishlist removes a product from a student's wishlist
+func (s *MarketplaceService) RemoveFromWishlist(userID, productID uint) error {
+	return s.repo.DeleteWishlistItem(userID, productID)
+}
+
+// notifyRestock reloads a product after a stock change and notifies its watchers
+func (s *MarketplaceService) notifyRestock(before *Product) {
+	after, err := s.repo.FindByID(before.ID)
+	if err != nil {
+		log.Printf("[wishlist] failed to reload product %d: %v", before.ID, err)
+		return
+	}
+	s.notifyWatchers(before, after)
+}
+
+// notifyWatchers tells wishlist subscribers about a product that came back in
+// stock or dropped to their price threshold. Only transitions notify, so
+// repeated saves of an unchanged product stay silent.
+func (s *MarketplaceService) notifyWatchers(before, after *Product) {
+	if after.Status != "active" {
+		return
+	}
+
+	backInStock := before.Stock <= 0 && after.Stock > 0
+	priceDropped := after.Price < before.Price
+	if !backInStock && !priceDropped {
+		return
+	}
+
+	watchers, err := s.repo.GetWatchers(after.ID)
+	if err != nil {
+		log.Printf("[wishlist] failed to load watchers of product %d: %v", after.ID, err)
+		return
+	}
+
+	var notifications []notification.Notification
+	for _, w := range watchers {
+		switch {
+		case backInStock && w.NotifyBackInStock:
+			notifications = append(notifications, notification.Notification{
+				UserID:        w.UserID,
+				Type:          notification.TypeBackInStock,
+				Title:         after.Name + " is back in stock",
+				Message:       fmt.Sprintf("%s is available again (%d left) for %d points.", after.Name, after.Stock, after.Price),
+				ReferenceType: "product",
+				ReferenceID:   &after.ID,
+			})
+		case priceDropped && w.PriceThreshold != nil && after.Price <= *w.PriceThreshold && before.Price > *w.PriceThreshold:
+			notifications = append(notifications, notification.Notification{
+				UserID:        w.UserID,
+				Type:          notification.TypePriceDrop,
+				Title:         after.Name + " dropped in price",
+				Message:       fmt.Sprintf("%s now costs %d points (was %d), within your limit of %d.", after.Name, after.Price, before.Price, *w.PriceThreshold),
+				ReferenceType: "product",
+				ReferenceID:   &after.ID,
+			})
+		}
+	}
+
+	if len(notifications) > 0 {
+		s.notificationService.Notify(nil, notifications...)
+	}
+}
@@ -0,0 +1,193 @@
+package media
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // registers the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+)
+
+const (
+	MaxImageSize      = 10 << 20 // 10MB
+	MinImageDimension = 100
+	MaxImageDimension = 6000
+
+	OriginalMaxSide  = 2000
+	MediumMaxSide    = 800
+	ThumbnailMaxSide = 200
+
+	jpegQuality = 85
+)
+
+// ImageSet is a product image in the sizes the frontend needs
+type ImageSet struct {
+	Original  string `json:"original"`
+	Medium    string `json:"medium"`
+	Thumbnail string `json:"thumbnail"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+}
+
+func (is ImageSet) Value() (driver.Value, error) {
+	if is.Original == "" {
+		return nil, nil
+	}
+	return json.Marshal(is)
+}
+
+func (is *ImageSet) Scan(value interface{}) error {
+	if value == nil {
+		*is = ImageSet{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, is)
+}
+
+// URLs lists the stored files of the set
+func (is ImageSet) URLs() []string {
+	var urls []string
+	for _, url := range []string{is.Original, is.Medium, is.Thumbnail} {
+		if url != "" {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// decodedImage is an upload that passed validation
+type decodedImage struct {
+	img         image.Image
+	format      string // jpeg or png, the format it is re-encoded to
+	contentType string
+}
+
+// decodeImage validates an upload by its content rather than its name: the
+// sniffed MIME type must be an image we can decode, and its dimensions are
+// checked from the header before the pixels are decoded.
+func decodeImage(r io.Reader) (*decodedImage, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxImageSize {
+		return nil, fmt.Errorf("image exceeds %dMB", MaxImageSize>>20)
+	}
+
+	var format, contentType string
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		format, contentType = "jpeg", "image/jpeg"
+	case "image/png":
+		format, contentType = "png", "image/png"
+	case "image/gif":
+		// Animated GIFs keep only their first frame
+		format, contentType = "png", "image/png"
+	default:
+		return nil, errors.New("file is not a JPEG, PNG or GIF image")
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("image could not be read")
+	}
+	if config.Width < MinImageDimension || config.Height < MinImageDimension {
+		return nil, fmt.Errorf("image must be at least %dx%d pixels", MinImageDimension, MinImageDimension)
+	}
+	if config.Width > MaxImageDimension || config.Height > MaxImageDimension {
+		return nil, fmt.Errorf("image must be at most %dx%d pixels", MaxImageDimension, MaxImageDimension)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("image could not be decoded")
+	}
+
+	return &decodedImage{img: img, format: format, contentType: contentType}, nil
+}
+
+// encode writes an image from pixels alone; EXIF, ICC and text chunks of the
+// upload are never copied, which strips its metadata
+func encode(img image.Image, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	case "png":
+		err = png.Encode(&buf, img)
+	default:
+		err = fmt.Errorf("unsupported format %s", format)
+	}
+	return buf.Bytes(), err
+}
+
+// fit scales an image down so its longest side is at most maxSide. Smaller
+// images are returned as they are.
+func fit(img image.Image, maxSide int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSide && h <= maxSide {
+		return img
+	}
+
+	if w >= h {
+		h = max(1, h*maxSide/w)
+		w = maxSide
+	} else {
+		w = max(1, w*maxSide/h)
+		h = maxSide
+	}
+	return resize(img, w, h)
+}
+
+// resize shrinks an image with a box filter: every destination pixel is the
+// average of the source pixels it covers. Colours are averaged premultiplied
+// so transparent pixels do not darken the edges.
+func resize(img image.Image, w, h int) *image.RGBA {
+	b := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for dy := 0; dy < h; dy++ {
+		y0 := dy * sh / h
+		y1 := max(y0+1, (dy+1)*sh/h)
+		for dx := 0; dx < w; dx++ {
+			x0 := dx * sw / w
+			x1 := max(x0+1, (dx+1)*sw/w)
+
+			var r, g, bl, a, n uint64
+			for y := y0; y < y1; y++ {
+				row := src.Pix[y*src.Stride:]
+				for x := x0; x < x1; x++ {
+					p := row[x*4 : x*4+4]
+					r += uint64(p[0])
+					g += uint64(p[1])
+					bl += uint64(p[2])
+					a += uint64(p[3])
+					n++
+				}
+			}
+
+			i := dy*dst.Stride + dx*4
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(bl / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,69 @@
+package media
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+type ImageService struct {
+	storage Storage
+}
+
+func NewImageService(storage Storage) *ImageService {
+	return &ImageService{storage: storage}
+}
+
+// ProcessImage validates an uploaded image and stores it re-encoded, without
+// metadata, as an original (capped at OriginalMaxSide), a medium and a
+// thumbnail size. Files are stored under prefix, e.g. "products/12".
+func (s *ImageService) ProcessImage(r io.Reader, prefix string) (*ImageSet, error) {
+	decoded, err := decodeImage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	original := fit(decoded.img, OriginalMaxSide)
+
+	base := fmt.Sprintf("%s/%d", prefix, time.Now().UnixNano())
+	ext := "." + decoded.format
+	if decoded.format == "jpeg" {
+		ext = ".jpg"
+	}
+
+	set := &ImageSet{
+		Width:  original.Bounds().Dx(),
+		Height: original.Bounds().Dy(),
+	}
+	targets := []struct {
+		url  *string
+		name string
+		max  int
+	}{
+		{&set.Original, "original", OriginalMaxSide},
+		{&set.Medium, "medium", MediumMaxSide},
+		{&set.Thumbnail, "thumb", ThumbnailMaxSide},
+	}
+	for _, t := range targets {
+		data, err := encode(fit(original, t.max), decoded.format)
+		if err != nil {
+			s.DeleteImage(set)
+			return nil, err
+		}
+		url, err := s.storage.Save(base+"_"+t.name+ext, data, decoded.contentType)
+		if err != nil {
+			s.DeleteImage(set)
+			return nil, err
+		}
+		*t.url = url
+	}
+
+	return set, nil
+}
+
+// DeleteImage removes the stored files of an image set, best effort
+func (s *ImageService) DeleteImage(set *ImageSet) {
+	for _, url := range set.URLs() {
+		_ = s.storage.Delete(url)
+	}
+}
@@ -0,0 +1,65 @@
+package media
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Storage keeps uploaded files and hands back the public URL they are served
+// from. Keys are slash-separated paths such as "products/12/thumb_x.jpg".
+type Storage interface {
+	Save(key string, data []byte, contentType string) (string, error)
+	Delete(url string) error
+}
+
+// LocalStorage stores files on the local disk below Dir, served at BaseURL
+type LocalStorage struct {
+	Dir     string
+	BaseURL string
+}
+
+func NewLocalStorage(dir, baseURL string) *LocalStorage {
+	return &LocalStorage{Dir: dir, BaseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+func (s *LocalStorage) Save(key string, data []byte, contentType string) (string, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+	return s.BaseURL + "/" + key, nil
+}
+
+// Delete removes a file previously saved by this storage; URLs it did not
+// hand out are ignored
+func (s *LocalStorage) Delete(url string) error {
+	key, ok := strings.CutPrefix(url, s.BaseURL+"/")
+	if !ok {
+		return nil
+	}
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path resolves a key below Dir, refusing keys that would escape it
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if clean == "." || filepath.IsAbs(clean) || strings.HasPrefix(clean, "..") {
+		return "", errors.New("invalid storage key")
+	}
+	return filepath.Join(s.Dir, clean), nil
+}
@@ -0,0 +1,108 @@
+package mission
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Scoring methods pick the attempt that counts when a mission allows several
+const (
+	ScoreLatest = "latest"
+	ScoreBest   = "best"
+)
+
+// usedAttempts counts the attempts held against a mission's limit. An attempt
+// a reviewer rejected asks for a resubmission, so it does not use one up; a
+// quiz attempt rejected by auto-grading does.
+func usedAttempts(attempts []MissionSubmission) int {
+	used := 0
+	for _, a := range attempts {
+		if a.Status == "rejected" && a.ReviewedBy != nil {
+			continue
+		}
+		used++
+	}
+	return used
+}
+
+// nextAttempt checks that a student may submit the mission again and returns
+// the number of the new attempt. A new attempt needs the previous one to be
+// reviewed; after approval only quizzes may be retaken, to improve the score.
+func nextAttempt(mission *Mission, attempts []MissionSubmission) (int, error) {
+	if len(attempts) == 0 {
+		return 1, nil
+	}
+
+	for _, a := range attempts {
+		if a.Status == "pending" {
+			return 0, errors.New("your previous attempt is still awaiting review")
+		}
+		if a.Status == "approved" && mission.Type != "quiz" {
+			return 0, errors.New("you have already completed this mission")
+		}
+	}
+
+	if !mission.hasAttemptsLeft(usedAttempts(attempts)) {
+		if mission.MaxAttempts == 1 {
+			return 0, errors.New("you have already submitted this mission")
+		}
+		return 0, fmt.Errorf("you have used all %d attempts for this mission", mission.MaxAttempts)
+	}
+
+	return attempts[len(attempts)-1].Attempt + 1, nil
+}
+
+// hasAttemptsLeft reports whether another attempt may follow once used
+// attempts count against the limit
+func (m *Mission) hasAttemptsLeft(used int) bool {
+	return m.MaxAttempts == 0 || used < m.MaxAttempts
+}
+
+// alreadyRewarded reports whether another attempt was approved, and so paid;
+// a mission's reward is paid at most once per student
+func alreadyRewarded(attempts []MissionSubmission, submissionID uint) bool {
+	for _, a := range attempts {
+		if a.ID != submissionID && a.Status == "approved" {
+			return true
+		}
+	}
+	return false
+}
+
+// attemptScore is the score percentage of a graded or reviewed attempt
+func attemptScore(submission *MissionSubmission) float64 {
+	if submission.AutoGraded {
+		return submission.Percentage
+	}
+	return float64(submission.Score)
+}
+
+// refreshCountedAttempt marks the attempt that counts for a student: the
+// latest one that has a result, or the one with the best score
+func (s *MissionService) refreshCountedAttempt(tx *gorm.DB, mission *Mission, studentID uint) error {
+	attempts, err := s.repo.FindAttemptsWithTx(tx, mission.ID, studentID)
+	if err != nil {
+		return err
+	}
+
+	var counted *MissionSubmission
+	for i := range attempts {
+		a := &attempts[i]
+		if a.Status == "pending" && !a.AutoGraded {
+			continue
+		}
+		switch {
+		case counted == nil,
+			mission.ScoringMethod != ScoreBest,
+			attemptScore(a) > attemptScore(counted):
+			counted = a
+		}
+	}
+	if counted == nil {
+		return nil
+	}
+
+	return s.repo.MarkCountedAttemptWithTx(tx, mission.ID, studentID, counted.ID)
+}
@@ -0,0 +1,50 @@
+package mission
+
+import "testing"
+
+func TestNextAttempt(t *testing.T) {
+	reviewer := uint(9)
+	rejected := MissionSubmission{Attempt: 1, Status: "rejected", ReviewedBy: &reviewer}
+	autoRejected := MissionSubmission{Attempt: 1, Status: "rejected", AutoGraded: true}
+
+	tests := []struct {
+		name        string
+		mission     Mission
+		attempts    []MissionSubmission
+		wantAttempt int
+		wantErr     string
+	}{
+		{"first attempt", Mission{Type: "task", MaxAttempts: 1}, nil, 1, ""},
+		{"resubmit after a rejection", Mission{Type: "task", MaxAttempts: 1}, []MissionSubmission{rejected}, 2, ""},
+		{
+			"resubmit after repeated rejections", Mission{Type: "task", MaxAttempts: 1},
+			[]MissionSubmission{rejected, {Attempt: 2, Status: "rejected", ReviewedBy: &reviewer}}, 3, "",
+		},
+		{"previous attempt pending", Mission{Type: "task", MaxAttempts: 3}, []MissionSubmission{{Attempt: 1, Status: "pending"}}, 0, "your previous attempt is still awaiting review"},
+		{"task already approved", Mission{Type: "task", MaxAttempts: 0}, []MissionSubmission{{Attempt: 1, Status: "approved"}}, 0, "you have already completed this mission"},
+		{"failed quiz uses up the only attempt", Mission{Type: "quiz", MaxAttempts: 1}, []MissionSubmission{autoRejected}, 0, "you have already submitted this mission"},
+		{"failed quiz with attempts left", Mission{Type: "quiz", MaxAttempts: 2}, []MissionSubmission{autoRejected}, 2, ""},
+		{
+			"every quiz attempt used", Mission{Type: "quiz", MaxAttempts: 2},
+			[]MissionSubmission{autoRejected, {Attempt: 2, Status: "approved", AutoGraded: true}}, 0, "you have used all 2 attempts for this mission",
+		},
+		{"approved quiz retaken", Mission{Type: "quiz", MaxAttempts: 0}, []MissionSubmission{{Attempt: 1, Status: "approved", AutoGraded: true}}, 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempt, err := nextAttempt(&tt.mission, tt.attempts)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("got attempt %d, error %v; want error %q", attempt, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if attempt != tt.wantAttempt {
+				t.Errorf("attempt = %d, want %d", attempt, tt.wantAttempt)
+			}
+		})
+	}
+}
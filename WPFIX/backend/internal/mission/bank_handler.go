@@ -0,0 +1,207 @@
+package mission
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetBankQuestions handles listing the dosen's question bank
+// @Summary Get bank questions
+// @Description List questions in the requester's question bank
+// @Tags Dosen - Question Bank
+// @Security BearerAuth
+// @Produce json
+// @Param tag query string false "Filter by tag"
+// @Param difficulty query string false "Filter by difficulty" Enums(easy, medium, hard)
+// @Param course query string false "Filter by course"
+// @Param type query string false "Filter by question type"
+// @Param search query string false "Search question text"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=BankQuestionListResponse}
+// @Router /dosen/question-bank [get]
+func (h *MissionHandler) GetBankQuestions(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	params := BankQuestionListParams{
+		OwnerID:    c.GetUint("user_id"),
+		Tag:        c.Query("tag"),
+		Difficulty: c.Query("difficulty"),
+		Course:     c.Query("course"),
+		Type:       c.Query("type"),
+		Search:     c.Query("search"),
+		Page:       page,
+		Limit:      limit,
+	}
+
+	response, err := h.service.GetBankQuestions(params)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve bank questions", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Bank questions retrieved successfully", response)
+}
+
+// GetBankQuestion handles getting one bank question
+// @Summary Get bank question
+// @Tags Dosen - Question Bank
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Bank question ID"
+// @Success 200 {object} utils.Response{data=BankQuestion}
+// @Failure 404 {object} utils.Response
+// @Router /dosen/question-bank/{id} [get]
+func (h *MissionHandler) GetBankQuestion(c *gin.Context) {
+	questionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid question ID", nil)
+		return
+	}
+
+	question, err := h.service.GetBankQuestion(uint(questionID), c.GetUint("user_id"), c.GetString("role"))
+	if err != nil {
+		h.bankError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Bank question retrieved successfully", question)
+}
+
+// CreateBankQuestion handles adding a question to the bank
+// @Summary Create bank question
+// @Description Add a question to the requester's bank, with tags, difficulty and course for pool rules to draw on
+// @Tags Dosen - Question Bank
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body BankQuestionRequest true "Question"
+// @Success 201 {object} utils.Response{data=BankQuestion}
+// @Failure 400 {object} utils.Response
+// @Router /dosen/question-bank [post]
+func (h *MissionHandler) CreateBankQuestion(c *gin.Context) {
+	dosenID := c.GetUint("user_id")
+
+	var req BankQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	question, err := h.service.CreateBankQuestion(&req, dosenID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Bank question created successfully", question)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    dosenID,
+		Action:    "CREATE_BANK_QUESTION",
+		Entity:    "BANK_QUESTION",
+		EntityID:  question.ID,
+		Details:   fmt.Sprintf("Dosen added a %s %s question to the bank", question.Difficulty, question.Type),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// UpdateBankQuestion handles editing a bank question
+// @Summary Update bank question
+// @Description Replace a bank question. Attempts that already drew it keep the copy they were given.
+// @Tags Dosen - Question Bank
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Bank question ID"
+// @Param request body BankQuestionRequest true "Question"
+// @Success 200 {object} utils.Response{data=BankQuestion}
+// @Failure 400 {object} utils.Response
+// @Router /dosen/question-bank/{id} [put]
+func (h *MissionHandler) UpdateBankQuestion(c *gin.Context) {
+	dosenID := c.GetUint("user_id")
+	questionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid question ID", nil)
+		return
+	}
+
+	var req BankQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	question, err := h.service.UpdateBankQuestion(uint(questionID), &req, dosenID, c.GetString("role"))
+	if err != nil {
+		h.bankError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Bank question updated successfully", question)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    dosenID,
+		Action:    "UPDATE_BANK_QUESTION",
+		Entity:    "BANK_QUESTION",
+		EntityID:  question.ID,
+		Details:   "Dosen updated bank question ID: " + strconv.FormatUint(questionID, 10),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// DeleteBankQuestion handles removing a bank question
+// @Summary Delete bank question
+// @Tags Dosen - Question Bank
+// @Security BearerAuth
+// @Param id path int true "Bank question ID"
+// @Success 200 {object} utils.Response
+// @Router /dosen/question-bank/{id} [delete]
+func (h *MissionHandler) DeleteBankQuestion(c *gin.Context) {
+	dosenID := c.GetUint("user_id")
+	questionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid question ID", nil)
+		return
+	}
+
+	if err := h.service.DeleteBankQuestion(uint(questionID), dosenID, c.GetString("role")); err != nil {
+		h.bankError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Bank question deleted successfully", nil)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    dosenID,
+		Action:    "DELETE_BANK_QUESTION",
+		Entity:    "BANK_QUESTION",
+		EntityID:  uint(questionID),
+		Details:   "Dosen deleted bank question ID: " + strconv.FormatUint(questionID, 10),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// bankError maps question bank errors to HTTP status codes
+func (h *MissionHandler) bankError(c *gin.Context, err error) {
+	statusCode := http.StatusBadRequest
+	switch err.Error() {
+	case "bank question not found":
+		statusCode = http.StatusNotFound
+	case "you do not own this question":
+		statusCode = http.StatusForbidden
+	}
+	utils.ErrorResponse(c, statusCode, err.Error(), nil)
+}
@@ -0,0 +1,86 @@
+package mission
+
+import "time"
+
+// Question difficulties
+const (
+	DifficultyEasy   = "easy"
+	DifficultyMedium = "medium"
+	DifficultyHard   = "hard"
+)
+
+// BankQuestion is a question in a dosen's question bank. Missions draw from
+// the bank through pool rules; each drawn question is copied into the attempt,
+// so later edits to the bank leave past attempts alone.
+type BankQuestion struct {
+	ID         uint        `json:"id" gorm:"primaryKey"`
+	OwnerID    uint        `json:"owner_id" gorm:"not null;index"`
+	Course     string      `json:"course" gorm:"size:100;index"`
+	Tags       JSONOptions `json:"tags" gorm:"type:json"`
+	Difficulty string      `json:"difficulty" gorm:"type:enum('easy','medium','hard');default:'medium';not null"`
+
+	// Same fields as MissionQuestion
+	Type           string      `json:"type" gorm:"type:enum('single_choice','multi_select','numeric','short_text','ordering','matching');default:'single_choice';not null"`
+	Question       string      `json:"question" gorm:"type:text;not null"`
+	Options        JSONOptions `json:"options" gorm:"type:json"`
+	Answer         string      `json:"answer" gorm:"not null"`
+	Points         int         `json:"points" gorm:"default:1;not null"`
+	CorrectAnswers JSONOptions `json:"correct_answers,omitempty" gorm:"type:json"`
+	Tolerance      float64     `json:"tolerance" gorm:"default:0"`
+	MatchMode      string      `json:"match_mode,omitempty" gorm:"size:20"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (BankQuestion) TableName() string {
+	return "mission_bank_questions"
+}
+
+// PoolRule draws Count random questions from the mission creator's bank,
+// matching every filter that is set
+type PoolRule struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	MissionID  uint   `json:"mission_id" gorm:"not null;index"`
+	Count      int    `json:"count" gorm:"not null"`
+	Tag        string `json:"tag" gorm:"size:50"`
+	Difficulty string `json:"difficulty" gorm:"size:10"`
+	Course     string `json:"course" gorm:"size:100"`
+}
+
+func (PoolRule) TableName() string {
+	return "mission_pool_rules"
+}
+
+type PoolRuleRequest struct {
+	Count      int    `json:"count" binding:"required,gt=0"`
+	Tag        string `json:"tag"`
+	Difficulty string `json:"difficulty" binding:"omitempty,oneof=easy medium hard"`
+	Course     string `json:"course"`
+}
+
+type BankQuestionRequest struct {
+	QuestionRequest
+	Course     string   `json:"course"`
+	Tags       []string `json:"tags"`
+	Difficulty string   `json:"difficulty" binding:"omitempty,oneof=easy medium hard"` // Defaults to medium
+}
+
+type BankQuestionListParams struct {
+	OwnerID    uint
+	Tag        string
+	Difficulty string
+	Course     string
+	Type       string
+	Search     string
+	Page       int
+	Limit      int
+}
+
+type BankQuestionListResponse struct {
+	Questions  []BankQuestion `json:"questions"`
+	Total      int64          `json:"total"`
+	Page       int            `json:"page"`
+	Limit      int            `json:"limit"`
+	TotalPages int            `json:"total_pages"`
+}
@@ -0,0 +1,96 @@
+package mission
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// Question bank
+func (r *MissionRepository) CreateBankQuestion(question *BankQuestion) error {
+	return r.db.Create(question).Error
+}
+
+func (r *MissionRepository) FindBankQuestion(id uint) (*BankQuestion, error) {
+	var question BankQuestion
+	err := r.db.First(&question, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("bank question not found")
+		}
+		return nil, err
+	}
+	return &question, nil
+}
+
+func (r *MissionRepository) FindBankQuestions(params BankQuestionListParams) ([]BankQuestion, int64, error) {
+	var questions []BankQuestion
+	var total int64
+
+	query := r.bankQuery(r.db, params.OwnerID, params.Tag, params.Difficulty, params.Course)
+	if params.Type != "" {
+		query = query.Where("type = ?", params.Type)
+	}
+	if params.Search != "" {
+		query = query.Where("question LIKE ?", "%"+params.Search+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Order("created_at DESC").
+		Limit(params.Limit).
+		Offset(offset).
+		Find(&questions).Error
+
+	return questions, total, err
+}
+
+// FindPoolQuestionIDs lists the IDs of a dosen's bank questions matching a pool rule
+func (r *MissionRepository) FindPoolQuestionIDs(tx *gorm.DB, ownerID uint, rule PoolRule) ([]uint, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	var ids []uint
+	err := r.bankQuery(tx, ownerID, rule.Tag, rule.Difficulty, rule.Course).
+		Order("id ASC").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *MissionRepository) FindBankQuestionsByIDs(tx *gorm.DB, ids []uint) ([]BankQuestion, error) {
+	var questions []BankQuestion
+	err := tx.Where("id IN ?", ids).Find(&questions).Error
+	return questions, err
+}
+
+func (r *MissionRepository) bankQuery(db *gorm.DB, ownerID uint, tag, difficulty, course string) *gorm.DB {
+	query := db.Model(&BankQuestion{}).Where("owner_id = ?", ownerID)
+	if tag != "" {
+		query = query.Where("JSON_CONTAINS(tags, JSON_QUOTE(?))", tag)
+	}
+	if difficulty != "" {
+		query = query.Where("difficulty = ?", difficulty)
+	}
+	if course != "" {
+		query = query.Where("course = ?", course)
+	}
+	return query
+}
+
+func (r *MissionRepository) SaveBankQuestion(question *BankQuestion) error {
+	return r.db.Save(question).Error
+}
+
+func (r *MissionRepository) DeleteBankQuestion(id uint) error {
+	return r.db.Delete(&BankQuestion{}, id).Error
+}
+
+// FindSessionQuestions lists the questions drawn from the bank for one attempt
+func (r *MissionRepository) FindSessionQuestions(sessionID uint) ([]MissionQuestion, error) {
+	var questions []MissionQuestion
+	err := r.db.Where("session_id = ?", sessionID).Order("id ASC").Find(&questions).Error
+	return questions, err
+}
@@ -0,0 +1,231 @@
+package mission
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"gorm.io/gorm"
+)
+
+// newBankQuestion validates a bank question request and builds the question
+func newBankQuestion(ownerID uint, req *BankQuestionRequest) (*BankQuestion, error) {
+	question, err := newQuestion(0, req.QuestionRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	difficulty := req.Difficulty
+	if difficulty == "" {
+		difficulty = DifficultyMedium
+	}
+
+	return &BankQuestion{
+		OwnerID:        ownerID,
+		Course:         req.Course,
+		Tags:           req.Tags,
+		Difficulty:     difficulty,
+		Type:           question.Type,
+		Question:       question.Question,
+		Options:        question.Options,
+		Answer:         question.Answer,
+		Points:         question.Points,
+		CorrectAnswers: question.CorrectAnswers,
+		Tolerance:      question.Tolerance,
+		MatchMode:      question.MatchMode,
+	}, nil
+}
+
+// snapshot copies a bank question into the questions of one attempt
+func (b *BankQuestion) snapshot(missionID, sessionID uint) MissionQuestion {
+	bankID := b.ID
+	return MissionQuestion{
+		MissionID:      missionID,
+		Type:           b.Type,
+		Question:       b.Question,
+		Options:        b.Options,
+		Answer:         b.Answer,
+		Points:         b.Points,
+		CorrectAnswers: b.CorrectAnswers,
+		Tolerance:      b.Tolerance,
+		MatchMode:      b.MatchMode,
+		SessionID:      &sessionID,
+		BankQuestionID: &bankID,
+	}
+}
+
+func (s *MissionService) CreateBankQuestion(req *BankQuestionRequest, ownerID uint) (*BankQuestion, error) {
+	question, err := newBankQuestion(ownerID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateBankQuestion(question); err != nil {
+		return nil, err
+	}
+
+	return question, nil
+}
+
+func (s *MissionService) GetBankQuestions(params BankQuestionListParams) (*BankQuestionListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+
+	questions, total, err := s.repo.FindBankQuestions(params)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(params.Limit)))
+
+	return &BankQuestionListResponse{
+		Questions:  questions,
+		Total:      total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// GetBankQuestion finds a bank question the user may manage: their own, or any for admins
+func (s *MissionService) GetBankQuestion(id, userID uint, role string) (*BankQuestion, error) {
+	question, err := s.repo.FindBankQuestion(id)
+	if err != nil {
+		return nil, err
+	}
+	if role != "admin" && question.OwnerID != userID {
+		return nil, errors.New("you do not own this question")
+	}
+	return question, nil
+}
+
+// UpdateBankQuestion replaces a bank question. Attempts that already drew it
+// keep their own copy.
+func (s *MissionService) UpdateBankQuestion(id uint, req *BankQuestionRequest, userID uint, role string) (*BankQuestion, error) {
+	existing, err := s.GetBankQuestion(id, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	question, err := newBankQuestion(existing.OwnerID, req)
+	if err != nil {
+		return nil, err
+	}
+	question.ID = existing.ID
+	question.CreatedAt = existing.CreatedAt
+
+	if err := s.repo.SaveBankQuestion(question); err != nil {
+		return nil, err
+	}
+
+	return question, nil
+}
+
+func (s *MissionService) DeleteBankQuestion(id, userID uint, role string) error {
+	if _, err := s.GetBankQuestion(id, userID, role); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteBankQuestion(id)
+}
+
+// newPoolRules checks that a dosen's bank holds enough questions for each rule
+func (s *MissionService) newPoolRules(missionID, ownerID uint, reqs []PoolRuleRequest) ([]PoolRule, error) {
+	rules := make([]PoolRule, 0, len(reqs))
+	for i, req := range reqs {
+		rule := PoolRule{
+			MissionID:  missionID,
+			Count:      req.Count,
+			Tag:        req.Tag,
+			Difficulty: req.Difficulty,
+			Course:     req.Course,
+		}
+		ids, err := s.repo.FindPoolQuestionIDs(nil, ownerID, rule)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) < rule.Count {
+			return nil, fmt.Errorf("pool rule %d: only %d bank questions match, %d needed", i+1, len(ids), rule.Count)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// drawPool draws the questions of a new attempt from the mission creator's
+// bank and stores a copy of each for the session. A question is drawn at most
+// once even when several rules match it.
+func (s *MissionService) drawPool(tx *gorm.DB, mission *Mission, sessionID uint) ([]MissionQuestion, error) {
+	drawn := make(map[uint]bool)
+	var picked []uint
+	for i, rule := range mission.PoolRules {
+		ids, err := s.repo.FindPoolQuestionIDs(tx, mission.CreatorID, rule)
+		if err != nil {
+			return nil, err
+		}
+
+		available := make([]uint, 0, len(ids))
+		for _, id := range ids {
+			if !drawn[id] {
+				available = append(available, id)
+			}
+		}
+		if len(available) < rule.Count {
+			return nil, fmt.Errorf("not enough bank questions left for pool rule %d", i+1)
+		}
+
+		rand.Shuffle(len(available), func(a, b int) {
+			available[a], available[b] = available[b], available[a]
+		})
+		for _, id := range available[:rule.Count] {
+			drawn[id] = true
+			picked = append(picked, id)
+		}
+	}
+	if len(picked) == 0 {
+		return nil, nil
+	}
+
+	bank, err := s.repo.FindBankQuestionsByIDs(tx, picked)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*BankQuestion, len(bank))
+	for i := range bank {
+		byID[bank[i].ID] = &bank[i]
+	}
+
+	questions := make([]MissionQuestion, 0, len(picked))
+	for _, id := range picked {
+		if b, ok := byID[id]; ok {
+			questions = append(questions, b.snapshot(mission.ID, sessionID))
+		}
+	}
+	if err := tx.Create(&questions).Error; err != nil {
+		return nil, err
+	}
+
+	return questions, nil
+}
+
+// sessionMission is the mission as taken in one attempt: its fixed questions
+// plus the questions drawn from the bank for the attempt
+func (s *MissionService) sessionMission(mission *Mission, session *AttemptSession) (*Mission, error) {
+	if len(mission.PoolRules) == 0 {
+		return mission, nil
+	}
+
+	drawn, err := s.repo.FindSessionQuestions(session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	taken := *mission
+	taken.Questions = append(append([]MissionQuestion{}, mission.Questions...), drawn...)
+	return &taken, nil
+}
@@ -0,0 +1,323 @@
+package mission
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetClasses handles listing classes
+// @Summary Get classes
+// @Description List the requester's classes. Admins see every class, or one dosen's with owner_id.
+// @Tags Dosen - Classes
+// @Security BearerAuth
+// @Produce json
+// @Param owner_id query int false "Filter by owning dosen (admin only)"
+// @Param course query string false "Filter by course"
+// @Param search query string false "Search code, name and course"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=ClassListResponse}
+// @Router /dosen/classes [get]
+func (h *MissionHandler) GetClasses(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	params := ClassListParams{
+		OwnerID: c.GetUint("user_id"),
+		Course:  c.Query("course"),
+		Search:  c.Query("search"),
+		Page:    page,
+		Limit:   limit,
+	}
+	if c.GetString("role") == "admin" {
+		ownerID, _ := strconv.ParseUint(c.Query("owner_id"), 10, 32)
+		params.OwnerID = uint(ownerID)
+	}
+
+	response, err := h.service.GetClasses(params)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve classes", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Classes retrieved successfully", response)
+}
+
+// GetMyClasses handles listing the classes a student is enrolled in
+// @Summary Get my classes
+// @Tags Mahasiswa - Classes
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=ClassListResponse}
+// @Router /mahasiswa/classes [get]
+func (h *MissionHandler) GetMyClasses(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	response, err := h.service.GetClasses(ClassListParams{
+		StudentID: c.GetUint("user_id"),
+		Page:      page,
+		Limit:     limit,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve classes", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Classes retrieved successfully", response)
+}
+
+// GetClass handles getting a class with its students
+// @Summary Get class
+// @Tags Dosen - Classes
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Class ID"
+// @Success 200 {object} utils.Response{data=ClassDetail}
+// @Failure 404 {object} utils.Response
+// @Router /dosen/classes/{id} [get]
+func (h *MissionHandler) GetClass(c *gin.Context) {
+	classID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid class ID", nil)
+		return
+	}
+
+	class, err := h.service.GetClass(uint(classID), c.GetUint("user_id"), c.GetString("role"))
+	if err != nil {
+		h.classError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Class retrieved successfully", class)
+}
+
+// CreateClass handles creating a class
+// @Summary Create class
+// @Description Create a class owned by the requester. Missions assigned to it are open only to its students.
+// @Tags Dosen - Classes
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body ClassRequest true "Class"
+// @Success 201 {object} utils.Response{data=Class}
+// @Failure 400 {object} utils.Response
+// @Router /dosen/classes [post]
+func (h *MissionHandler) CreateClass(c *gin.Context) {
+	dosenID := c.GetUint("user_id")
+
+	var req ClassRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	class, err := h.service.CreateClass(&req, dosenID)
+	if err != nil {
+		h.classError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Class created successfully", class)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    dosenID,
+		Action:    "CREATE_CLASS",
+		Entity:    "CLASS",
+		EntityID:  class.ID,
+		Details:   fmt.Sprintf("Dosen created class %s: %s", class.Code, class.Name),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// UpdateClass handles editing a class
+// @Summary Update class
+// @Tags Dosen - Classes
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Class ID"
+// @Param request body ClassRequest true "Class"
+// @Success 200 {object} utils.Response{data=Class}
+// @Failure 400 {object} utils.Response
+// @Router /dosen/classes/{id} [put]
+func (h *MissionHandler) UpdateClass(c *gin.Context) {
+	dosenID := c.GetUint("user_id")
+	classID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid class ID", nil)
+		return
+	}
+
+	var req ClassRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	class, err := h.service.UpdateClass(uint(classID), &req, dosenID, c.GetString("role"))
+	if err != nil {
+		h.classError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Class updated successfully", class)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    dosenID,
+		Action:    "UPDATE_CLASS",
+		Entity:    "CLASS",
+		EntityID:  class.ID,
+		Details:   "Dosen updated class: " + class.Code,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// DeleteClass handles deleting a class
+// @Summary Delete class
+// @Description Delete a class and its enrollments. A class still assigned to missions cannot be deleted.
+// @Tags Dosen - Classes
+// @Security BearerAuth
+// @Param id path int true "Class ID"
+// @Success 200 {object} utils.Response
+// @Router /dosen/classes/{id} [delete]
+func (h *MissionHandler) DeleteClass(c *gin.Context) {
+	dosenID := c.GetUint("user_id")
+	classID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid class ID", nil)
+		return
+	}
+
+	if err := h.service.DeleteClass(uint(classID), dosenID, c.GetString("role")); err != nil {
+		h.classError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Class deleted successfully", nil)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    dosenID,
+		Action:    "DELETE_CLASS",
+		Entity:    "CLASS",
+		EntityID:  uint(classID),
+		Details:   "Dosen deleted class ID: " + strconv.FormatUint(classID, 10),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// EnrollStudents handles adding students to a class
+// @Summary Enroll students
+// @Description Enroll mahasiswa by user ID or NIM. Students already enrolled and entries matching no student are listed in the result.
+// @Tags Dosen - Classes
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Class ID"
+// @Param request body EnrollStudentsRequest true "Students"
+// @Success 200 {object} utils.Response{data=EnrollResult}
+// @Failure 400 {object} utils.Response
+// @Router /dosen/classes/{id}/students [post]
+func (h *MissionHandler) EnrollStudents(c *gin.Context) {
+	dosenID := c.GetUint("user_id")
+	classID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid class ID", nil)
+		return
+	}
+
+	var req EnrollStudentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	result, err := h.service.EnrollStudents(uint(classID), &req, dosenID, c.GetString("role"))
+	if err != nil {
+		h.classError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, fmt.Sprintf("%d student(s) enrolled", len(result.Enrolled)), result)
+
+	// Log activity
+	if len(result.Enrolled) > 0 {
+		h.auditService.LogActivity(audit.CreateAuditParams{
+			UserID:    dosenID,
+			Action:    "ENROLL_STUDENTS",
+			Entity:    "CLASS",
+			EntityID:  uint(classID),
+			Details:   fmt.Sprintf("Dosen enrolled %d student(s) in class ID %d", len(result.Enrolled), classID),
+			IPAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+	}
+}
+
+// UnenrollStudent handles removing a student from a class
+// @Summary Unenroll student
+// @Tags Dosen - Classes
+// @Security BearerAuth
+// @Param id path int true "Class ID"
+// @Param studentId path int true "Student user ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /dosen/classes/{id}/students/{studentId} [delete]
+func (h *MissionHandler) UnenrollStudent(c *gin.Context) {
+	dosenID := c.GetUint("user_id")
+	classID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid class ID", nil)
+		return
+	}
+	studentID, err := strconv.ParseUint(c.Param("studentId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid student ID", nil)
+		return
+	}
+
+	if err := h.service.UnenrollStudent(uint(classID), uint(studentID), dosenID, c.GetString("role")); err != nil {
+		h.classError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Student unenrolled successfully", nil)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    dosenID,
+		Action:    "UNENROLL_STUDENT",
+		Entity:    "CLASS",
+		EntityID:  uint(classID),
+		Details:   fmt.Sprintf("Dosen removed student ID %d from class ID %d", studentID, classID),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// classError maps class errors to HTTP status codes
+func (h *MissionHandler) classError(c *gin.Context, err error) {
+	statusCode := http.StatusBadRequest
+	switch err.Error() {
+	case "class not found", "student is not enrolled in this class":
+		statusCode = http.StatusNotFound
+	case "you do not own this class":
+		statusCode = http.StatusForbidden
+	case "class code already exists":
+		statusCode = http.StatusConflict
+	}
+	utils.ErrorResponse(c, statusCode, err.Error(), nil)
+}
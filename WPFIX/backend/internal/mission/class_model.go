@@ -0,0 +1,102 @@
+package mission
+
+import "time"
+
+// Class is a cohort of students taught by a dosen, such as one section of a
+// course in a semester. Missions assigned to classes are open only to the
+// students enrolled in them; missions with no class are open to everyone.
+type Class struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	OwnerID     uint      `json:"owner_id" gorm:"not null;index"`
+	Code        string    `json:"code" gorm:"size:50;uniqueIndex;not null"`
+	Name        string    `json:"name" gorm:"not null"`
+	Course      string    `json:"course" gorm:"size:100;index"`
+	Semester    string    `json:"semester" gorm:"size:20"`
+	Description string    `json:"description" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (Class) TableName() string {
+	return "classes"
+}
+
+type ClassEnrollment struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ClassID   uint      `json:"class_id" gorm:"not null;uniqueIndex:idx_class_student"`
+	StudentID uint      `json:"student_id" gorm:"not null;index;uniqueIndex:idx_class_student"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (ClassEnrollment) TableName() string {
+	return "class_enrollments"
+}
+
+// MissionClass assigns a mission to a class
+type MissionClass struct {
+	MissionID uint `json:"mission_id" gorm:"primaryKey"`
+	ClassID   uint `json:"class_id" gorm:"primaryKey;index"`
+}
+
+func (MissionClass) TableName() string {
+	return "mission_classes"
+}
+
+type ClassRequest struct {
+	Code        string `json:"code" binding:"required,max=50"`
+	Name        string `json:"name" binding:"required"`
+	Course      string `json:"course" binding:"omitempty,max=100"`
+	Semester    string `json:"semester" binding:"omitempty,max=20"`
+	Description string `json:"description"`
+}
+
+// EnrollStudentsRequest names students by user ID, NIM or both
+type EnrollStudentsRequest struct {
+	StudentIDs []uint   `json:"student_ids"`
+	NIMs       []string `json:"nims"`
+}
+
+type EnrollResult struct {
+	Enrolled        []uint   `json:"enrolled"`
+	AlreadyEnrolled []uint   `json:"already_enrolled"`
+	NotFound        []string `json:"not_found"` // IDs or NIMs matching no student
+}
+
+type ClassWithDetails struct {
+	Class
+	OwnerName    string `json:"owner_name"`
+	StudentCount int64  `json:"student_count"`
+	MissionCount int64  `json:"mission_count"`
+}
+
+type ClassStudent struct {
+	StudentID  uint      `json:"student_id"`
+	FullName   string    `json:"full_name"`
+	NimNip     string    `json:"nim_nip"`
+	Email      string    `json:"email"`
+	Major      string    `json:"major"`
+	Batch      string    `json:"batch"`
+	EnrolledAt time.Time `json:"enrolled_at"`
+}
+
+type ClassDetail struct {
+	ClassWithDetails
+	Students []ClassStudent `json:"students"`
+}
+
+type ClassListParams struct {
+	OwnerID   uint
+	StudentID uint // Classes this student is enrolled in
+	Course    string
+	Search    string
+	Page      int
+	Limit     int
+}
+
+type ClassListResponse struct {
+	Classes    []ClassWithDetails `json:"classes"`
+	Total      int64              `json:"total"`
+	Page       int                `json:"page"`
+	Limit      int                `json:"limit"`
+	TotalPages int                `json:"total_pages"`
+}
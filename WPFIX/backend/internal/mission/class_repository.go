@@ -0,0 +1,174 @@
+package mission
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// Classes
+func (r *MissionRepository) CreateClass(class *Class) error {
+	return r.db.Create(class).Error
+}
+
+func (r *MissionRepository) FindClassByID(id uint) (*ClassWithDetails, error) {
+	var class ClassWithDetails
+	err := r.classQuery().Where("classes.id = ?", id).Take(&class).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("class not found")
+		}
+		return nil, err
+	}
+	return &class, nil
+}
+
+func (r *MissionRepository) FindClassByCode(code string) (*Class, error) {
+	var class Class
+	err := r.db.Where("code = ?", code).First(&class).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("class not found")
+		}
+		return nil, err
+	}
+	return &class, nil
+}
+
+func (r *MissionRepository) FindClasses(params ClassListParams) ([]ClassWithDetails, int64, error) {
+	var classes []ClassWithDetails
+	var total int64
+
+	query := r.classQuery()
+	if params.OwnerID > 0 {
+		query = query.Where("classes.owner_id = ?", params.OwnerID)
+	}
+	if params.StudentID > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM class_enrollments WHERE class_enrollments.class_id = classes.id AND class_enrollments.student_id = ?)", params.StudentID)
+	}
+	if params.Course != "" {
+		query = query.Where("classes.course = ?", params.Course)
+	}
+	if params.Search != "" {
+		search := "%" + params.Search + "%"
+		query = query.Where("(classes.code LIKE ? OR classes.name LIKE ? OR classes.course LIKE ?)", search, search, search)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Order("classes.created_at DESC").
+		Limit(params.Limit).
+		Offset(offset).
+		Scan(&classes).Error
+
+	return classes, total, err
+}
+
+func (r *MissionRepository) classQuery() *gorm.DB {
+	return r.db.Table("classes").
+		Select("classes.*, users.full_name as owner_name, " +
+			"(SELECT COUNT(*) FROM class_enrollments WHERE class_enrollments.class_id = classes.id) as student_count, " +
+			"(SELECT COUNT(*) FROM mission_classes WHERE mission_classes.class_id = classes.id) as mission_count").
+		Joins("LEFT JOIN users ON users.id = classes.owner_id")
+}
+
+func (r *MissionRepository) FindClassesByIDs(ids []uint) ([]Class, error) {
+	var classes []Class
+	err := r.db.Where("id IN ?", ids).Find(&classes).Error
+	return classes, err
+}
+
+func (r *MissionRepository) SaveClass(class *Class) error {
+	return r.db.Save(class).Error
+}
+
+// DeleteClass removes a class and its enrollments
+func (r *MissionRepository) DeleteClass(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("class_id = ?", id).Delete(&ClassEnrollment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Class{}, id).Error
+	})
+}
+
+// Enrollments
+
+// FindClassStudents lists the students enrolled in a class by name
+func (r *MissionRepository) FindClassStudents(classID uint) ([]ClassStudent, error) {
+	var students []ClassStudent
+	err := r.db.Table("class_enrollments").
+		Select("users.id as student_id, users.full_name, users.nim_nip, users.email, users.major, users.batch, class_enrollments.created_at as enrolled_at").
+		Joins("JOIN users ON users.id = class_enrollments.student_id").
+		Where("class_enrollments.class_id = ?", classID).
+		Order("users.full_name ASC").
+		Scan(&students).Error
+	return students, err
+}
+
+// FindStudentUsers finds mahasiswa accounts by user ID or NIM
+func (r *MissionRepository) FindStudentUsers(ids []uint, nims []string) ([]ClassStudent, error) {
+	var students []ClassStudent
+	query := r.db.Table("users").
+		Select("users.id as student_id, users.full_name, users.nim_nip, users.email, users.major, users.batch").
+		Where("users.role = ?", "mahasiswa")
+	switch {
+	case len(ids) > 0 && len(nims) > 0:
+		query = query.Where("(users.id IN ? OR users.nim_nip IN ?)", ids, nims)
+	case len(ids) > 0:
+		query = query.Where("users.id IN ?", ids)
+	default:
+		query = query.Where("users.nim_nip IN ?", nims)
+	}
+	err := query.Scan(&students).Error
+	return students, err
+}
+
+// FindEnrolledStudentIDs lists which of the given students are enrolled in a class
+func (r *MissionRepository) FindEnrolledStudentIDs(classID uint, studentIDs []uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&ClassEnrollment{}).
+		Where("class_id = ? AND student_id IN ?", classID, studentIDs).
+		Pluck("student_id", &ids).Error
+	return ids, err
+}
+
+func (r *MissionRepository) CreateEnrollments(enrollments []ClassEnrollment) error {
+	return r.db.Create(&enrollments).Error
+}
+
+func (r *MissionRepository) DeleteEnrollment(classID, studentID uint) error {
+	result := r.db.Where("class_id = ? AND student_id = ?", classID, studentID).Delete(&ClassEnrollment{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("student is not enrolled in this class")
+	}
+	return nil
+}
+
+// IsEnrolledInAny reports whether a student is enrolled in any of the classes
+func (r *MissionRepository) IsEnrolledInAny(studentID uint, classIDs []uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&ClassEnrollment{}).
+		Where("student_id = ? AND class_id IN ?", studentID, classIDs).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ReplaceMissionClasses assigns a mission to exactly the given classes
+func (r *MissionRepository) ReplaceMissionClasses(missionID uint, classes []MissionClass) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("mission_id = ?", missionID).Delete(&MissionClass{}).Error; err != nil {
+			return err
+		}
+		if len(classes) > 0 {
+			return tx.Create(&classes).Error
+		}
+		return nil
+	})
+}
@@ -0,0 +1,255 @@
+package mission
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var errNotInClass = errors.New("this mission is only open to the classes it is assigned to")
+
+func (s *MissionService) CreateClass(req *ClassRequest, ownerID uint) (*Class, error) {
+	code := strings.TrimSpace(req.Code)
+	if _, err := s.repo.FindClassByCode(code); err == nil {
+		return nil, errors.New("class code already exists")
+	}
+
+	class := &Class{
+		OwnerID:     ownerID,
+		Code:        code,
+		Name:        strings.TrimSpace(req.Name),
+		Course:      strings.TrimSpace(req.Course),
+		Semester:    strings.TrimSpace(req.Semester),
+		Description: req.Description,
+	}
+	if err := s.repo.CreateClass(class); err != nil {
+		return nil, err
+	}
+
+	return class, nil
+}
+
+func (s *MissionService) GetClasses(params ClassListParams) (*ClassListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+
+	classes, total, err := s.repo.FindClasses(params)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(params.Limit)))
+
+	return &ClassListResponse{
+		Classes:    classes,
+		Total:      total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// findOwnedClass finds a class the user may manage: their own, or any for admins
+func (s *MissionService) findOwnedClass(id, userID uint, role string) (*ClassWithDetails, error) {
+	class, err := s.repo.FindClassByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if role != "admin" && class.OwnerID != userID {
+		return nil, errors.New("you do not own this class")
+	}
+	return class, nil
+}
+
+// GetClass returns a class the user manages with its enrolled students
+func (s *MissionService) GetClass(id, userID uint, role string) (*ClassDetail, error) {
+	class, err := s.findOwnedClass(id, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	students, err := s.repo.FindClassStudents(id)
+	if err != nil {
+		return nil, err
+	}
+	if students == nil {
+		students = []ClassStudent{}
+	}
+
+	return &ClassDetail{ClassWithDetails: *class, Students: students}, nil
+}
+
+func (s *MissionService) UpdateClass(id uint, req *ClassRequest, userID uint, role string) (*Class, error) {
+	existing, err := s.findOwnedClass(id, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	code := strings.TrimSpace(req.Code)
+	if other, err := s.repo.FindClassByCode(code); err == nil && other.ID != id {
+		return nil, errors.New("class code already exists")
+	}
+
+	class := existing.Class
+	class.Code = code
+	class.Name = strings.TrimSpace(req.Name)
+	class.Course = strings.TrimSpace(req.Course)
+	class.Semester = strings.TrimSpace(req.Semester)
+	class.Description = req.Description
+
+	if err := s.repo.SaveClass(&class); err != nil {
+		return nil, err
+	}
+
+	return &class, nil
+}
+
+// DeleteClass removes a class and its enrollments. A class with missions
+// cannot be deleted, since its missions would then open to every student.
+func (s *MissionService) DeleteClass(id, userID uint, role string) error {
+	class, err := s.findOwnedClass(id, userID, role)
+	if err != nil {
+		return err
+	}
+	if class.MissionCount > 0 {
+		return fmt.Errorf("class has %d mission(s) assigned; remove it from them first", class.MissionCount)
+	}
+
+	return s.repo.DeleteClass(id)
+}
+
+// EnrollStudents adds students to a class by user ID or NIM. Students already
+// enrolled and names matching no student are reported rather than failing.
+func (s *MissionService) EnrollStudents(id uint, req *EnrollStudentsRequest, userID uint, role string) (*EnrollResult, error) {
+	if _, err := s.findOwnedClass(id, userID, role); err != nil {
+		return nil, err
+	}
+	if len(req.StudentIDs) == 0 && len(req.NIMs) == 0 {
+		return nil, errors.New("student_ids or nims is required")
+	}
+
+	students, err := s.repo.FindStudentUsers(req.StudentIDs, req.NIMs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &EnrollResult{Enrolled: []uint{}, AlreadyEnrolled: []uint{}, NotFound: []string{}}
+	foundIDs := make(map[uint]bool)
+	foundNIMs := make(map[string]bool)
+	var studentIDs []uint
+	for _, student := range students {
+		foundIDs[student.StudentID] = true
+		foundNIMs[student.NimNip] = true
+		studentIDs = append(studentIDs, student.StudentID)
+	}
+	for _, studentID := range req.StudentIDs {
+		if !foundIDs[studentID] {
+			result.NotFound = append(result.NotFound, strconv.FormatUint(uint64(studentID), 10))
+		}
+	}
+	for _, nim := range req.NIMs {
+		if !foundNIMs[nim] {
+			result.NotFound = append(result.NotFound, nim)
+		}
+	}
+	if len(studentIDs) == 0 {
+		return result, nil
+	}
+
+	enrolled, err := s.repo.FindEnrolledStudentIDs(id, studentIDs)
+	if err != nil {
+		return nil, err
+	}
+	already := make(map[uint]bool, len(enrolled))
+	for _, studentID := range enrolled {
+		already[studentID] = true
+	}
+
+	var enrollments []ClassEnrollment
+	for _, studentID := range studentIDs {
+		if already[studentID] {
+			result.AlreadyEnrolled = append(result.AlreadyEnrolled, studentID)
+			continue
+		}
+		enrollments = append(enrollments, ClassEnrollment{ClassID: id, StudentID: studentID})
+		result.Enrolled = append(result.Enrolled, studentID)
+	}
+	if len(enrollments) > 0 {
+		if err := s.repo.CreateEnrollments(enrollments); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func (s *MissionService) UnenrollStudent(id, studentID, userID uint, role string) error {
+	if _, err := s.findOwnedClass(id, userID, role); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteEnrollment(id, studentID)
+}
+
+// newMissionClasses checks that each class exists and belongs to the
+// mission's creator
+func (s *MissionService) newMissionClasses(missionID, ownerID uint, ids []uint) ([]MissionClass, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	classes, err := s.repo.FindClassesByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[uint]Class, len(classes))
+	for _, class := range classes {
+		found[class.ID] = class
+	}
+
+	assigned := make([]MissionClass, 0, len(ids))
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		class, ok := found[id]
+		if !ok {
+			return nil, fmt.Errorf("class %d not found", id)
+		}
+		if class.OwnerID != ownerID {
+			return nil, fmt.Errorf("class %s is not one of your classes", class.Code)
+		}
+		assigned = append(assigned, MissionClass{MissionID: missionID, ClassID: id})
+	}
+	return assigned, nil
+}
+
+// checkClassAccess lets a student take a mission with no class, or one
+// assigned to a class they are enrolled in
+func (s *MissionService) checkClassAccess(mission *Mission, studentID uint) error {
+	if len(mission.Classes) == 0 {
+		return nil
+	}
+
+	classIDs := make([]uint, 0, len(mission.Classes))
+	for _, c := range mission.Classes {
+		classIDs = append(classIDs, c.ClassID)
+	}
+	enrolled, err := s.repo.IsEnrolledInAny(studentID, classIDs)
+	if err != nil {
+		return err
+	}
+	if !enrolled {
+		return errNotInClass
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package mission
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// QuestionFeedback tells a student how one quiz answer was graded
+type QuestionFeedback struct {
+	QuestionID uint    `json:"question_id"`
+	Question   string  `json:"question"`
+	Answer     string  `json:"answer"` // What the student answered
+	Correct    bool    `json:"correct"`
+	Earned     float64 `json:"earned"` // Points earned, partial credit included
+	Points     int     `json:"points"`
+}
+
+// QuizFeedback is the per-question result of an auto-graded quiz, stored as JSON
+type QuizFeedback []QuestionFeedback
+
+func (qf QuizFeedback) Value() (driver.Value, error) {
+	if qf == nil {
+		return nil, nil
+	}
+	return json.Marshal(qf)
+}
+
+func (qf *QuizFeedback) Scan(value interface{}) error {
+	if value == nil {
+		*qf = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, qf)
+}
+
+// QuizResult is the outcome of grading a quiz submission
+type QuizResult struct {
+	Correct    int // Questions answered fully right
+	Total      int
+	Earned     float64
+	MaxPoints  int
+	Percentage float64
+	Passed     bool
+	Feedback   QuizFeedback
+}
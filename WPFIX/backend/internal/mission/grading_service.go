@@ -0,0 +1,87 @@
+package mission
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// GradeQuiz grades a student's answers with the grader of each question type.
+// A question left unanswered earns nothing. The quiz is passed when the
+// mission has a pass threshold and the percentage of points reaches it.
+func GradeQuiz(mission *Mission, answers []AnswerSubmission) *QuizResult {
+	given := make(map[uint]AnswerSubmission, len(answers))
+	for _, a := range answers {
+		given[a.QuestionID] = a
+	}
+
+	result := &QuizResult{
+		Total:    len(mission.Questions),
+		Feedback: make(QuizFeedback, 0, len(mission.Questions)),
+	}
+	for i := range mission.Questions {
+		question := &mission.Questions[i]
+		answer := given[question.ID]
+		points := question.Points
+		if points <= 0 {
+			points = 1
+		}
+
+		share := math.Max(0, math.Min(1, graderFor(question.Type).Grade(question, answer)))
+		earned := math.Round(share*float64(points)*100) / 100
+		if share == 1 {
+			result.Correct++
+		}
+		result.Earned += earned
+		result.MaxPoints += points
+
+		shown := answer.Answer
+		if len(answer.Answers) > 0 {
+			shown = strings.Join(answer.Answers, ", ")
+		}
+		result.Feedback = append(result.Feedback, QuestionFeedback{
+			QuestionID: question.ID,
+			Question:   question.Question,
+			Answer:     shown,
+			Correct:    share == 1,
+			Earned:     earned,
+			Points:     points,
+		})
+	}
+
+	if result.MaxPoints > 0 {
+		percentage := result.Earned * 100 / float64(result.MaxPoints)
+		result.Percentage = math.Round(percentage*100) / 100
+	}
+	result.Passed = mission.PassThreshold > 0 && result.Percentage >= float64(mission.PassThreshold)
+
+	return result
+}
+
+// isCorrectAnswer matches an answer against the key, ignoring case and
+// surrounding spaces. For multiple choice the key and the answer may each be
+// either the option text or its zero-based index.
+func isCorrectAnswer(question *MissionQuestion, answer string) bool {
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return false
+	}
+
+	key := resolveOption(question.Options, question.Answer)
+	return strings.EqualFold(key, resolveOption(question.Options, answer))
+}
+
+// resolveOption turns an option index into the option text. A value naming
+// an option by its text wins, so numeric options are not read as indexes.
+func resolveOption(options JSONOptions, value string) string {
+	value = strings.TrimSpace(value)
+	for _, o := range options {
+		if strings.EqualFold(strings.TrimSpace(o), value) {
+			return strings.TrimSpace(o)
+		}
+	}
+	if index, err := strconv.Atoi(value); err == nil && index >= 0 && index < len(options) {
+		return strings.TrimSpace(options[index])
+	}
+	return value
+}
@@ -0,0 +1,141 @@
+package mission
+
+import "testing"
+
+// rightAnswers answers every question of quizWithEveryType correctly
+func rightAnswers() []AnswerSubmission {
+	return []AnswerSubmission{
+		{QuestionID: 1, Answer: "Paris"},
+		{QuestionID: 2, Answers: []string{"2", "7"}},
+		{QuestionID: 3, Answer: "3.14"},
+		{QuestionID: 4, Answer: "Go"},
+		{QuestionID: 5, Answers: []string{"Mercury", "Venus", "Earth"}},
+		{QuestionID: 6, Answers: []string{"Tokyo", "Rome"}},
+	}
+}
+
+func TestGradeQuiz(t *testing.T) {
+	tests := []struct {
+		name        string
+		threshold   int
+		answers     []AnswerSubmission
+		wantCorrect int
+		wantEarned  float64
+		wantPercent float64
+		wantPassed  bool
+	}{
+		{"all right", 70, rightAnswers(), 6, 10, 100, true},
+		{"no pass threshold", 0, rightAnswers(), 6, 10, 100, false},
+		{
+			"exactly on the threshold", 70,
+			[]AnswerSubmission{
+				{QuestionID: 1, Answer: "Paris"},
+				{QuestionID: 2, Answers: []string{"7", "2"}},
+				{QuestionID: 3, Answer: "3,141"},
+				{QuestionID: 5, Answers: []string{"Mercury", "Venus", "Earth"}},
+			},
+			4, 7, 70, true,
+		},
+		{
+			"partial credit below the threshold", 70,
+			[]AnswerSubmission{
+				{QuestionID: 1, Answer: "0"},
+				{QuestionID: 2, Answers: []string{"2"}},
+				{QuestionID: 4, Answer: "python"},
+				{QuestionID: 5, Answers: []string{"Mercury", "Earth", "Venus"}},
+				{QuestionID: 6, Answers: []string{"Rome", "Tokyo"}},
+			},
+			1, 3, 30, false,
+		},
+		{"no answers", 50, nil, 0, 0, 0, false},
+		{"answers to unknown questions", 50, []AnswerSubmission{{QuestionID: 99, Answer: "Paris"}}, 0, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mission := quizWithEveryType()
+			mission.PassThreshold = tt.threshold
+
+			result := GradeQuiz(mission, tt.answers)
+
+			if result.Total != 6 || result.MaxPoints != 10 {
+				t.Errorf("total = %d questions, %d points; want 6 questions, 10 points", result.Total, result.MaxPoints)
+			}
+			if result.Correct != tt.wantCorrect {
+				t.Errorf("correct = %d, want %d", result.Correct, tt.wantCorrect)
+			}
+			if result.Earned != tt.wantEarned {
+				t.Errorf("earned = %v, want %v", result.Earned, tt.wantEarned)
+			}
+			if result.Percentage != tt.wantPercent {
+				t.Errorf("percentage = %v, want %v", result.Percentage, tt.wantPercent)
+			}
+			if result.Passed != tt.wantPassed {
+				t.Errorf("passed = %v, want %v", result.Passed, tt.wantPassed)
+			}
+			if len(result.Feedback) != 6 {
+				t.Fatalf("got feedback for %d questions, want 6", len(result.Feedback))
+			}
+		})
+	}
+}
+
+func TestGradeQuizFeedback(t *testing.T) {
+	result := GradeQuiz(quizWithEveryType(), []AnswerSubmission{
+		{QuestionID: 2, Answers: []string{"2", "4"}},
+		{QuestionID: 3, Answer: "3.14"},
+	})
+
+	tests := []struct {
+		index       int
+		wantAnswer  string
+		wantCorrect bool
+		wantEarned  float64
+		wantPoints  int
+	}{
+		{0, "", false, 0, 1},
+		// One right and one wrong choice cancel out
+		{1, "2, 4", false, 0, 2},
+		{2, "3.14", true, 1, 1},
+		{3, "", false, 0, 1},
+	}
+	for _, tt := range tests {
+		f := result.Feedback[tt.index]
+		if f.QuestionID != uint(tt.index+1) {
+			t.Errorf("feedback %d is for question %d", tt.index, f.QuestionID)
+		}
+		if f.Answer != tt.wantAnswer || f.Correct != tt.wantCorrect || f.Earned != tt.wantEarned || f.Points != tt.wantPoints {
+			t.Errorf("feedback %d = %+v, want answer %q, correct %v, earned %v of %d",
+				tt.index, f, tt.wantAnswer, tt.wantCorrect, tt.wantEarned, tt.wantPoints)
+		}
+	}
+}
+
+func TestGradeQuizRoundsPartialCredit(t *testing.T) {
+	mission := &Mission{
+		PassThreshold: 50,
+		Questions: []MissionQuestion{
+			{ID: 1, Type: QuestionOrdering, Options: JSONOptions{"A", "B", "C"}, CorrectAnswers: JSONOptions{"A", "B", "C"}, Points: 1},
+			{ID: 2, Type: QuestionSingleChoice, Options: JSONOptions{"yes", "no"}, Answer: "yes"},
+			{ID: 3, Type: QuestionSingleChoice, Options: JSONOptions{"yes", "no"}, Answer: "yes"},
+		},
+	}
+
+	// A third of a one-point question, plus one of two unweighted questions
+	result := GradeQuiz(mission, []AnswerSubmission{
+		{QuestionID: 1, Answers: []string{"A", "C", "B"}},
+		{QuestionID: 2, Answer: "yes"},
+	})
+
+	if result.MaxPoints != 3 {
+		t.Errorf("max points = %d, want 3 with unweighted questions worth 1", result.MaxPoints)
+	}
+	if result.Earned != 1.33 {
+		t.Errorf("earned = %v, want 1.33", result.Earned)
+	}
+	if result.Percentage != 44.33 {
+		t.Errorf("percentage = %v, want 44.33", result.Percentage)
+	}
+	if result.Passed {
+		t.Error("44.33% passed a 50% threshold")
+	}
+}
@@ -35,6 +35,8 @@ func NewMissionHandler(service *MissionService, auditService *audit.AuditService
 // @Param type query string false "Filter by type"
 // @Param status query string false "Filter by status"
 // @Param created_by query int false "Filter by creator"
+// @Param class_id query int false "Filter by class"
+// @Param template_id query int false "Filter by recurring mission: its instances"
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
 // @Success 200 {object} utils.Response{data=MissionListResponse}
@@ -43,19 +45,27 @@ func (h *MissionHandler) GetAllMissions(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 	createdBy, _ := strconv.ParseUint(c.Query("created_by"), 10, 32)
+	classID, _ := strconv.ParseUint(c.Query("class_id"), 10, 32)
+	templateID, _ := strconv.ParseUint(c.Query("template_id"), 10, 32)
 
 	params := MissionListParams{
-		Type:      c.Query("type"),
-		Status:    c.Query("status"),
-		CreatedBy: uint(createdBy),
-		Page:      page,
-		Limit:     limit,
+		Type:       c.Query("type"),
+		Status:     c.Query("status"),
+		CreatedBy:  uint(createdBy),
+		ClassID:    uint(classID),
+		TemplateID: uint(templateID),
+		Page:       page,
+		Limit:      limit,
 	}
 
-	// Security: Students should only see active missions by default
-	userRole := c.GetString("user_role")
-	if userRole == "mahasiswa" && params.Status == "" {
-		params.Status = "active"
+	// Security: Students should only see active missions by default, and only
+	// those open to their classes; unpublished missions and templates stay hidden
+	userRole := c.GetString("role")
+	if userRole == "mahasiswa" {
+		if params.Status == "" || params.Status == MissionScheduled || params.Status == MissionRecurring {
+			params.Status = "active"
+		}
+		params.StudentID = c.GetUint("user_id")
 	}
 
 	response, err := h.service.GetAllMissions(params)
@@ -69,13 +79,15 @@ func (h *MissionHandler) GetAllMissions(c *gin.Context) {
 
 // GetMissionByID handles getting mission by ID
 // @Summary Get mission by ID
-// @Description Get mission details
+// @Description Get mission details. Students get the questions without their answers (StudentMission), and only for missions open to their classes; dosen and admins get the full mission.
 // @Tags Missions
 // @Security BearerAuth
 // @Produce json
 // @Param id path int true "Mission ID"
 // @Success 200 {object} utils.Response{data=Mission}
-// @Router /missions/{id} [get]
+// @Success 200 {object} utils.Response{data=StudentMission}
+// @Router /dosen/missions/{id} [get]
+// @Router /mahasiswa/missions/{id} [get]
 func (h *MissionHandler) GetMissionByID(c *gin.Context) {
 	missionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -88,13 +100,23 @@ func (h *MissionHandler) GetMissionByID(c *gin.Context) {
 		utils.ErrorResponse(c, http.StatusNotFound, err.Error(), nil)
 		return
 	}
+	if c.GetString("role") == "mahasiswa" {
+		if mission.Status == MissionScheduled || mission.Status == MissionRecurring {
+			utils.ErrorResponse(c, http.StatusNotFound, "mission not found", nil)
+			return
+		}
+		if err := h.service.checkClassAccess(mission, c.GetUint("user_id")); err != nil {
+			utils.ErrorResponse(c, http.StatusForbidden, err.Error(), nil)
+			return
+		}
+	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Mission retrieved successfully", mission)
+	utils.SuccessResponse(c, http.StatusOK, "Mission retrieved successfully", missionView(mission, c.GetString("role")))
 }
 
 // CreateMission handles creating new mission (Dosen only)
 // @Summary Create mission
-// @Description Create a new mission
+// @Description Create a new mission. A publish_at in the future schedules it; with a recurrence it becomes a template that spawns an instance, with its own submissions and rewards, every day, week or month from publish_at.
 // @Tags Dosen - Missions
 // @Security BearerAuth
 // @Accept json
@@ -225,7 +247,7 @@ func (h *MissionHandler) DeleteMission(c *gin.Context) {
 
 // SubmitMission handles student submission
 // @Summary Submit mission
-// @Description Student submits mission work
+// @Description Student submits mission work. Quiz answers are graded immediately with per-question feedback, and a score reaching the mission's pass threshold is approved and rewarded automatically.
 // @Tags Mahasiswa - Missions
 // @Security BearerAuth
 // @Accept json
@@ -242,12 +264,7 @@ func (h *MissionHandler) SubmitMission(c *gin.Context) {
 		var req SubmitMissionRequest
 		if err := c.ShouldBindJSON(&req); err == nil {
 			// Process JSON request
-			submission, err := h.service.SubmitMission(&req, studentID)
-			if err != nil {
-				utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
-				return
-			}
-			utils.SuccessResponse(c, http.StatusCreated, "Mission submitted successfully", submission)
+			h.submit(c, &req, studentID)
 			return
 		}
 	}
@@ -303,13 +320,31 @@ func (h *MissionHandler) SubmitMission(c *gin.Context) {
 		FileURL:   fileURL,
 	}
 
-	submission, err := h.service.SubmitMission(&req, studentID)
+	h.submit(c, &req, studentID)
+}
+
+// submit stores a submission from either the JSON or the multipart form
+func (h *MissionHandler) submit(c *gin.Context, req *SubmitMissionRequest, studentID uint) {
+	submission, err := h.service.SubmitMission(req, studentID)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		statusCode := http.StatusBadRequest
+		if err.Error() == errNotInClass.Error() {
+			statusCode = http.StatusForbidden
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusCreated, "Mission submitted successfully", submission)
+	message := "Mission submitted successfully"
+	details := "Student submitted mission work"
+	if submission.AutoGraded {
+		details = fmt.Sprintf("Student submitted quiz, auto-graded %d/%d (%.2f%%), %s", submission.Score, submission.MaxScore, submission.Percentage, submission.Status)
+		if submission.Status == "approved" {
+			message = "Quiz passed and reward credited"
+		}
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, message, submission)
 
 	// Log activity
 	h.auditService.LogActivity(audit.CreateAuditParams{
@@ -317,7 +352,7 @@ func (h *MissionHandler) SubmitMission(c *gin.Context) {
 		Action:    "SUBMIT_MISSION",
 		Entity:    "SUBMISSION",
 		EntityID:  submission.ID,
-		Details:   "Student submitted mission work",
+		Details:   details,
 		IPAddress: c.ClientIP(),
 		UserAgent: c.Request.UserAgent(),
 	})
@@ -331,10 +366,13 @@ func (h *MissionHandler) SubmitMission(c *gin.Context) {
 // @Produce json
 // @Param mission_id query int false "Filter by mission"
 // @Param student_id query int false "Filter by student"
+// @Param class_id query int false "Filter by class: its students' submissions to missions assigned to it. Dosen must own the class."
 // @Param status query string false "Filter by status"
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
 // @Success 200 {object} utils.Response{data=SubmissionListResponse}
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
 // @Router /missions/submissions [get]
 func (h *MissionHandler) GetAllSubmissions(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -342,26 +380,35 @@ func (h *MissionHandler) GetAllSubmissions(c *gin.Context) {
 	missionID, _ := strconv.ParseUint(c.Query("mission_id"), 10, 32)
 	studentID, _ := strconv.ParseUint(c.Query("student_id"), 10, 32)
 	creatorID, _ := strconv.ParseUint(c.Query("creator_id"), 10, 32)
+	classID, _ := strconv.ParseUint(c.Query("class_id"), 10, 32)
 
 	// Parse query params
 	params := SubmissionListParams{
 		MissionID: uint(missionID),
 		StudentID: uint(studentID),
 		CreatorID: uint(creatorID),
+		ClassID:   uint(classID),
 		Status:    c.Query("status"),
 		Page:      page,
 		Limit:     limit,
 	}
 
 	// Security: If requester is a student, only show their own submissions
-	userRole := c.GetString("user_role")
+	userRole := c.GetString("role")
 	if userRole == "mahasiswa" {
 		params.StudentID = c.GetUint("user_id")
 	}
 
-	response, err := h.service.GetAllSubmissions(params)
+	response, err := h.service.GetAllSubmissions(params, c.GetUint("user_id"), userRole)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve submissions", err.Error())
+		switch err.Error() {
+		case "class not found":
+			utils.ErrorResponse(c, http.StatusNotFound, err.Error(), nil)
+		case "you do not own this class":
+			utils.ErrorResponse(c, http.StatusForbidden, err.Error(), nil)
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve submissions", err.Error())
+		}
 		return
 	}
 
@@ -414,18 +461,27 @@ func (h *MissionHandler) ReviewSubmission(c *gin.Context) {
 
 // GetDosenStats handles getting Dosen dashboard stats
 // @Summary Get Dosen stats
-// @Description Get statistics for Dosen dashboard
+// @Description Get statistics for Dosen dashboard, overall or for one of their classes
 // @Tags Dosen - Missions
 // @Security BearerAuth
 // @Produce json
+// @Param class_id query int false "Count only this class's missions and students"
 // @Success 200 {object} utils.Response{data=DosenStatsResponse}
 // @Router /dosen/stats [get]
 func (h *MissionHandler) GetDosenStats(c *gin.Context) {
 	dosenID := c.GetUint("user_id")
+	classID, _ := strconv.ParseUint(c.Query("class_id"), 10, 32)
 
-	stats, err := h.service.GetDosenStats(dosenID)
+	stats, err := h.service.GetDosenStats(dosenID, uint(classID), c.GetString("role"))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get stats", err.Error())
+		switch err.Error() {
+		case "class not found":
+			utils.ErrorResponse(c, http.StatusNotFound, err.Error(), nil)
+		case "you do not own this class":
+			utils.ErrorResponse(c, http.StatusForbidden, err.Error(), nil)
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get stats", err.Error())
+		}
 		return
 	}
 
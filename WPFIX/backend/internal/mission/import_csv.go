@@ -0,0 +1,118 @@
+package mission
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CSV columns, one question per row. Lists such as options are separated by
+// csvListSeparator within their cell.
+var csvColumns = []string{"type", "question", "options", "answer", "correct_answers", "points", "tolerance", "match_mode"}
+
+const csvListSeparator = "|"
+
+// parseCSV reads questions from a CSV file with a header row. Columns are
+// matched by name, so their order does not matter and only question is required.
+func parseCSV(data []byte) (*importedFile, error) {
+	// Spreadsheets often save a byte order mark
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %v", err)
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("the CSV file is empty")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		columns[normalize(name)] = i
+	}
+	if _, ok := columns["question"]; !ok {
+		return nil, errors.New("the CSV header needs a question column")
+	}
+	cell := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	file := &importedFile{}
+	for n, row := range rows[1:] {
+		if strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+
+		imported := importedQuestion{
+			Line: n + 2,
+			Request: QuestionRequest{
+				Type:           cell(row, "type"),
+				Question:       cell(row, "question"),
+				Options:        splitList(cell(row, "options")),
+				Answer:         cell(row, "answer"),
+				CorrectAnswers: splitList(cell(row, "correct_answers")),
+				MatchMode:      cell(row, "match_mode"),
+			},
+		}
+		if v := cell(row, "points"); v != "" {
+			points, err := strconv.Atoi(v)
+			if err != nil {
+				imported.Err = fmt.Errorf("points %q is not a whole number", v)
+			}
+			imported.Request.Points = points
+		}
+		if v := cell(row, "tolerance"); v != "" {
+			tolerance, err := parseNumber(v)
+			if err != nil {
+				imported.Err = fmt.Errorf("tolerance %q is not a number", v)
+			}
+			imported.Request.Tolerance = tolerance
+		}
+		file.Questions = append(file.Questions, imported)
+	}
+
+	return file, nil
+}
+
+// writeCSV writes a mission's questions as CSV with a header row
+func writeCSV(mission *Mission) ([]byte, []ImportIssue, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(csvColumns)
+	for _, q := range mission.Questions {
+		w.Write([]string{
+			q.Type,
+			q.Question,
+			strings.Join(q.Options, csvListSeparator),
+			q.Answer,
+			strings.Join(q.CorrectAnswers, csvListSeparator),
+			strconv.Itoa(q.Points),
+			strconv.FormatFloat(q.Tolerance, 'f', -1, 64),
+			q.MatchMode,
+		})
+	}
+	w.Flush()
+	return buf.Bytes(), nil, w.Error()
+}
+
+// splitList splits a cell holding a list
+func splitList(cell string) []string {
+	if cell == "" {
+		return nil
+	}
+	var items []string
+	for _, item := range strings.Split(cell, csvListSeparator) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
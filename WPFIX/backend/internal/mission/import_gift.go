@@ -0,0 +1,457 @@
+package mission
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// parseGIFT reads questions in Moodle's GIFT text format: multiple choice,
+// weighted multiple answers, true/false, short answer, numerical and matching.
+// Questions are separated by blank lines; essays and descriptions are skipped.
+func parseGIFT(data []byte) (*importedFile, error) {
+	text := strings.ReplaceAll(string(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))), "\r\n", "\n")
+
+	file := &importedFile{}
+	var block []string
+	start := 0
+	flush := func() {
+		if len(block) > 0 {
+			file.addGIFTQuestion(strings.Join(block, "\n"), start)
+			block = nil
+		}
+	}
+	for i, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "//"):
+			continue
+		case strings.HasPrefix(trimmed, "$CATEGORY:"):
+			flush()
+			file.Title = categoryTitle(strings.TrimPrefix(trimmed, "$CATEGORY:"))
+			continue
+		case trimmed == "":
+			flush()
+			continue
+		}
+		if len(block) == 0 {
+			start = i + 1
+		}
+		block = append(block, line)
+	}
+	flush()
+
+	if len(file.Questions) == 0 && len(file.Warnings) == 0 {
+		return nil, errors.New("no GIFT questions found")
+	}
+	return file, nil
+}
+
+// addGIFTQuestion reads one GIFT question starting at the given line
+func (f *importedFile) addGIFTQuestion(text string, line int) {
+	text = strings.TrimSpace(text)
+	// Optional ::title::
+	if strings.HasPrefix(text, "::") {
+		if end := indexUnescaped(text[2:], "::"); end >= 0 {
+			text = strings.TrimSpace(text[2+end+2:])
+		}
+	}
+
+	open := indexUnescaped(text, "{")
+	end := lastIndexUnescaped(text, "}")
+	if open < 0 || end < open {
+		f.skip(line, giftUnescape(text), "description without answers")
+		return
+	}
+
+	// A missing word question keeps a blank where its answers were
+	question := strings.TrimSpace(text[:open])
+	if after := strings.TrimSpace(text[end+1:]); after != "" {
+		question += " _____ " + after
+	}
+	question = giftUnescape(stripGIFTFormat(question))
+	body := strings.TrimSpace(text[open+1 : end])
+
+	imported := importedQuestion{Line: line, Request: QuestionRequest{Question: question}}
+	q := &imported.Request
+	switch value := strings.ToUpper(strings.TrimSpace(cutFeedback(body))); {
+	case body == "":
+		f.skip(line, question, "essay questions cannot be graded automatically")
+		return
+
+	case strings.HasPrefix(body, "#"):
+		answer := strings.TrimSpace(body[1:])
+		// Of several accepted numbers the first is kept
+		if strings.HasPrefix(answer, "=") {
+			if answers := giftAnswers(answer); len(answers) > 0 {
+				answer = answers[0].text
+			}
+		}
+		number, tolerance, err := parseGIFTNumber(cutFeedback(answer))
+		if err != nil {
+			imported.Err = err
+		}
+		q.Type = QuestionNumeric
+		q.Answer = strconv.FormatFloat(number, 'f', -1, 64)
+		q.Tolerance = tolerance
+
+	case value == "T" || value == "TRUE" || value == "F" || value == "FALSE":
+		q.Type = QuestionSingleChoice
+		q.Options = []string{"True", "False"}
+		q.Answer = "True"
+		if strings.HasPrefix(value, "F") {
+			q.Answer = "False"
+		}
+
+	default:
+		imported.Err = giftChoices(q, giftAnswers(body))
+	}
+
+	f.Questions = append(f.Questions, imported)
+}
+
+// skip records a GIFT item that is not imported
+func (f *importedFile) skip(line int, text, reason string) {
+	f.Warnings = append(f.Warnings, ImportIssue{Line: line, Text: preview(text), Message: reason + "; skipped"})
+}
+
+// giftAnswer is one = or ~ answer of a GIFT question
+type giftAnswer struct {
+	correct bool     // Marked with =
+	weight  *float64 // Percentage from ~%50%, if given
+	raw     string   // Still escaped, to find the -> of matching pairs
+	text    string
+}
+
+// giftAnswers splits a GIFT answer block into its answers, dropping feedback
+func giftAnswers(body string) []giftAnswer {
+	var answers []giftAnswer
+	var current *giftAnswer
+	var raw strings.Builder
+	flush := func() {
+		if current == nil {
+			return
+		}
+		text := strings.TrimSpace(cutFeedback(raw.String()))
+		if strings.HasPrefix(text, "%") {
+			if end := strings.Index(text[1:], "%"); end >= 0 {
+				if weight, err := strconv.ParseFloat(text[1:end+1], 64); err == nil {
+					current.weight = &weight
+				}
+				text = strings.TrimSpace(text[end+2:])
+			}
+		}
+		current.raw = text
+		current.text = giftUnescape(text)
+		answers = append(answers, *current)
+		raw.Reset()
+	}
+
+	for i := 0; i < len(body); i++ {
+		switch c := body[i]; {
+		case c == '\\' && i+1 < len(body):
+			raw.WriteByte(c)
+			raw.WriteByte(body[i+1])
+			i++
+		case c == '=' || c == '~':
+			flush()
+			current = &giftAnswer{correct: c == '='}
+		default:
+			raw.WriteByte(c)
+		}
+	}
+	flush()
+
+	return answers
+}
+
+// giftChoices turns GIFT answers into a matching, short answer or choice question
+func giftChoices(q *QuestionRequest, answers []giftAnswer) error {
+	if len(answers) == 0 {
+		return errors.New("no answers found")
+	}
+
+	for _, a := range answers {
+		if a.correct && indexUnescaped(a.raw, "->") >= 0 {
+			q.Type = QuestionMatching
+			for _, pair := range answers {
+				arrow := indexUnescaped(pair.raw, "->")
+				if arrow < 0 {
+					return errors.New("every matching answer needs a ->")
+				}
+				prompt := strings.TrimSpace(giftUnescape(pair.raw[:arrow]))
+				if prompt == "" {
+					continue // A distractor with nothing to match
+				}
+				q.Options = append(q.Options, prompt)
+				q.CorrectAnswers = append(q.CorrectAnswers, strings.TrimSpace(giftUnescape(pair.raw[arrow+2:])))
+			}
+			return nil
+		}
+	}
+
+	var correct []string
+	wrong := false
+	for _, a := range answers {
+		full := a.weight == nil || *a.weight >= 100
+		if (a.weight != nil && *a.weight > 0) || (a.weight == nil && a.correct) {
+			correct = append(correct, a.text)
+		}
+		if !a.correct || !full {
+			wrong = true
+		}
+		q.Options = append(q.Options, a.text)
+	}
+	if len(correct) == 0 {
+		return errors.New("no correct answer marked")
+	}
+
+	switch {
+	case !wrong:
+		// Only = answers: any of them is accepted as typed
+		q.Type = QuestionShortText
+		q.Options = nil
+		q.Answer, q.MatchMode = acceptedAnswers(correct, false)
+	case len(correct) == 1:
+		q.Type = QuestionSingleChoice
+		q.Answer = correct[0]
+	default:
+		q.Type = QuestionMultiSelect
+		q.CorrectAnswers = correct
+	}
+	return nil
+}
+
+// acceptedAnswers is the answer key of a short answer accepting any of the
+// given answers: the answer itself, or a pattern when there are several
+func acceptedAnswers(answers []string, caseSensitive bool) (string, string) {
+	if len(answers) == 1 && !caseSensitive {
+		return answers[0], MatchIgnoreCase
+	}
+	quoted := make([]string, len(answers))
+	for i, a := range answers {
+		quoted[i] = regexp.QuoteMeta(a)
+	}
+	pattern := strings.Join(quoted, "|")
+	if !caseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	return pattern, MatchRegex
+}
+
+// parseGIFTNumber reads a GIFT number: 3.14, 3.14:0.01 or the range 3..4
+func parseGIFTNumber(s string) (float64, float64, error) {
+	s = strings.TrimSpace(s)
+	if low, high, ok := strings.Cut(s, ".."); ok {
+		min, err1 := parseNumber(low)
+		max, err2 := parseNumber(high)
+		if err1 != nil || err2 != nil {
+			return 0, 0, fmt.Errorf("invalid numeric range %q", s)
+		}
+		return (min + max) / 2, (max - min) / 2, nil
+	}
+	value, margin, _ := strings.Cut(s, ":")
+	number, err := parseNumber(value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid number %q", value)
+	}
+	tolerance := 0.0
+	if margin != "" {
+		if tolerance, err = parseNumber(margin); err != nil {
+			return 0, 0, fmt.Errorf("invalid tolerance %q", margin)
+		}
+	}
+	return number, tolerance, nil
+}
+
+// writeGIFT writes a mission's questions in GIFT. Points are not part of the
+// format, and questions it cannot express are left as comments.
+func writeGIFT(mission *Mission) ([]byte, []ImportIssue, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// %s\n$CATEGORY: $course$/%s\n\n", oneLine(mission.Title), strings.ReplaceAll(oneLine(mission.Title), "/", "-"))
+
+	var skipped []ImportIssue
+	for i := range mission.Questions {
+		q := &mission.Questions[i]
+		body, err := giftBody(q)
+		if err != nil {
+			skipped = append(skipped, ImportIssue{Question: i + 1, Text: preview(q.Question), Message: err.Error()})
+			fmt.Fprintf(&buf, "// Question %d skipped: %v\n\n", i+1, err)
+			continue
+		}
+		fmt.Fprintf(&buf, "::Q%d:: %s {%s}\n\n", i+1, giftEscape(q.Question), body)
+	}
+
+	return buf.Bytes(), skipped, nil
+}
+
+// giftBody writes the answer block of a question
+func giftBody(q *MissionQuestion) (string, error) {
+	var parts []string
+	switch q.Type {
+	case QuestionNumeric:
+		answer := "#" + strings.TrimSpace(q.Answer)
+		if q.Tolerance > 0 {
+			answer += ":" + strconv.FormatFloat(q.Tolerance, 'f', -1, 64)
+		}
+		return answer, nil
+
+	case QuestionShortText:
+		if q.MatchMode == MatchRegex {
+			return "", errors.New("pattern answers have no GIFT equivalent")
+		}
+		return "=" + giftEscape(q.Answer), nil
+
+	case QuestionMultiSelect:
+		correct := make(map[string]bool)
+		for _, a := range resolveAll(q.Options, q.CorrectAnswers) {
+			correct[a] = true
+		}
+		// Weigh the options as written, which may repeat an answer or miss one
+		right, wrong := 0, 0
+		for _, o := range q.Options {
+			if correct[normalize(o)] {
+				right++
+			} else {
+				wrong++
+			}
+		}
+		if right == 0 {
+			return "", errors.New("no correct answers")
+		}
+		weight := formatWeight(100 / float64(right))
+		penalty := ""
+		if wrong > 0 {
+			penalty = formatWeight(-100 / float64(wrong))
+		}
+		for _, o := range q.Options {
+			if correct[normalize(o)] {
+				parts = append(parts, "~%"+weight+"%"+giftEscape(o))
+			} else {
+				parts = append(parts, "~%"+penalty+"%"+giftEscape(o))
+			}
+		}
+
+	case QuestionMatching:
+		for i, o := range q.Options {
+			if i < len(q.CorrectAnswers) {
+				parts = append(parts, "="+giftEscape(o)+" -> "+giftEscape(q.CorrectAnswers[i]))
+			}
+		}
+
+	case QuestionOrdering:
+		return "", errors.New("ordering questions have no GIFT equivalent")
+
+	default:
+		if len(q.Options) == 0 {
+			return "=" + giftEscape(q.Answer), nil
+		}
+		key := resolveOption(q.Options, q.Answer)
+		for _, o := range q.Options {
+			if strings.EqualFold(strings.TrimSpace(o), key) {
+				parts = append(parts, "="+giftEscape(o))
+			} else {
+				parts = append(parts, "~"+giftEscape(o))
+			}
+		}
+	}
+	return strings.Join(parts, " "), nil
+}
+
+var (
+	giftEscaper   = strings.NewReplacer(`\`, `\\`, "~", `\~`, "=", `\=`, "#", `\#`, "{", `\{`, "}", `\}`, ":", `\:`, "\n", `\n`)
+	giftUnescaper = strings.NewReplacer(`\\`, `\`, `\~`, "~", `\=`, "=", `\#`, "#", `\{`, "{", `\}`, "}", `\:`, ":", `\n`, "\n")
+)
+
+func giftEscape(s string) string {
+	return giftEscaper.Replace(strings.TrimSpace(s))
+}
+
+func giftUnescape(s string) string {
+	return strings.TrimSpace(giftUnescaper.Replace(s))
+}
+
+// stripGIFTFormat drops the [html], [markdown], [plain] or [moodle] marker
+// before a question's text
+func stripGIFTFormat(s string) string {
+	for _, format := range []string{"[html]", "[markdown]", "[plain]", "[moodle]"} {
+		if strings.HasPrefix(s, format) {
+			text := strings.TrimSpace(strings.TrimPrefix(s, format))
+			if format == "[html]" {
+				text = stripHTML(text)
+			}
+			return text
+		}
+	}
+	return s
+}
+
+// cutFeedback drops the #feedback after a GIFT answer
+func cutFeedback(s string) string {
+	if i := indexUnescaped(s, "#"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// indexUnescaped finds sub in s where it is not escaped with a backslash
+func indexUnescaped(s, sub string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(s[i:], sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+func lastIndexUnescaped(s, sub string) int {
+	last := -1
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(s[i:], sub) {
+			last = i
+		}
+	}
+	return last
+}
+
+// formatWeight writes a GIFT answer weight to the 5 decimals Moodle uses
+func formatWeight(w float64) string {
+	return strconv.FormatFloat(math.Round(w*1e5)/1e5, 'f', -1, 64)
+}
+
+// categoryTitle takes a mission title from a Moodle category path such as
+// $course$/Quiz 1
+func categoryTitle(path string) string {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	title := strings.TrimSpace(parts[len(parts)-1])
+	if strings.HasPrefix(title, "$") {
+		return ""
+	}
+	return title
+}
+
+// oneLine joins the lines of s
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// preview shortens a question's text for a report
+func preview(s string) string {
+	s = oneLine(s)
+	if runes := []rune(s); len(runes) > 60 {
+		return string(runes[:57]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,28 @@
+package mission
+
+import "testing"
+
+func TestGiftBodyMultiSelectWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		options JSONOptions
+		correct JSONOptions
+		want    string
+	}{
+		{"some correct", JSONOptions{"2", "4", "7"}, JSONOptions{"2", "7"}, "~%50%2 ~%-100%4 ~%50%7"},
+		{"all correct", JSONOptions{"2", "3"}, JSONOptions{"2", "3"}, "~%50%2 ~%50%3"},
+		{"answer missing from options", JSONOptions{"A", "B", "C"}, JSONOptions{"A", "B", "Z"}, "~%50%A ~%50%B ~%-100%C"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &MissionQuestion{Type: QuestionMultiSelect, Options: tt.options, CorrectAnswers: tt.correct}
+			got, err := giftBody(q)
+			if err != nil {
+				t.Fatalf("giftBody: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package mission
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportMission handles importing a mission from a file
+// @Summary Import mission
+// @Description Import a quiz from CSV, JSON, GIFT or Moodle XML, sent as the multipart field "file" or as the request body. CSV, GIFT and XML hold only questions; the other mission fields come from the form or query. The report lists every problem found; with dry_run=true nothing is created.
+// @Tags Dosen - Missions
+// @Security BearerAuth
+// @Accept multipart/form-data,json,text/csv,text/plain,text/xml
+// @Produce json
+// @Param format query string false "File format, else taken from the file extension" Enums(csv, json, gift, xml)
+// @Param dry_run query bool false "Validate only"
+// @Param file formData file false "File to import"
+// @Param title formData string false "Mission title; defaults to the file's category"
+// @Param description formData string false "Mission description"
+// @Param type formData string false "Mission type" default(quiz)
+// @Param points formData int false "Points reward"
+// @Param pass_threshold formData int false "Auto-approve percentage"
+// @Success 201 {object} utils.Response{data=ImportReport}
+// @Success 200 {object} utils.Response{data=ImportReport}
+// @Failure 400 {object} utils.Response
+// @Failure 422 {object} utils.Response{errors=ImportReport}
+// @Router /dosen/missions/import [post]
+func (h *MissionHandler) ImportMission(c *gin.Context) {
+	dosenID := c.GetUint("user_id")
+
+	data, filename, err := importData(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	format := ImportFormat(c.Query("format"), filename)
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+
+	points, _ := strconv.Atoi(formValue(c, "points"))
+	passThreshold, _ := strconv.Atoi(formValue(c, "pass_threshold"))
+	defaults := CreateMissionRequest{
+		Title:         formValue(c, "title"),
+		Description:   formValue(c, "description"),
+		Type:          formValue(c, "type"),
+		Points:        points,
+		PassThreshold: passThreshold,
+	}
+	if defaults.Type == "" {
+		defaults.Type = "quiz"
+	}
+
+	report, err := h.service.ImportMission(format, data, defaults, dosenID, dryRun)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	switch {
+	case !report.Valid:
+		utils.ErrorResponse(c, http.StatusUnprocessableEntity, fmt.Sprintf("Import has %d problem(s)", len(report.Issues)), report)
+		return
+	case dryRun:
+		utils.SuccessResponse(c, http.StatusOK, "Import is valid", report)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Mission imported successfully", report)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    dosenID,
+		Action:    "IMPORT_MISSION",
+		Entity:    "MISSION",
+		EntityID:  report.Created.ID,
+		Details:   fmt.Sprintf("Dosen imported mission %s with %d questions from %s, %d skipped", report.Created.Title, report.Questions, strings.ToUpper(format), len(report.Warnings)),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// ExportMission handles exporting a mission to a file
+// @Summary Export mission
+// @Description Download a mission's questions, answers included, as CSV, JSON, GIFT or Moodle XML. JSON holds the whole mission. Questions a format cannot express are left out and counted in the X-Export-Skipped header.
+// @Tags Dosen - Missions
+// @Security BearerAuth
+// @Produce json,text/csv,text/plain,text/xml
+// @Param id path int true "Mission ID"
+// @Param format query string false "File format" Enums(csv, json, gift, xml) default(json)
+// @Success 200 {file} file
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /dosen/missions/{id}/export [get]
+func (h *MissionHandler) ExportMission(c *gin.Context) {
+	missionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid mission ID", nil)
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", FormatJSON))
+	data, skipped, err := h.service.ExportMission(uint(missionID), c.GetUint("user_id"), c.GetString("role"), format)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		switch err.Error() {
+		case "mission not found":
+			statusCode = http.StatusNotFound
+		case "you do not own this mission":
+			statusCode = http.StatusForbidden
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	contentTypes := map[string]string{
+		FormatCSV:  "text/csv; charset=utf-8",
+		FormatJSON: "application/json; charset=utf-8",
+		FormatGIFT: "text/plain; charset=utf-8",
+		FormatXML:  "text/xml; charset=utf-8",
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=mission_%d.%s", missionID, format))
+	c.Header("X-Export-Skipped", strconv.Itoa(len(skipped)))
+	c.Data(http.StatusOK, contentTypes[format], data)
+}
+
+// importData reads an imported file from the multipart field "file" or else
+// from the request body
+func importData(c *gin.Context) ([]byte, string, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxImportSize+1<<20)
+
+	var reader io.Reader = c.Request.Body
+	filename := ""
+	if file, header, err := c.Request.FormFile("file"); err == nil {
+		defer file.Close()
+		reader, filename = file, header.Filename
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, MaxImportSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read the file: %v", err)
+	}
+	if len(data) > MaxImportSize {
+		return nil, "", fmt.Errorf("the file is larger than %d MB", MaxImportSize>>20)
+	}
+	if len(data) == 0 {
+		return nil, "", errors.New("no file to import")
+	}
+	return data, filename, nil
+}
+
+// formValue reads a multipart form field, or else the query parameter
+func formValue(c *gin.Context, key string) string {
+	if value := c.PostForm(key); value != "" {
+		return value
+	}
+	return c.Query(key)
+}
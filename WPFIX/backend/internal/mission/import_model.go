@@ -0,0 +1,48 @@
+package mission
+
+// Import and export formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatGIFT = "gift"
+	FormatXML  = "xml" // Moodle XML
+)
+
+// MaxImportSize caps the size of an imported file
+const MaxImportSize = 5 << 20 // 5 MB
+
+// ImportIssue is a problem found in an imported file. Question is the
+// question's position in the file, counting from 1, or 0 for the mission.
+type ImportIssue struct {
+	Question int    `json:"question,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Message  string `json:"message"`
+}
+
+// ImportReport is the validation report of an import. The mission is only
+// created when the file is valid and the import is not a dry run.
+type ImportReport struct {
+	Format    string                `json:"format"`
+	DryRun    bool                  `json:"dry_run"`
+	Valid     bool                  `json:"valid"`
+	Questions int                   `json:"questions"`
+	Issues    []ImportIssue         `json:"issues"`
+	Warnings  []ImportIssue         `json:"warnings"` // Items skipped, such as unsupported question types
+	Mission   *CreateMissionRequest `json:"mission"`  // As read from the file
+	Created   *Mission              `json:"created,omitempty"`
+}
+
+// importedFile is what a question format parser reads from a file
+type importedFile struct {
+	Title     string // From the file's category, if any
+	Questions []importedQuestion
+	Warnings  []ImportIssue
+}
+
+// importedQuestion is one question read from a file, with where it was found
+type importedQuestion struct {
+	Request QuestionRequest
+	Line    int
+	Err     error // Set when the question could not be read
+}
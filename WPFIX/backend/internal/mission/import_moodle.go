@@ -0,0 +1,330 @@
+package mission
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Moodle XML question bank export, as read and written here
+type moodleQuiz struct {
+	XMLName   xml.Name         `xml:"quiz"`
+	Questions []moodleQuestion `xml:"question"`
+}
+
+type moodleText struct {
+	Format string `xml:"format,attr,omitempty"`
+	Text   string `xml:"text"`
+}
+
+type moodleQuestion struct {
+	Type         string              `xml:"type,attr"`
+	Category     *moodleText         `xml:"category,omitempty"`
+	Name         *moodleText         `xml:"name,omitempty"`
+	QuestionText *moodleText         `xml:"questiontext,omitempty"`
+	DefaultGrade string              `xml:"defaultgrade,omitempty"`
+	Single       string              `xml:"single,omitempty"`  // multichoice: true for one answer
+	UseCase      string              `xml:"usecase,omitempty"` // shortanswer: 1 for case sensitive
+	Answers      []moodleAnswer      `xml:"answer"`
+	Subquestions []moodleSubquestion `xml:"subquestion"`
+}
+
+type moodleAnswer struct {
+	Fraction  string `xml:"fraction,attr"`
+	Format    string `xml:"format,attr,omitempty"`
+	Text      string `xml:"text"`
+	Tolerance string `xml:"tolerance,omitempty"`
+}
+
+type moodleSubquestion struct {
+	Format string `xml:"format,attr,omitempty"`
+	Text   string `xml:"text"`
+	Answer struct {
+		Text string `xml:"text"`
+	} `xml:"answer"`
+}
+
+// parseMoodleXML reads questions from a Moodle XML file: multichoice,
+// truefalse, shortanswer, numerical, matching and ordering questions
+func parseMoodleXML(data []byte) (*importedFile, error) {
+	var quiz moodleQuiz
+	if err := xml.Unmarshal(data, &quiz); err != nil {
+		return nil, fmt.Errorf("invalid Moodle XML: %v", err)
+	}
+
+	file := &importedFile{}
+	for i := range quiz.Questions {
+		mq := &quiz.Questions[i]
+		if mq.Type == "category" {
+			if mq.Category != nil {
+				file.Title = categoryTitle(mq.Category.Text)
+			}
+			continue
+		}
+
+		text := ""
+		if mq.QuestionText != nil {
+			text = stripHTML(mq.QuestionText.Text)
+		}
+		imported := importedQuestion{Request: QuestionRequest{Question: text}}
+		q := &imported.Request
+		if grade, err := strconv.ParseFloat(strings.TrimSpace(mq.DefaultGrade), 64); err == nil && grade >= 1 {
+			q.Points = int(math.Round(grade))
+		}
+
+		switch mq.Type {
+		case "multichoice", "truefalse":
+			imported.Err = moodleChoices(q, mq)
+		case "shortanswer":
+			accepted := mq.correctAnswers()
+			if len(accepted) == 0 {
+				imported.Err = errors.New("no full-credit answer")
+				break
+			}
+			q.Type = QuestionShortText
+			q.Answer, q.MatchMode = acceptedAnswers(accepted, mq.UseCase == "1")
+		case "numerical":
+			imported.Err = moodleNumber(q, mq)
+		case "matching":
+			q.Type = QuestionMatching
+			for _, sub := range mq.Subquestions {
+				if prompt := stripHTML(sub.Text); prompt != "" {
+					q.Options = append(q.Options, prompt)
+					q.CorrectAnswers = append(q.CorrectAnswers, strings.TrimSpace(sub.Answer.Text))
+				}
+			}
+		case "ordering":
+			// Answers are listed in the right order
+			q.Type = QuestionOrdering
+			for _, a := range mq.Answers {
+				item := stripHTML(a.Text)
+				q.Options = append(q.Options, item)
+				q.CorrectAnswers = append(q.CorrectAnswers, item)
+			}
+		default:
+			file.Warnings = append(file.Warnings, ImportIssue{
+				Question: i + 1,
+				Text:     preview(text),
+				Message:  fmt.Sprintf("%s questions are not supported; skipped", mq.Type),
+			})
+			continue
+		}
+
+		file.Questions = append(file.Questions, imported)
+	}
+
+	if len(file.Questions) == 0 && len(file.Warnings) == 0 {
+		return nil, errors.New("no Moodle questions found")
+	}
+	return file, nil
+}
+
+// moodleChoices reads a multichoice or truefalse question; answers with a
+// positive fraction are right
+func moodleChoices(q *QuestionRequest, mq *moodleQuestion) error {
+	var correct []string
+	for _, a := range mq.Answers {
+		option := stripHTML(a.Text)
+		if mq.Type == "truefalse" {
+			option = trueFalseOption(option)
+		}
+		q.Options = append(q.Options, option)
+		if fraction, _ := strconv.ParseFloat(a.Fraction, 64); fraction > 0 {
+			correct = append(correct, option)
+		}
+	}
+	if len(correct) == 0 {
+		return errors.New("no correct answer marked")
+	}
+
+	if mq.Type == "truefalse" || (mq.Single != "false" && mq.Single != "0") {
+		q.Type = QuestionSingleChoice
+		q.Answer = mq.correctAnswers()[0]
+		if mq.Type == "truefalse" {
+			q.Answer = trueFalseOption(q.Answer)
+		}
+		return nil
+	}
+	q.Type = QuestionMultiSelect
+	q.CorrectAnswers = correct
+	return nil
+}
+
+// trueFalseOption spells Moodle's true and false answers as the GIFT import does
+func trueFalseOption(s string) string {
+	switch strings.ToLower(s) {
+	case "true":
+		return "True"
+	case "false":
+		return "False"
+	}
+	return s
+}
+
+// moodleNumber reads the full-credit answer of a numerical question
+func moodleNumber(q *QuestionRequest, mq *moodleQuestion) error {
+	for _, a := range mq.Answers {
+		if fraction, _ := strconv.ParseFloat(a.Fraction, 64); fraction < 100 {
+			continue
+		}
+		number, err := parseNumber(a.Text)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", a.Text)
+		}
+		q.Type = QuestionNumeric
+		q.Answer = strconv.FormatFloat(number, 'f', -1, 64)
+		if a.Tolerance != "" {
+			if q.Tolerance, err = parseNumber(a.Tolerance); err != nil {
+				return fmt.Errorf("invalid tolerance %q", a.Tolerance)
+			}
+		}
+		return nil
+	}
+	return errors.New("no full-credit answer")
+}
+
+// correctAnswers lists the answers worth full credit, or the best ones
+func (mq *moodleQuestion) correctAnswers() []string {
+	best := 0.0
+	for _, a := range mq.Answers {
+		if fraction, _ := strconv.ParseFloat(a.Fraction, 64); fraction > best {
+			best = fraction
+		}
+	}
+	var answers []string
+	for _, a := range mq.Answers {
+		if fraction, _ := strconv.ParseFloat(a.Fraction, 64); best > 0 && fraction == best {
+			answers = append(answers, stripHTML(a.Text))
+		}
+	}
+	return answers
+}
+
+// writeMoodleXML writes a mission's questions as Moodle XML in a category
+// named after the mission
+func writeMoodleXML(mission *Mission) ([]byte, []ImportIssue, error) {
+	quiz := moodleQuiz{Questions: []moodleQuestion{{
+		Type:     "category",
+		Category: &moodleText{Text: "$course$/" + strings.ReplaceAll(oneLine(mission.Title), "/", "-")},
+	}}}
+
+	var skipped []ImportIssue
+	for i := range mission.Questions {
+		q := &mission.Questions[i]
+		mq, err := moodleFromQuestion(q, i+1)
+		if err != nil {
+			skipped = append(skipped, ImportIssue{Question: i + 1, Text: preview(q.Question), Message: err.Error()})
+			continue
+		}
+		quiz.Questions = append(quiz.Questions, *mq)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(quiz); err != nil {
+		return nil, nil, err
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), skipped, nil
+}
+
+// moodleFromQuestion converts a question to its Moodle XML form
+func moodleFromQuestion(q *MissionQuestion, number int) (*moodleQuestion, error) {
+	mq := &moodleQuestion{
+		Name:         &moodleText{Text: fmt.Sprintf("Q%d %s", number, preview(q.Question))},
+		QuestionText: &moodleText{Format: "plain_text", Text: q.Question},
+		DefaultGrade: strconv.Itoa(q.Points),
+	}
+	answer := func(fraction float64, text string) moodleAnswer {
+		return moodleAnswer{Fraction: formatWeight(fraction), Format: "plain_text", Text: text}
+	}
+
+	switch q.Type {
+	case QuestionMultiSelect:
+		correct := make(map[string]bool)
+		for _, a := range resolveAll(q.Options, q.CorrectAnswers) {
+			correct[a] = true
+		}
+		if len(correct) == 0 {
+			return nil, errors.New("no correct answers")
+		}
+		mq.Type, mq.Single = "multichoice", "false"
+		for _, o := range q.Options {
+			if correct[normalize(o)] {
+				mq.Answers = append(mq.Answers, answer(100/float64(len(correct)), o))
+			} else {
+				mq.Answers = append(mq.Answers, answer(-100/float64(len(q.Options)-len(correct)), o))
+			}
+		}
+
+	case QuestionNumeric:
+		mq.Type = "numerical"
+		a := answer(100, strings.TrimSpace(q.Answer))
+		a.Format = ""
+		a.Tolerance = strconv.FormatFloat(q.Tolerance, 'f', -1, 64)
+		mq.Answers = []moodleAnswer{a}
+
+	case QuestionShortText:
+		if q.MatchMode == MatchRegex {
+			return nil, errors.New("pattern answers have no Moodle XML equivalent")
+		}
+		mq.Type, mq.UseCase = "shortanswer", "0"
+		mq.Answers = []moodleAnswer{answer(100, q.Answer)}
+
+	case QuestionMatching:
+		mq.Type = "matching"
+		for i, o := range q.Options {
+			if i < len(q.CorrectAnswers) {
+				sub := moodleSubquestion{Format: "plain_text", Text: o}
+				sub.Answer.Text = q.CorrectAnswers[i]
+				mq.Subquestions = append(mq.Subquestions, sub)
+			}
+		}
+
+	case QuestionOrdering:
+		mq.Type = "ordering"
+		for _, item := range resolveAll(q.Options, q.CorrectAnswers) {
+			text := item
+			for _, o := range q.Options {
+				if normalize(o) == item {
+					text = o
+				}
+			}
+			mq.Answers = append(mq.Answers, answer(1, text))
+		}
+
+	default:
+		if len(q.Options) == 0 {
+			mq.Type, mq.UseCase = "shortanswer", "0"
+			mq.Answers = []moodleAnswer{answer(100, q.Answer)}
+			break
+		}
+		mq.Type, mq.Single = "multichoice", "true"
+		key := resolveOption(q.Options, q.Answer)
+		for _, o := range q.Options {
+			fraction := 0.0
+			if strings.EqualFold(strings.TrimSpace(o), key) {
+				fraction = 100
+			}
+			mq.Answers = append(mq.Answers, answer(fraction, o))
+		}
+	}
+
+	return mq, nil
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// stripHTML turns Moodle's HTML question text into plain text
+func stripHTML(s string) string {
+	s = strings.NewReplacer("<br>", "\n", "<br/>", "\n", "<br />", "\n", "</p>", "\n").Replace(s)
+	return strings.TrimSpace(html.UnescapeString(htmlTag.ReplaceAllString(s, "")))
+}
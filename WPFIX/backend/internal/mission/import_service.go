@@ -0,0 +1,227 @@
+package mission
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+// ImportFormat picks the format of an import or export: the one asked for,
+// or else the one of the file's extension
+func ImportFormat(format, filename string) string {
+	if format != "" {
+		return strings.ToLower(format)
+	}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return FormatCSV
+	case ".json":
+		return FormatJSON
+	case ".gift", ".txt":
+		return FormatGIFT
+	case ".xml":
+		return FormatXML
+	}
+	return ""
+}
+
+// ImportMission reads a mission from a file and validates it. CSV, GIFT and
+// Moodle XML files hold only questions, so the mission's other fields come
+// from defaults; a JSON file is a whole CreateMissionRequest, with defaults
+// filling what it leaves blank. Unless dryRun is set, a valid mission is created.
+func (s *MissionService) ImportMission(format string, data []byte, defaults CreateMissionRequest, creatorID uint, dryRun bool) (*ImportReport, error) {
+	req := defaults
+	var file *importedFile
+	var err error
+	switch format {
+	case FormatJSON:
+		file, err = parseMissionJSON(data, &req)
+	case FormatCSV:
+		file, err = parseCSV(data)
+	case FormatGIFT:
+		file, err = parseGIFT(data)
+	case FormatXML:
+		file, err = parseMoodleXML(data)
+	default:
+		return nil, errors.New("unsupported format; use csv, json, gift or xml")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if req.Title == "" {
+		req.Title = file.Title
+	}
+
+	report := &ImportReport{
+		Format:    format,
+		DryRun:    dryRun,
+		Questions: len(file.Questions),
+		Issues:    []ImportIssue{},
+		Warnings:  file.Warnings,
+		Mission:   &req,
+	}
+	if report.Warnings == nil {
+		report.Warnings = []ImportIssue{}
+	}
+	addIssue := func(issue ImportIssue) {
+		report.Issues = append(report.Issues, issue)
+	}
+
+	// The mission itself, checked as its create request would be
+	req.Questions = nil
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		addIssue(ImportIssue{Message: err.Error()})
+	}
+	policy := req.RewardPolicy
+	if policy == "" {
+		policy = RewardFull
+	}
+	if err := validateRewardPolicy(policy, req.RewardTiers); err != nil {
+		addIssue(ImportIssue{Message: err.Error()})
+	}
+	if len(req.PoolRules) > 0 {
+		if _, err := s.newPoolRules(0, creatorID, req.PoolRules); err != nil {
+			addIssue(ImportIssue{Message: err.Error()})
+		}
+	}
+	schedule := &Mission{
+		Deadline:      req.Deadline,
+		PublishAt:     req.PublishAt,
+		CloseAt:       req.CloseAt,
+		Recurrence:    req.Recurrence,
+		RecurrenceEnd: req.RecurrenceEnd,
+	}
+	if err := schedule.validateSchedule(); err != nil {
+		addIssue(ImportIssue{Message: err.Error()})
+	}
+	if _, err := s.newMissionClasses(0, creatorID, req.ClassIDs); err != nil {
+		addIssue(ImportIssue{Message: err.Error()})
+	}
+	if req.Type != "quiz" && len(file.Questions) > 0 {
+		addIssue(ImportIssue{Message: "only quiz missions have questions"})
+	}
+
+	// Each question, checked as CreateMission will
+	for i, q := range file.Questions {
+		issue := ImportIssue{Question: i + 1, Line: q.Line, Text: preview(q.Request.Question)}
+		if q.Err != nil {
+			issue.Message = q.Err.Error()
+			addIssue(issue)
+		} else if strings.TrimSpace(q.Request.Question) == "" {
+			issue.Message = "question text is required"
+			addIssue(issue)
+		} else if _, err := newQuestion(0, q.Request); err != nil {
+			issue.Message = err.Error()
+			addIssue(issue)
+		}
+		req.Questions = append(req.Questions, q.Request)
+	}
+
+	report.Valid = len(report.Issues) == 0
+	if !report.Valid || dryRun {
+		return report, nil
+	}
+
+	mission, err := s.CreateMission(&req, creatorID)
+	if err != nil {
+		return nil, err
+	}
+	report.Created = mission
+
+	return report, nil
+}
+
+// parseMissionJSON reads a CreateMissionRequest over the defaults in req
+func parseMissionJSON(data []byte, req *CreateMissionRequest) (*importedFile, error) {
+	if err := json.Unmarshal(data, req); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+
+	file := &importedFile{}
+	for _, q := range req.Questions {
+		q.ID = 0
+		file.Questions = append(file.Questions, importedQuestion{Request: q})
+	}
+	return file, nil
+}
+
+// ExportMission writes a mission and its fixed questions in the given format.
+// It also returns the questions the format could not express.
+func (s *MissionService) ExportMission(id, userID uint, role, format string) ([]byte, []ImportIssue, error) {
+	mission, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The export carries the answer key, so only its creator or an admin may
+	// take it
+	if role != "admin" && mission.CreatorID != userID {
+		return nil, nil, errors.New("you do not own this mission")
+	}
+
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(missionRequest(mission), "", "  ")
+		return data, nil, err
+	case FormatCSV:
+		return writeCSV(mission)
+	case FormatGIFT:
+		return writeGIFT(mission)
+	case FormatXML:
+		return writeMoodleXML(mission)
+	}
+	return nil, nil, errors.New("unsupported format; use csv, json, gift or xml")
+}
+
+// missionRequest is the create request that would make a copy of a mission
+func missionRequest(mission *Mission) *CreateMissionRequest {
+	maxAttempts := mission.MaxAttempts
+	req := &CreateMissionRequest{
+		Title:             mission.Title,
+		Description:       mission.Description,
+		Type:              mission.Type,
+		Points:            mission.Points,
+		Deadline:          mission.Deadline,
+		PassThreshold:     mission.PassThreshold,
+		RewardPolicy:      mission.RewardPolicy,
+		RewardTiers:       mission.RewardTiers,
+		EarlyBonusPercent: mission.EarlyBonusPercent,
+		EarlyBonusUntil:   mission.EarlyBonusUntil,
+		MaxAttempts:       &maxAttempts,
+		ScoringMethod:     mission.ScoringMethod,
+		TimeLimitMinutes:  mission.TimeLimitMinutes,
+		ShuffleQuestions:  mission.ShuffleQuestions,
+		ShuffleOptions:    mission.ShuffleOptions,
+		PublishAt:         mission.PublishAt,
+		CloseAt:           mission.CloseAt,
+		Recurrence:        mission.Recurrence,
+		RecurrenceEnd:     mission.RecurrenceEnd,
+	}
+	for _, r := range mission.PoolRules {
+		req.PoolRules = append(req.PoolRules, PoolRuleRequest{
+			Count:      r.Count,
+			Tag:        r.Tag,
+			Difficulty: r.Difficulty,
+			Course:     r.Course,
+		})
+	}
+	for _, c := range mission.Classes {
+		req.ClassIDs = append(req.ClassIDs, c.ClassID)
+	}
+	for _, q := range mission.Questions {
+		req.Questions = append(req.Questions, QuestionRequest{
+			Type:           q.Type,
+			Question:       q.Question,
+			Options:        q.Options,
+			Answer:         q.Answer,
+			Points:         q.Points,
+			CorrectAnswers: q.CorrectAnswers,
+			Tolerance:      q.Tolerance,
+			MatchMode:      q.MatchMode,
+		})
+	}
+	return req
+}
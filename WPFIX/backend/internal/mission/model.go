@@ -14,6 +14,10 @@ func (jo JSONOptions) Value() (driver.Value, error) {
 }
 
 func (jo *JSONOptions) Scan(value interface{}) error {
+	if value == nil {
+		*jo = nil
+		return nil
+	}
 	bytes, ok := value.([]byte)
 	if !ok {
 		return errors.New("type assertion to []byte failed")
@@ -22,25 +26,67 @@ func (jo *JSONOptions) Scan(value interface{}) error {
 }
 
 type Mission struct {
-	ID          uint              `json:"id" gorm:"primaryKey"`
-	CreatorID   uint              `json:"creator_id" gorm:"column:creator_id;not null"`
-	Title       string            `json:"title" gorm:"not null"`
-	Description string            `json:"description" gorm:"type:text"`
-	Type        string            `json:"type" gorm:"type:enum('quiz','task','assignment');not null"`
-	Points      int               `json:"points" gorm:"column:points_reward;not null"`
-	Deadline    *time.Time        `json:"deadline" gorm:"column:deadline"`
-	Status      string            `json:"status" gorm:"type:enum('active','inactive','expired');default:'active'"`
-	Questions   []MissionQuestion `json:"questions,omitempty" gorm:"foreignKey:MissionID;constraint:OnDelete:CASCADE"`
-	CreatedAt   time.Time         `json:"created_at"`
-	UpdatedAt   time.Time         `json:"updated_at"`
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	CreatorID   uint       `json:"creator_id" gorm:"column:creator_id;not null"`
+	Title       string     `json:"title" gorm:"not null"`
+	Description string     `json:"description" gorm:"type:text"`
+	Type        string     `json:"type" gorm:"type:enum('quiz','task','assignment');not null"`
+	Points      int        `json:"points" gorm:"column:points_reward;not null"`
+	Deadline    *time.Time `json:"deadline" gorm:"column:deadline"`
+	Status      string     `json:"status" gorm:"type:enum('active','inactive','expired','scheduled','recurring');default:'active'"`
+	// Publish window: scheduled until PublishAt, expired from CloseAt, or from
+	// the Deadline when CloseAt is unset; see RunSchedule
+	PublishAt *time.Time `json:"publish_at"`
+	CloseAt   *time.Time `json:"close_at"`
+	// A recurring mission is a template for its first instance, spawned again
+	// every Recurrence until RecurrenceEnd; see spawnInstances. On the template
+	// Occurrence counts the instances spawned, on an instance it is its number.
+	Recurrence    string     `json:"recurrence,omitempty" gorm:"size:10"`
+	RecurrenceEnd *time.Time `json:"recurrence_end,omitempty"`
+	NextRunAt     *time.Time `json:"next_run_at,omitempty" gorm:"index"`
+	TemplateID    *uint      `json:"template_id,omitempty" gorm:"index"`
+	Occurrence    int        `json:"occurrence" gorm:"default:0;not null"`
+	// Quiz percentage that approves a submission automatically; 0 leaves every submission for review
+	PassThreshold int `json:"pass_threshold" gorm:"default:0;not null"`
+	// How approved submissions are paid; see CalculateReward
+	RewardPolicy      string      `json:"reward_policy" gorm:"type:enum('full','proportional','tiered');default:'full';not null"`
+	RewardTiers       RewardTiers `json:"reward_tiers,omitempty" gorm:"type:json"`
+	EarlyBonusPercent int         `json:"early_bonus_percent" gorm:"default:0;not null"`
+	EarlyBonusUntil   *time.Time  `json:"early_bonus_until"` // Submissions before this time earn the bonus
+	// Attempts a student may make (0 for unlimited) and which of them counts
+	MaxAttempts   int    `json:"max_attempts" gorm:"default:1;not null"`
+	ScoringMethod string `json:"scoring_method" gorm:"type:enum('latest','best');default:'latest';not null"`
+	// Quizzes taken in a timed attempt session; see StartAttempt
+	TimeLimitMinutes int  `json:"time_limit_minutes" gorm:"default:0;not null"` // 0 for no limit
+	ShuffleQuestions bool `json:"shuffle_questions" gorm:"default:false"`
+	ShuffleOptions   bool `json:"shuffle_options" gorm:"default:false"`
+	// Questions drawn from the creator's bank for each attempt, besides the fixed Questions
+	PoolRules []PoolRule `json:"pool_rules,omitempty" gorm:"foreignKey:MissionID;constraint:OnDelete:CASCADE"`
+	// Classes whose students may take the mission; a mission with none is open to every student
+	Classes   []MissionClass    `json:"classes,omitempty" gorm:"foreignKey:MissionID;constraint:OnDelete:CASCADE"`
+	Questions []MissionQuestion `json:"questions,omitempty" gorm:"foreignKey:MissionID;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
 }
 
 type MissionQuestion struct {
 	ID        uint        `json:"id" gorm:"primaryKey"`
 	MissionID uint        `json:"mission_id" gorm:"not null;index"`
+	Type      string      `json:"type" gorm:"type:enum('single_choice','multi_select','numeric','short_text','ordering','matching');default:'single_choice';not null"`
 	Question  string      `json:"question" gorm:"type:text;not null"`
 	Options   JSONOptions `json:"options" gorm:"type:json"` // Array of strings (options)
 	Answer    string      `json:"answer" gorm:"not null"`   // Correct answer or index
+	Points    int         `json:"points" gorm:"default:1;not null"`
+
+	// Answer key of the typed questions; see the graders
+	CorrectAnswers JSONOptions `json:"correct_answers,omitempty" gorm:"type:json"`
+	Tolerance      float64     `json:"tolerance" gorm:"default:0"`          // numeric
+	MatchMode      string      `json:"match_mode,omitempty" gorm:"size:20"` // short_text: ignore_case or regex
+
+	// Set on questions drawn from the bank for one attempt; a mission's fixed
+	// questions have no session
+	SessionID      *uint `json:"session_id,omitempty" gorm:"index"`
+	BankQuestionID *uint `json:"bank_question_id,omitempty"`
 }
 
 func (MissionQuestion) TableName() string {
@@ -52,17 +98,27 @@ func (Mission) TableName() string {
 }
 
 type MissionSubmission struct {
-	ID         uint      `json:"id" gorm:"primaryKey"`
-	MissionID  uint      `json:"mission_id" gorm:"not null;index"`
-	StudentID  uint      `json:"student_id" gorm:"not null;index"`
-	Content    string    `json:"content" gorm:"column:submission_content;type:text"`
-	FileURL    string    `json:"file_url" gorm:"size:500"`
-	Score      int       `json:"score" gorm:"default:0"` // Will be added by AutoMigrate
-	Status     string    `json:"status" gorm:"type:enum('pending','approved','rejected');default:'pending'"`
-	ReviewedBy *uint     `json:"reviewed_by" gorm:"column:validated_by"`
-	ReviewNote string    `json:"review_note" gorm:"column:validation_note;type:text"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID        uint `json:"id" gorm:"primaryKey"`
+	MissionID uint `json:"mission_id" gorm:"not null;index;uniqueIndex:idx_submission_attempt"`
+	StudentID uint `json:"student_id" gorm:"not null;index;uniqueIndex:idx_submission_attempt"`
+	// Attempt number of this submission, counting from 1
+	Attempt int    `json:"attempt" gorm:"default:1;not null;uniqueIndex:idx_submission_attempt"`
+	Counted bool   `json:"counted" gorm:"default:true"` // The attempt that counts under the mission's scoring method
+	Content string `json:"content" gorm:"column:submission_content;type:text"`
+	FileURL string `json:"file_url" gorm:"size:500"`
+	Score   int    `json:"score" gorm:"default:0"` // Will be added by AutoMigrate
+	// Auto-grading of quiz answers: Score points (rounded) out of MaxScore
+	MaxScore   int          `json:"max_score" gorm:"default:0"`
+	Percentage float64      `json:"percentage" gorm:"type:decimal(5,2);default:0"`
+	AutoGraded bool         `json:"auto_graded" gorm:"default:false"`
+	Feedback   QuizFeedback `json:"feedback,omitempty" gorm:"type:json"`
+	// Points paid out on approval under the mission's reward policy
+	RewardPoints int       `json:"reward_points" gorm:"default:0"`
+	Status       string    `json:"status" gorm:"type:enum('pending','approved','rejected');default:'pending'"`
+	ReviewedBy   *uint     `json:"reviewed_by" gorm:"column:validated_by"`
+	ReviewNote   string    `json:"review_note" gorm:"column:validation_note;type:text"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
 
 func (MissionSubmission) TableName() string {
@@ -70,27 +126,64 @@ func (MissionSubmission) TableName() string {
 }
 
 type CreateMissionRequest struct {
-	Title       string            `json:"title" binding:"required"`
-	Description string            `json:"description"`
-	Type        string            `json:"type" binding:"required,oneof=quiz task assignment"`
-	Points      int               `json:"points" binding:"required,gt=0"`
-	Deadline    *time.Time        `json:"deadline"`
-	Questions   []QuestionRequest `json:"questions"`
+	Title             string            `json:"title" binding:"required"`
+	Description       string            `json:"description"`
+	Type              string            `json:"type" binding:"required,oneof=quiz task assignment"`
+	Points            int               `json:"points" binding:"required,gt=0"`
+	Deadline          *time.Time        `json:"deadline"`
+	PassThreshold     int               `json:"pass_threshold" binding:"omitempty,gte=0,lte=100"`
+	RewardPolicy      string            `json:"reward_policy" binding:"omitempty,oneof=full proportional tiered"`
+	RewardTiers       []RewardTier      `json:"reward_tiers" binding:"omitempty,dive"`
+	EarlyBonusPercent int               `json:"early_bonus_percent" binding:"omitempty,gte=0,lte=100"`
+	EarlyBonusUntil   *time.Time        `json:"early_bonus_until"`
+	MaxAttempts       *int              `json:"max_attempts" binding:"omitempty,gte=0"` // Defaults to 1; 0 for unlimited
+	ScoringMethod     string            `json:"scoring_method" binding:"omitempty,oneof=latest best"`
+	TimeLimitMinutes  int               `json:"time_limit_minutes" binding:"omitempty,gte=0"`
+	ShuffleQuestions  bool              `json:"shuffle_questions"`
+	ShuffleOptions    bool              `json:"shuffle_options"`
+	PoolRules         []PoolRuleRequest `json:"pool_rules" binding:"omitempty,dive"`
+	PublishAt         *time.Time        `json:"publish_at"` // Scheduled until then; required for recurring missions
+	CloseAt           *time.Time        `json:"close_at"`
+	Recurrence        string            `json:"recurrence" binding:"omitempty,oneof=daily weekly monthly"`
+	RecurrenceEnd     *time.Time        `json:"recurrence_end"`
+	ClassIDs          []uint            `json:"class_ids"` // Classes of the creator; none opens the mission to every student
+	Questions         []QuestionRequest `json:"questions"`
 }
 
 type QuestionRequest struct {
-	Question string   `json:"question" binding:"required"`
-	Options  []string `json:"options"`
-	Answer   string   `json:"answer" binding:"required"`
+	ID             uint     `json:"id"` // Updates this question of the mission in place
+	Type           string   `json:"type" binding:"omitempty,oneof=single_choice multi_select numeric short_text ordering matching"`
+	Question       string   `json:"question" binding:"required"`
+	Options        []string `json:"options"`
+	Answer         string   `json:"answer"`
+	Points         int      `json:"points" binding:"omitempty,gt=0"` // Defaults to 1
+	CorrectAnswers []string `json:"correct_answers"`
+	Tolerance      float64  `json:"tolerance" binding:"gte=0"`
+	MatchMode      string   `json:"match_mode" binding:"omitempty,oneof=ignore_case regex"`
 }
 
 type UpdateMissionRequest struct {
-	Title       string            `json:"title,omitempty"`
-	Description string            `json:"description,omitempty"`
-	Points      int               `json:"points,omitempty" binding:"omitempty,gt=0"`
-	Deadline    *time.Time        `json:"deadline,omitempty"`
-	Status      string            `json:"status,omitempty" binding:"omitempty,oneof=active inactive expired"`
-	Questions   []QuestionRequest `json:"questions,omitempty"`
+	Title             string            `json:"title,omitempty"`
+	Description       string            `json:"description,omitempty"`
+	Points            int               `json:"points,omitempty" binding:"omitempty,gt=0"`
+	Deadline          *time.Time        `json:"deadline,omitempty"`
+	Status            string            `json:"status,omitempty" binding:"omitempty,oneof=active inactive expired recurring"`
+	PublishAt         *time.Time        `json:"publish_at,omitempty"`
+	CloseAt           *time.Time        `json:"close_at,omitempty"`
+	RecurrenceEnd     *time.Time        `json:"recurrence_end,omitempty"`
+	PassThreshold     *int              `json:"pass_threshold,omitempty" binding:"omitempty,gte=0,lte=100"`
+	RewardPolicy      string            `json:"reward_policy,omitempty" binding:"omitempty,oneof=full proportional tiered"`
+	RewardTiers       []RewardTier      `json:"reward_tiers,omitempty" binding:"omitempty,dive"`
+	EarlyBonusPercent *int              `json:"early_bonus_percent,omitempty" binding:"omitempty,gte=0,lte=100"`
+	EarlyBonusUntil   *time.Time        `json:"early_bonus_until,omitempty"`
+	MaxAttempts       *int              `json:"max_attempts,omitempty" binding:"omitempty,gte=0"`
+	ScoringMethod     string            `json:"scoring_method,omitempty" binding:"omitempty,oneof=latest best"`
+	TimeLimitMinutes  *int              `json:"time_limit_minutes,omitempty" binding:"omitempty,gte=0"`
+	ShuffleQuestions  *bool             `json:"shuffle_questions,omitempty"`
+	ShuffleOptions    *bool             `json:"shuffle_options,omitempty"`
+	PoolRules         []PoolRuleRequest `json:"pool_rules,omitempty" binding:"omitempty,dive"` // Replaces the rules; [] removes them
+	ClassIDs          []uint            `json:"class_ids,omitempty"`                           // Replaces the classes; [] opens the mission to every student
+	Questions         []QuestionRequest `json:"questions,omitempty"`                           // Upserted by ID; questions left out are removed
 }
 
 type SubmitMissionRequest struct {
@@ -101,13 +194,14 @@ type SubmitMissionRequest struct {
 }
 
 type AnswerSubmission struct {
-	QuestionID uint   `json:"question_id"`
-	Answer     string `json:"answer"`
+	QuestionID uint     `json:"question_id"`
+	Answer     string   `json:"answer"`
+	Answers    []string `json:"answers,omitempty"` // multi_select choices, ordering in order, matching per option
 }
 
 type ReviewSubmissionRequest struct {
 	Status     string `json:"status" binding:"required,oneof=approved rejected"`
-	Score      int    `json:"score" binding:"gte=0"`
+	Score      int    `json:"score" binding:"gte=0"` // Out of 100, higher scores pay as 100; 0 keeps an auto-graded quiz score
 	ReviewNote string `json:"review_note"`
 }
 
@@ -126,11 +220,14 @@ type SubmissionWithDetails struct {
 }
 
 type MissionListParams struct {
-	Type      string
-	Status    string
-	CreatedBy uint
-	Page      int
-	Limit     int
+	Type       string
+	Status     string
+	CreatedBy  uint
+	ClassID    uint
+	TemplateID uint // Instances of this recurring mission
+	StudentID  uint // Only missions open to this student
+	Page       int
+	Limit      int
 }
 
 type MissionListResponse struct {
@@ -145,6 +242,7 @@ type SubmissionListParams struct {
 	MissionID uint
 	StudentID uint
 	CreatorID uint // Filter submissions for missions created by this user
+	ClassID   uint // Submissions by the class's students to missions assigned to it
 	Status    string
 	Page      int
 	Limit     int
@@ -159,6 +257,8 @@ type SubmissionListResponse struct {
 }
 
 type DosenStatsResponse struct {
+	ClassID        uint  `json:"class_id,omitempty"`
+	Students       int64 `json:"students,omitempty"` // Enrolled in the class
 	TotalMissions  int64 `json:"total_missions"`
 	PendingReviews int64 `json:"pending_reviews"`
 	ValidatedTasks int64 `json:"validated_tasks"`
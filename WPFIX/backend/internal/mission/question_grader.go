@@ -0,0 +1,313 @@
+package mission
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Question types
+const (
+	QuestionSingleChoice = "single_choice"
+	QuestionMultiSelect  = "multi_select"
+	QuestionNumeric      = "numeric"
+	QuestionShortText    = "short_text"
+	QuestionOrdering     = "ordering"
+	QuestionMatching     = "matching"
+)
+
+// Short text match modes
+const (
+	MatchIgnoreCase = "ignore_case"
+	MatchRegex      = "regex"
+)
+
+// Grader validates and grades one type of quiz question
+type Grader interface {
+	// Validate checks the answer key of a question being created or updated
+	Validate(q *QuestionRequest) error
+	// Grade returns the share of the question's points earned, from 0 to 1
+	Grade(q *MissionQuestion, answer AnswerSubmission) float64
+}
+
+var graders = map[string]Grader{
+	QuestionSingleChoice: singleChoiceGrader{},
+	QuestionMultiSelect:  multiSelectGrader{},
+	QuestionNumeric:      numericGrader{},
+	QuestionShortText:    shortTextGrader{},
+	QuestionOrdering:     orderingGrader{},
+	QuestionMatching:     matchingGrader{},
+}
+
+// graderFor returns the grader of a question type, single choice by default
+func graderFor(questionType string) Grader {
+	if g, ok := graders[questionType]; ok {
+		return g
+	}
+	return graders[QuestionSingleChoice]
+}
+
+// newQuestion validates a question request and builds the question
+func newQuestion(missionID uint, req QuestionRequest) (MissionQuestion, error) {
+	if req.Type == "" {
+		req.Type = QuestionSingleChoice
+	}
+	if err := graderFor(req.Type).Validate(&req); err != nil {
+		return MissionQuestion{}, fmt.Errorf("question %q: %w", req.Question, err)
+	}
+
+	points := req.Points
+	if points <= 0 {
+		points = 1
+	}
+	matchMode := req.MatchMode
+	if req.Type == QuestionShortText && matchMode == "" {
+		matchMode = MatchIgnoreCase
+	}
+
+	return MissionQuestion{
+		MissionID:      missionID,
+		Type:           req.Type,
+		Question:       req.Question,
+		Options:        req.Options,
+		Answer:         req.Answer,
+		Points:         points,
+		CorrectAnswers: req.CorrectAnswers,
+		Tolerance:      req.Tolerance,
+		MatchMode:      matchMode,
+	}, nil
+}
+
+// normalize compares answers without case or surrounding spaces
+func normalize(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// resolveAll turns option indexes into option texts
+func resolveAll(options JSONOptions, values []string) []string {
+	resolved := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			resolved = append(resolved, normalize(resolveOption(options, v)))
+		}
+	}
+	return resolved
+}
+
+// hasOption reports whether value names one of the options, by text or index
+func hasOption(options []string, value string) bool {
+	if index, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && index >= 0 && index < len(options) {
+		return true
+	}
+	return hasOptionText(options, value)
+}
+
+// hasOptionText reports whether value is the text of one of the options
+func hasOptionText(options []string, value string) bool {
+	value = strings.TrimSpace(value)
+	for _, o := range options {
+		if strings.EqualFold(strings.TrimSpace(o), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// singleChoiceGrader: Answer is one of the Options, by text or index
+type singleChoiceGrader struct{}
+
+func (singleChoiceGrader) Validate(q *QuestionRequest) error {
+	if strings.TrimSpace(q.Answer) == "" {
+		return errors.New("answer is required")
+	}
+	if len(q.Options) > 0 && !hasOption(q.Options, q.Answer) {
+		return errors.New("answer must be one of the options")
+	}
+	return nil
+}
+
+func (singleChoiceGrader) Grade(q *MissionQuestion, answer AnswerSubmission) float64 {
+	if isCorrectAnswer(q, answer.Answer) {
+		return 1
+	}
+	return 0
+}
+
+// multiSelectGrader: CorrectAnswers lists every right option. Each right
+// choice earns its share and each wrong choice takes one away, never below zero.
+type multiSelectGrader struct{}
+
+func (multiSelectGrader) Validate(q *QuestionRequest) error {
+	if len(q.Options) < 2 {
+		return errors.New("multi_select needs at least two options")
+	}
+	if len(q.CorrectAnswers) == 0 {
+		return errors.New("correct_answers is required")
+	}
+	for _, a := range q.CorrectAnswers {
+		if !hasOption(q.Options, a) {
+			return fmt.Errorf("correct answer %q is not one of the options", a)
+		}
+	}
+	return nil
+}
+
+func (multiSelectGrader) Grade(q *MissionQuestion, answer AnswerSubmission) float64 {
+	correct := make(map[string]bool)
+	for _, a := range resolveAll(q.Options, q.CorrectAnswers) {
+		correct[a] = true
+	}
+	if len(correct) == 0 {
+		return 0
+	}
+
+	chosen := make(map[string]bool)
+	hits, misses := 0, 0
+	for _, a := range resolveAll(q.Options, answer.Answers) {
+		if chosen[a] {
+			continue
+		}
+		chosen[a] = true
+		if correct[a] {
+			hits++
+		} else {
+			misses++
+		}
+	}
+
+	return math.Max(0, float64(hits-misses)/float64(len(correct)))
+}
+
+// numericGrader: Answer is a number, matched within Tolerance
+type numericGrader struct{}
+
+// parseNumber accepts a decimal comma as well as a point
+func parseNumber(s string) (float64, error) {
+	return strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(s), ",", "."), 64)
+}
+
+func (numericGrader) Validate(q *QuestionRequest) error {
+	if _, err := parseNumber(q.Answer); err != nil {
+		return errors.New("answer must be a number")
+	}
+	return nil
+}
+
+func (numericGrader) Grade(q *MissionQuestion, answer AnswerSubmission) float64 {
+	expected, err := parseNumber(q.Answer)
+	if err != nil {
+		return 0
+	}
+	given, err := parseNumber(answer.Answer)
+	if err != nil {
+		return 0
+	}
+	// A small epsilon keeps answers exactly on the tolerance edge correct
+	if math.Abs(given-expected) <= q.Tolerance+1e-9 {
+		return 1
+	}
+	return 0
+}
+
+// shortTextGrader: Answer matched ignoring case, or a regular expression the
+// whole answer must match
+type shortTextGrader struct{}
+
+func (shortTextGrader) Validate(q *QuestionRequest) error {
+	if strings.TrimSpace(q.Answer) == "" {
+		return errors.New("answer is required")
+	}
+	if q.MatchMode == MatchRegex {
+		if _, err := regexp.Compile(q.Answer); err != nil {
+			return fmt.Errorf("invalid answer pattern: %v", err)
+		}
+	}
+	return nil
+}
+
+func (shortTextGrader) Grade(q *MissionQuestion, answer AnswerSubmission) float64 {
+	given := strings.TrimSpace(answer.Answer)
+	if given == "" {
+		return 0
+	}
+
+	if q.MatchMode == MatchRegex {
+		pattern, err := regexp.Compile("^(?:" + q.Answer + ")$")
+		if err == nil && pattern.MatchString(given) {
+			return 1
+		}
+		return 0
+	}
+
+	if normalize(given) == normalize(q.Answer) {
+		return 1
+	}
+	return 0
+}
+
+// orderingGrader: CorrectAnswers is the Options in the right order. Each item
+// in its right place earns its share. Students are shown the items sorted, so
+// an answer naming items by index counts from that sorted list.
+type orderingGrader struct{}
+
+func (orderingGrader) Validate(q *QuestionRequest) error {
+	if len(q.Options) < 2 {
+		return errors.New("ordering needs at least two items")
+	}
+	if len(q.CorrectAnswers) != len(q.Options) {
+		return errors.New("correct_answers must list every option in order")
+	}
+	for _, a := range q.CorrectAnswers {
+		if !hasOption(q.Options, a) {
+			return fmt.Errorf("correct answer %q is not one of the options", a)
+		}
+	}
+	return nil
+}
+
+func (orderingGrader) Grade(q *MissionQuestion, answer AnswerSubmission) float64 {
+	return positionalShare(resolveAll(q.Options, q.CorrectAnswers), resolveAll(sortedOptions(q.Options), answer.Answers))
+}
+
+// matchingGrader: Options are the prompts and CorrectAnswers[i] is the match
+// for Options[i]. The student answers one match per prompt.
+type matchingGrader struct{}
+
+func (matchingGrader) Validate(q *QuestionRequest) error {
+	if len(q.Options) < 2 {
+		return errors.New("matching needs at least two pairs")
+	}
+	if len(q.CorrectAnswers) != len(q.Options) {
+		return errors.New("correct_answers must give one match per option")
+	}
+	return nil
+}
+
+func (matchingGrader) Grade(q *MissionQuestion, answer AnswerSubmission) float64 {
+	key := make([]string, len(q.CorrectAnswers))
+	for i, a := range q.CorrectAnswers {
+		key[i] = normalize(a)
+	}
+	given := make([]string, len(answer.Answers))
+	for i, a := range answer.Answers {
+		given[i] = normalize(a)
+	}
+	return positionalShare(key, given)
+}
+
+// positionalShare is the share of key positions the answer got right
+func positionalShare(key, given []string) float64 {
+	if len(key) == 0 {
+		return 0
+	}
+	right := 0
+	for i := range key {
+		if i < len(given) && given[i] == key[i] {
+			right++
+		}
+	}
+	return float64(right) / float64(len(key))
+}
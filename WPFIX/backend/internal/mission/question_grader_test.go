@@ -0,0 +1,141 @@
+package mission
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestGraders(t *testing.T) {
+	singleChoice := &MissionQuestion{Type: QuestionSingleChoice, Options: JSONOptions{"Paris", "Berlin", "Amsterdam"}, Answer: "Paris"}
+	numericChoice := &MissionQuestion{Type: QuestionSingleChoice, Options: JSONOptions{"3", "2"}, Answer: "2"}
+	multiSelect := &MissionQuestion{Type: QuestionMultiSelect, Options: JSONOptions{"7", "4", "2", "9"}, CorrectAnswers: JSONOptions{"2", "7"}}
+	numeric := &MissionQuestion{Type: QuestionNumeric, Answer: "3.14", Tolerance: 0.01}
+	exact := &MissionQuestion{Type: QuestionNumeric, Answer: "42"}
+	shortText := &MissionQuestion{Type: QuestionShortText, Answer: "Go", MatchMode: MatchIgnoreCase}
+	pattern := &MissionQuestion{Type: QuestionShortText, Answer: "colou?r", MatchMode: MatchRegex}
+	alternation := &MissionQuestion{Type: QuestionShortText, Answer: "cat|dog", MatchMode: MatchRegex}
+	ordering := &MissionQuestion{Type: QuestionOrdering, Options: JSONOptions{"Mercury", "Venus", "Earth"}, CorrectAnswers: JSONOptions{"Mercury", "Venus", "Earth"}}
+	matching := &MissionQuestion{Type: QuestionMatching, Options: JSONOptions{"Japan", "Italy"}, CorrectAnswers: JSONOptions{"Tokyo", "Rome"}}
+
+	tests := []struct {
+		name     string
+		question *MissionQuestion
+		answer   AnswerSubmission
+		want     float64
+	}{
+		{"single choice by text", singleChoice, AnswerSubmission{Answer: " paris "}, 1},
+		{"single choice by index", singleChoice, AnswerSubmission{Answer: "0"}, 1},
+		{"single choice wrong", singleChoice, AnswerSubmission{Answer: "Berlin"}, 0},
+		{"single choice unanswered", singleChoice, AnswerSubmission{}, 0},
+		{"numeric option read as text", numericChoice, AnswerSubmission{Answer: "2"}, 1},
+		{"numeric option by index", numericChoice, AnswerSubmission{Answer: "1"}, 1},
+
+		{"multi select all right", multiSelect, AnswerSubmission{Answers: []string{"7", "2"}}, 1},
+		{"multi select half right", multiSelect, AnswerSubmission{Answers: []string{"2"}}, 0.5},
+		{"multi select wrong choice cancels a right one", multiSelect, AnswerSubmission{Answers: []string{"2", "4"}}, 0},
+		{"multi select every option", multiSelect, AnswerSubmission{Answers: []string{"7", "4", "2", "9"}}, 0},
+		{"multi select all right plus one wrong", multiSelect, AnswerSubmission{Answers: []string{"2", "7", "9"}}, 0.5},
+		{"multi select never below zero", multiSelect, AnswerSubmission{Answers: []string{"4", "9"}}, 0},
+		{"multi select repeated choice", multiSelect, AnswerSubmission{Answers: []string{"2", "2", " 2 "}}, 0.5},
+		{"multi select unanswered", multiSelect, AnswerSubmission{}, 0},
+
+		{"numeric exact", numeric, AnswerSubmission{Answer: "3.14"}, 1},
+		{"numeric within tolerance", numeric, AnswerSubmission{Answer: "3.145"}, 1},
+		{"numeric on the tolerance edge", numeric, AnswerSubmission{Answer: "3.13"}, 1},
+		{"numeric outside tolerance", numeric, AnswerSubmission{Answer: "3.16"}, 0},
+		{"numeric decimal comma", numeric, AnswerSubmission{Answer: "3,14"}, 1},
+		{"numeric not a number", numeric, AnswerSubmission{Answer: "pi"}, 0},
+		{"numeric without tolerance", exact, AnswerSubmission{Answer: "42.0"}, 1},
+		{"numeric without tolerance off by a little", exact, AnswerSubmission{Answer: "42.01"}, 0},
+
+		{"short text ignoring case", shortText, AnswerSubmission{Answer: "  gO "}, 1},
+		{"short text wrong", shortText, AnswerSubmission{Answer: "golang"}, 0},
+		{"short text unanswered", shortText, AnswerSubmission{Answer: "  "}, 0},
+		{"regex match", pattern, AnswerSubmission{Answer: "colour"}, 1},
+		{"regex optional part", pattern, AnswerSubmission{Answer: "color"}, 1},
+		{"regex anchored at the start", pattern, AnswerSubmission{Answer: "my color"}, 0},
+		{"regex anchored at the end", pattern, AnswerSubmission{Answer: "colors"}, 0},
+		{"regex alternation first", alternation, AnswerSubmission{Answer: "cat"}, 1},
+		{"regex alternation second", alternation, AnswerSubmission{Answer: "dog"}, 1},
+		{"regex alternation anchored as a whole", alternation, AnswerSubmission{Answer: "catdog"}, 0},
+		{"regex alternation suffix", alternation, AnswerSubmission{Answer: "hotdog"}, 0},
+
+		{"ordering right", ordering, AnswerSubmission{Answers: []string{"mercury", "Venus ", "Earth"}}, 1},
+		{"ordering one item in place", ordering, AnswerSubmission{Answers: []string{"Mercury", "Earth", "Venus"}}, 1.0 / 3},
+		{"ordering reversed", ordering, AnswerSubmission{Answers: []string{"Earth", "Venus", "Mercury"}}, 1.0 / 3},
+		{"ordering by sorted index", ordering, AnswerSubmission{Answers: []string{"1", "2", "0"}}, 1},
+		{"ordering identity indexes", ordering, AnswerSubmission{Answers: []string{"0", "1", "2"}}, 0},
+		{"ordering cut short", ordering, AnswerSubmission{Answers: []string{"Mercury", "Venus"}}, 2.0 / 3},
+		{"ordering unanswered", ordering, AnswerSubmission{}, 0},
+
+		{"matching right", matching, AnswerSubmission{Answers: []string{"tokyo", " Rome"}}, 1},
+		{"matching swapped", matching, AnswerSubmission{Answers: []string{"Rome", "Tokyo"}}, 0},
+		{"matching one pair", matching, AnswerSubmission{Answers: []string{"Tokyo", "Paris"}}, 0.5},
+		{"matching missing pair", matching, AnswerSubmission{Answers: []string{"Tokyo"}}, 0.5},
+		{"matching extra answers ignored", matching, AnswerSubmission{Answers: []string{"Tokyo", "Rome", "Paris"}}, 1},
+		{"matching unanswered", matching, AnswerSubmission{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := graderFor(tt.question.Type).Grade(tt.question, tt.answer)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGraderValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     QuestionRequest
+		wantErr bool
+	}{
+		{"single choice", QuestionRequest{Type: QuestionSingleChoice, Options: []string{"a", "b"}, Answer: "b"}, false},
+		{"single choice answer not an option", QuestionRequest{Type: QuestionSingleChoice, Options: []string{"a", "b"}, Answer: "c"}, true},
+		{"multi select", QuestionRequest{Type: QuestionMultiSelect, Options: []string{"a", "b"}, CorrectAnswers: []string{"a", "1"}}, false},
+		{"multi select without answers", QuestionRequest{Type: QuestionMultiSelect, Options: []string{"a", "b"}}, true},
+		{"numeric", QuestionRequest{Type: QuestionNumeric, Answer: "2,5"}, false},
+		{"numeric answer not a number", QuestionRequest{Type: QuestionNumeric, Answer: "two"}, true},
+		{"short text pattern", QuestionRequest{Type: QuestionShortText, Answer: "colou?r", MatchMode: MatchRegex}, false},
+		{"short text invalid pattern", QuestionRequest{Type: QuestionShortText, Answer: "colou(r", MatchMode: MatchRegex}, true},
+		{"ordering", QuestionRequest{Type: QuestionOrdering, Options: []string{"a", "b"}, CorrectAnswers: []string{"b", "a"}}, false},
+		{"ordering missing an item", QuestionRequest{Type: QuestionOrdering, Options: []string{"a", "b", "c"}, CorrectAnswers: []string{"b", "a"}}, true},
+		{"matching", QuestionRequest{Type: QuestionMatching, Options: []string{"a", "b"}, CorrectAnswers: []string{"1", "2"}}, false},
+		{"matching missing a pair", QuestionRequest{Type: QuestionMatching, Options: []string{"a", "b"}, CorrectAnswers: []string{"1"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := graderFor(tt.req.Type).Validate(&tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOrderingIndexesCountFromTheItemsShown(t *testing.T) {
+	q := quizWithEveryType().Questions[4]
+	shown := newStudentQuestion(q).Options
+
+	// Indexes into the author's order give the answer away without reading
+	// the items; they must not score
+	identity := []string{"0", "1", "2"}
+	if got := graderFor(q.Type).Grade(&q, AnswerSubmission{QuestionID: q.ID, Answers: identity}); got != 0 {
+		t.Errorf("identity order %v scored %v of the sorted items %v", identity, got, shown)
+	}
+
+	// Picking the right items from the list shown scores in full
+	var right []string
+	for _, item := range q.CorrectAnswers {
+		for i, o := range shown {
+			if o == item {
+				right = append(right, strconv.Itoa(i))
+			}
+		}
+	}
+	if got := graderFor(q.Type).Grade(&q, AnswerSubmission{QuestionID: q.ID, Answers: right}); got != 1 {
+		t.Errorf("right order %v of the items shown %v scored %v, want 1", right, shown, got)
+	}
+}
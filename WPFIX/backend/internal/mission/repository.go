@@ -2,8 +2,10 @@ package mission
 
 import (
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MissionRepository struct {
@@ -21,7 +23,7 @@ func (r *MissionRepository) Create(mission *Mission) error {
 
 func (r *MissionRepository) FindByID(id uint) (*Mission, error) {
 	var mission Mission
-	err := r.db.Preload("Questions").First(&mission, id).Error
+	err := r.db.Preload("Questions", "session_id IS NULL").Preload("PoolRules").Preload("Classes").First(&mission, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("mission not found")
@@ -48,6 +50,18 @@ func (r *MissionRepository) FindAll(params MissionListParams) ([]MissionWithCrea
 	if params.CreatedBy > 0 {
 		query = query.Where("missions.creator_id = ?", params.CreatedBy)
 	}
+	if params.TemplateID > 0 {
+		query = query.Where("missions.template_id = ?", params.TemplateID)
+	}
+	if params.ClassID > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM mission_classes WHERE mission_classes.mission_id = missions.id AND mission_classes.class_id = ?)", params.ClassID)
+	}
+	if params.StudentID > 0 {
+		// Missions with no class, or assigned to one of the student's classes
+		query = query.Where("(NOT EXISTS (SELECT 1 FROM mission_classes WHERE mission_classes.mission_id = missions.id) OR "+
+			"EXISTS (SELECT 1 FROM mission_classes JOIN class_enrollments ON class_enrollments.class_id = mission_classes.class_id "+
+			"WHERE mission_classes.mission_id = missions.id AND class_enrollments.student_id = ?))", params.StudentID)
+	}
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -75,6 +89,10 @@ func (r *MissionRepository) CreateSubmission(submission *MissionSubmission) erro
 	return r.db.Create(submission).Error
 }
 
+func (r *MissionRepository) CreateSubmissionWithTx(tx *gorm.DB, submission *MissionSubmission) error {
+	return tx.Create(submission).Error
+}
+
 func (r *MissionRepository) FindSubmissionByID(id uint) (*MissionSubmission, error) {
 	var submission MissionSubmission
 	err := r.db.First(&submission, id).Error
@@ -87,6 +105,19 @@ func (r *MissionRepository) FindSubmissionByID(id uint) (*MissionSubmission, err
 	return &submission, nil
 }
 
+// LockSubmissionWithTx finds a submission inside a transaction and locks the row
+func (r *MissionRepository) LockSubmissionWithTx(tx *gorm.DB, id uint) (*MissionSubmission, error) {
+	var submission MissionSubmission
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&submission, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("submission not found")
+		}
+		return nil, err
+	}
+	return &submission, nil
+}
+
 func (r *MissionRepository) FindAllSubmissions(params SubmissionListParams) ([]SubmissionWithDetails, int64, error) {
 	var submissions []SubmissionWithDetails
 	var total int64
@@ -106,6 +137,10 @@ func (r *MissionRepository) FindAllSubmissions(params SubmissionListParams) ([]S
 	if params.CreatorID > 0 {
 		query = query.Where("missions.creator_id = ?", params.CreatorID)
 	}
+	if params.ClassID > 0 {
+		query = query.Where("mission_submissions.student_id IN (SELECT student_id FROM class_enrollments WHERE class_id = ?)", params.ClassID).
+			Where("mission_submissions.mission_id IN (SELECT mission_id FROM mission_classes WHERE class_id = ?)", params.ClassID)
+	}
 	if params.Status != "" {
 		query = query.Where("mission_submissions.status = ?", params.Status)
 	}
@@ -131,10 +166,131 @@ func (r *MissionRepository) UpdateSubmissionWithTx(tx *gorm.DB, id uint, updates
 	return tx.Model(&MissionSubmission{}).Where("id = ?", id).Updates(updates).Error
 }
 
-func (r *MissionRepository) CheckDuplicateSubmission(missionID, studentID uint) (bool, error) {
-	var count int64
-	err := r.db.Model(&MissionSubmission{}).
+// FindAttemptsWithTx lists a student's attempts at a mission, oldest first
+func (r *MissionRepository) FindAttemptsWithTx(tx *gorm.DB, missionID, studentID uint) ([]MissionSubmission, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	var attempts []MissionSubmission
+	err := tx.Where("mission_id = ? AND student_id = ?", missionID, studentID).
+		Order("attempt ASC").
+		Find(&attempts).Error
+	return attempts, err
+}
+
+// MarkCountedAttemptWithTx makes one attempt the one that counts for a student
+func (r *MissionRepository) MarkCountedAttemptWithTx(tx *gorm.DB, missionID, studentID, submissionID uint) error {
+	return tx.Model(&MissionSubmission{}).
 		Where("mission_id = ? AND student_id = ?", missionID, studentID).
-		Count(&count).Error
-	return count > 0, err
+		Update("counted", gorm.Expr("id = ?", submissionID)).Error
+}
+
+// Attempt sessions
+func (r *MissionRepository) CreateSessionWithTx(tx *gorm.DB, session *AttemptSession) error {
+	return tx.Create(session).Error
+}
+
+// FindOpenSession finds a student's attempt in progress at a mission
+func (r *MissionRepository) FindOpenSession(missionID, studentID uint) (*AttemptSession, error) {
+	var session AttemptSession
+	err := r.db.Where("mission_id = ? AND student_id = ? AND status = ?", missionID, studentID, SessionInProgress).
+		First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("no quiz attempt in progress")
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *MissionRepository) FindSessionByID(id uint) (*AttemptSession, error) {
+	var session AttemptSession
+	err := r.db.First(&session, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("quiz attempt not found")
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// LockSessionWithTx finds an attempt session and locks the row
+func (r *MissionRepository) LockSessionWithTx(tx *gorm.DB, sessionID uint) (*AttemptSession, error) {
+	var session AttemptSession
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&session, sessionID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("quiz attempt not found")
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *MissionRepository) UpdateSessionWithTx(tx *gorm.DB, sessionID uint, updates map[string]interface{}) error {
+	return tx.Model(&AttemptSession{}).Where("id = ?", sessionID).Updates(updates).Error
+}
+
+// FindExpiredSessionIDs lists attempts in progress that expired before the given time
+func (r *MissionRepository) FindExpiredSessionIDs(before time.Time) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&AttemptSession{}).
+		Where("status = ? AND expires_at IS NOT NULL AND expires_at <= ?", SessionInProgress, before).
+		Order("expires_at ASC").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// Scheduling
+func (r *MissionRepository) CreateWithTx(tx *gorm.DB, mission *Mission) error {
+	return tx.Create(mission).Error
+}
+
+func (r *MissionRepository) UpdateWithTx(tx *gorm.DB, id uint, updates map[string]interface{}) error {
+	return tx.Model(&Mission{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// LockMissionWithTx finds a mission with its questions, pool rules and classes
+// and locks the row
+func (r *MissionRepository) LockMissionWithTx(tx *gorm.DB, id uint) (*Mission, error) {
+	var mission Mission
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Questions", "session_id IS NULL").Preload("PoolRules").Preload("Classes").
+		First(&mission, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("mission not found")
+		}
+		return nil, err
+	}
+	return &mission, nil
+}
+
+// PublishScheduled activates scheduled missions whose publish time has come
+func (r *MissionRepository) PublishScheduled(now time.Time) (int64, error) {
+	result := r.db.Model(&Mission{}).
+		Where("status = ? AND publish_at <= ?", MissionScheduled, now).
+		Update("status", "active")
+	return result.RowsAffected, result.Error
+}
+
+// ExpireClosed expires active missions past their close time, or past their
+// deadline when they have none
+func (r *MissionRepository) ExpireClosed(now time.Time) (int64, error) {
+	result := r.db.Model(&Mission{}).
+		Where("status = ? AND (close_at <= ? OR (close_at IS NULL AND deadline <= ?))", "active", now, now).
+		Update("status", "expired")
+	return result.RowsAffected, result.Error
+}
+
+// FindDueTemplateIDs lists recurring missions with an instance due
+func (r *MissionRepository) FindDueTemplateIDs(now time.Time) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&Mission{}).
+		Where("status = ? AND next_run_at <= ?", MissionRecurring, now).
+		Order("next_run_at ASC").
+		Pluck("id", &ids).Error
+	return ids, err
 }
@@ -0,0 +1,48 @@
+package mission
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// Reward policies decide how much of a mission's points an approved submission earns
+const (
+	RewardFull         = "full"         // All points on approval
+	RewardProportional = "proportional" // Points scaled by the score percentage
+	RewardTiered       = "tiered"       // Share of the points set by the highest tier reached
+)
+
+// RewardTier pays Percent of the mission points for a score of at least MinScore percent
+type RewardTier struct {
+	MinScore int `json:"min_score" binding:"gte=0,lte=100"`
+	Percent  int `json:"percent" binding:"gte=0,lte=100"`
+}
+
+// RewardTiers is stored as JSON on the mission
+type RewardTiers []RewardTier
+
+func (rt RewardTiers) Value() (driver.Value, error) {
+	if rt == nil {
+		return nil, nil
+	}
+	return json.Marshal(rt)
+}
+
+func (rt *RewardTiers) Scan(value interface{}) error {
+	if value == nil {
+		*rt = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, rt)
+}
+
+// Reward is the payout computed for an approved submission
+type Reward struct {
+	Points int
+	Detail string // How the points were worked out, for the wallet transaction
+}
@@ -0,0 +1,70 @@
+package mission
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// validateRewardPolicy checks that a tiered policy has tiers to pay from
+func validateRewardPolicy(policy string, tiers RewardTiers) error {
+	if policy == RewardTiered && len(tiers) == 0 {
+		return errors.New("a tiered reward policy needs at least one tier")
+	}
+	return nil
+}
+
+// scorePercent is the score of a submission as a percentage. Auto-graded
+// quizzes use their graded percentage unless the reviewer scored them;
+// reviewer scores are out of 100, and scores above it, which clients sent
+// before rewards depended on the score, count as 100.
+func scorePercent(submission *MissionSubmission, reviewScore int) float64 {
+	score := float64(reviewScore)
+	if submission.AutoGraded && reviewScore == 0 {
+		score = submission.Percentage
+	}
+	return math.Max(0, math.Min(100, score))
+}
+
+// CalculateReward works out the points earned by an approved submission with
+// the given score percentage, submitted at submittedAt
+func (m *Mission) CalculateReward(score float64, submittedAt time.Time) Reward {
+	var parts []string
+	points := m.Points
+
+	switch m.RewardPolicy {
+	case RewardProportional:
+		points = int(math.Round(float64(m.Points) * score / 100))
+		parts = append(parts, fmt.Sprintf("%.0f%% score", score))
+	case RewardTiered:
+		tiers := make(RewardTiers, len(m.RewardTiers))
+		copy(tiers, m.RewardTiers)
+		sort.Slice(tiers, func(i, j int) bool { return tiers[i].MinScore > tiers[j].MinScore })
+
+		points = 0
+		parts = append(parts, fmt.Sprintf("%.0f%% score, below every tier", score))
+		for _, tier := range tiers {
+			if score >= float64(tier.MinScore) {
+				points = int(math.Round(float64(m.Points) * float64(tier.Percent) / 100))
+				parts[0] = fmt.Sprintf("%.0f%% score, tier >=%d%% pays %d%%", score, tier.MinScore, tier.Percent)
+				break
+			}
+		}
+	}
+
+	if points > 0 && m.EarlyBonusPercent > 0 && m.EarlyBonusUntil != nil && submittedAt.Before(*m.EarlyBonusUntil) {
+		bonus := int(math.Round(float64(points) * float64(m.EarlyBonusPercent) / 100))
+		points += bonus
+		parts = append(parts, fmt.Sprintf("+%d early bonus", bonus))
+	}
+
+	detail := fmt.Sprintf("%d of %d points", points, m.Points)
+	if len(parts) > 0 {
+		detail += ", " + strings.Join(parts, ", ")
+	}
+
+	return Reward{Points: points, Detail: detail}
+}
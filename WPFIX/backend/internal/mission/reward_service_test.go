@@ -0,0 +1,40 @@
+package mission
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScorePercent(t *testing.T) {
+	tests := []struct {
+		name        string
+		autoGraded  bool
+		percentage  float64
+		reviewScore int
+		want        float64
+	}{
+		{"reviewer score", false, 0, 80, 80},
+		{"raw score above 100", false, 0, 150, 100},
+		{"quiz keeps its graded percentage", true, 62.5, 0, 62.5},
+		{"reviewer overrides the quiz", true, 62.5, 90, 90},
+		{"reviewer overrides the quiz above 100", true, 62.5, 120, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			submission := &MissionSubmission{AutoGraded: tt.autoGraded, Percentage: tt.percentage}
+			if got := scorePercent(submission, tt.reviewScore); got != tt.want {
+				t.Errorf("scorePercent = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateRewardCapsRawScores(t *testing.T) {
+	mission := &Mission{Points: 40, RewardPolicy: RewardProportional}
+
+	reward := mission.CalculateReward(scorePercent(&MissionSubmission{}, 250), time.Now())
+
+	if reward.Points != 40 {
+		t.Errorf("a raw score of 250 paid %d of 40 points, want 40", reward.Points)
+	}
+}
@@ -0,0 +1,26 @@
+package mission
+
+import "time"
+
+// Mission statuses set by the schedule; see RunSchedule
+const (
+	MissionScheduled = "scheduled" // Waiting for its publish_at
+	MissionRecurring = "recurring" // A template spawning instances; never taken itself
+)
+
+// Recurrences of recurring missions
+const (
+	RecurDaily   = "daily"
+	RecurWeekly  = "weekly"
+	RecurMonthly = "monthly"
+)
+
+// ScheduleInterval is how often missions are published, expired and spawned
+const ScheduleInterval = time.Minute
+
+// ScheduleResult counts what one run of the schedule changed
+type ScheduleResult struct {
+	Published int64 `json:"published"`
+	Expired   int64 `json:"expired"`
+	Spawned   int   `json:"spawned"`
+}
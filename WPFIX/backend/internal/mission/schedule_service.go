@@ -0,0 +1,206 @@
+package mission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+// closesAt is when a mission expires: its CloseAt, or else its Deadline
+func (m *Mission) closesAt() *time.Time {
+	if m.CloseAt != nil {
+		return m.CloseAt
+	}
+	return m.Deadline
+}
+
+// windowStatus is the status a mission's publish window gives it at a time
+func (m *Mission) windowStatus(now time.Time) string {
+	if m.PublishAt != nil && now.Before(*m.PublishAt) {
+		return MissionScheduled
+	}
+	if closes := m.closesAt(); closes != nil && !now.Before(*closes) {
+		return "expired"
+	}
+	return "active"
+}
+
+// validateSchedule checks that a mission's window and recurrence fit together
+func (m *Mission) validateSchedule() error {
+	if m.PublishAt != nil {
+		if m.CloseAt != nil && !m.CloseAt.After(*m.PublishAt) {
+			return errors.New("close_at must be after publish_at")
+		}
+		if m.Deadline != nil && !m.Deadline.After(*m.PublishAt) {
+			return errors.New("deadline must be after publish_at")
+		}
+		if m.RecurrenceEnd != nil && m.RecurrenceEnd.Before(*m.PublishAt) {
+			return errors.New("recurrence_end must not be before publish_at")
+		}
+	}
+	if m.Recurrence != "" && m.PublishAt == nil {
+		return errors.New("recurring missions need a publish_at for their first instance")
+	}
+	return nil
+}
+
+// shift moves a time of a recurring mission's first instance to its k-th
+// instance, counting from 0
+func (m *Mission) shift(t *time.Time, k int) *time.Time {
+	if t == nil {
+		return nil
+	}
+	var shifted time.Time
+	switch m.Recurrence {
+	case RecurDaily:
+		shifted = t.AddDate(0, 0, k)
+	case RecurWeekly:
+		shifted = t.AddDate(0, 0, 7*k)
+	case RecurMonthly:
+		shifted = t.AddDate(0, k, 0)
+		// Stay in the month: January 31 plus a month is February 28, not March 3
+		if shifted.Day() != t.Day() {
+			shifted = shifted.AddDate(0, 0, -shifted.Day())
+		}
+	default:
+		shifted = *t
+	}
+	return &shifted
+}
+
+// instance builds the k-th instance of a recurring mission, counting from 0,
+// with the template's questions, pool rules and classes. Without a close_at or
+// deadline an instance closes when the next one is published.
+func (m *Mission) instance(k int, now time.Time) *Mission {
+	templateID := m.ID
+	instance := *m
+	instance.ID = 0
+	instance.Title = fmt.Sprintf("%s #%d", m.Title, k+1)
+	instance.PublishAt = m.shift(m.PublishAt, k)
+	instance.CloseAt = m.shift(m.CloseAt, k)
+	instance.Deadline = m.shift(m.Deadline, k)
+	instance.EarlyBonusUntil = m.shift(m.EarlyBonusUntil, k)
+	if instance.CloseAt == nil && instance.Deadline == nil {
+		instance.CloseAt = m.shift(m.PublishAt, k+1)
+	}
+	instance.Recurrence = ""
+	instance.RecurrenceEnd = nil
+	instance.NextRunAt = nil
+	instance.TemplateID = &templateID
+	instance.Occurrence = k + 1
+	instance.CreatedAt = time.Time{}
+	instance.UpdatedAt = time.Time{}
+	instance.Status = instance.windowStatus(now)
+
+	instance.Questions = nil
+	for _, q := range m.Questions {
+		q.ID, q.MissionID = 0, 0
+		instance.Questions = append(instance.Questions, q)
+	}
+	instance.PoolRules = nil
+	for _, r := range m.PoolRules {
+		r.ID, r.MissionID = 0, 0
+		instance.PoolRules = append(instance.PoolRules, r)
+	}
+	instance.Classes = nil
+	for _, c := range m.Classes {
+		instance.Classes = append(instance.Classes, MissionClass{ClassID: c.ClassID})
+	}
+	return &instance
+}
+
+// RunSchedule publishes scheduled missions whose publish_at has come, expires
+// missions whose window has closed and spawns the instances of recurring
+// missions that are due
+func (s *MissionService) RunSchedule() *ScheduleResult {
+	now := s.db.NowFunc()
+	result := &ScheduleResult{}
+
+	published, err := s.repo.PublishScheduled(now)
+	if err != nil {
+		log.Printf("[mission] failed to publish scheduled missions: %v", err)
+	}
+	result.Published = published
+
+	ids, err := s.repo.FindDueTemplateIDs(now)
+	if err != nil {
+		log.Printf("[mission] failed to find due recurring missions: %v", err)
+	}
+	for _, id := range ids {
+		spawned, err := s.spawnInstances(id, now)
+		if err != nil {
+			log.Printf("[mission] failed to spawn instances of recurring mission %d: %v", id, err)
+		}
+		result.Spawned += spawned
+	}
+
+	expired, err := s.repo.ExpireClosed(now)
+	if err != nil {
+		log.Printf("[mission] failed to expire closed missions: %v", err)
+	}
+	result.Expired = expired
+
+	return result
+}
+
+// spawnInstances creates the instances of a recurring mission due by now.
+// Instances whose window closed while none were spawned, such as during
+// downtime, are skipped. A template past its recurrence_end expires.
+func (s *MissionService) spawnInstances(templateID uint, now time.Time) (int, error) {
+	spawned := 0
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		template, err := s.repo.LockMissionWithTx(tx, templateID)
+		if err != nil {
+			return err
+		}
+		// Spawned or paused since it was listed
+		if template.Status != MissionRecurring || template.NextRunAt == nil || template.NextRunAt.After(now) {
+			return nil
+		}
+
+		k := template.Occurrence
+		next := template.shift(template.PublishAt, k)
+		for !next.After(now) {
+			if template.RecurrenceEnd != nil && next.After(*template.RecurrenceEnd) {
+				break
+			}
+			instance := template.instance(k, now)
+			k++
+			next = template.shift(template.PublishAt, k)
+
+			if closes := instance.closesAt(); closes != nil && !closes.After(now) {
+				continue
+			}
+			if err := s.repo.CreateWithTx(tx, instance); err != nil {
+				return err
+			}
+			spawned++
+		}
+
+		updates := map[string]interface{}{"occurrence": k, "next_run_at": next}
+		if template.RecurrenceEnd != nil && next.After(*template.RecurrenceEnd) {
+			updates["next_run_at"] = nil
+			updates["status"] = "expired"
+		}
+		return s.repo.UpdateWithTx(tx, templateID, updates)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return spawned, nil
+}
+
+// RunScheduler runs the mission schedule every interval until ctx is cancelled
+func (s *MissionService) RunScheduler(ctx context.Context, interval time.Duration) {
+	utils.RunEvery(ctx, interval, func() {
+		result := s.RunSchedule()
+		if result.Published > 0 || result.Expired > 0 || result.Spawned > 0 {
+			log.Printf("[mission] schedule: %d published, %d expired, %d instances spawned", result.Published, result.Expired, result.Spawned)
+		}
+	})
+}
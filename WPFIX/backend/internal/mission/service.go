@@ -3,6 +3,7 @@ package mission
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"math"
 	"wallet-point/internal/wallet"
 
@@ -25,25 +26,79 @@ func NewMissionService(repo *MissionRepository, walletService *wallet.WalletServ
 
 // Mission Management
 func (s *MissionService) CreateMission(req *CreateMissionRequest, creatorID uint) (*Mission, error) {
+	policy := req.RewardPolicy
+	if policy == "" {
+		policy = RewardFull
+	}
+	if err := validateRewardPolicy(policy, req.RewardTiers); err != nil {
+		return nil, err
+	}
+
 	mission := &Mission{
-		Title:       req.Title,
-		Description: req.Description,
-		Type:        req.Type,
-		Points:      req.Points,
-		Deadline:    req.Deadline,
-		Status:      "active",
-		CreatorID:   creatorID,
+		Title:         req.Title,
+		Description:   req.Description,
+		Type:          req.Type,
+		Points:        req.Points,
+		Deadline:      req.Deadline,
+		Status:        "active",
+		PassThreshold: req.PassThreshold,
+		CreatorID:     creatorID,
+
+		RewardPolicy:      policy,
+		RewardTiers:       req.RewardTiers,
+		EarlyBonusPercent: req.EarlyBonusPercent,
+		EarlyBonusUntil:   req.EarlyBonusUntil,
+
+		MaxAttempts:   1,
+		ScoringMethod: ScoreLatest,
+
+		TimeLimitMinutes: req.TimeLimitMinutes,
+		ShuffleQuestions: req.ShuffleQuestions,
+		ShuffleOptions:   req.ShuffleOptions,
+
+		PublishAt:     req.PublishAt,
+		CloseAt:       req.CloseAt,
+		Recurrence:    req.Recurrence,
+		RecurrenceEnd: req.RecurrenceEnd,
+	}
+	if err := mission.validateSchedule(); err != nil {
+		return nil, err
+	}
+	// A recurring mission is a template whose instances the schedule spawns
+	if mission.Recurrence != "" {
+		mission.Status = MissionRecurring
+		mission.NextRunAt = mission.PublishAt
+	} else if mission.PublishAt != nil && mission.PublishAt.After(s.db.NowFunc()) {
+		mission.Status = MissionScheduled
+	}
+	if req.MaxAttempts != nil {
+		mission.MaxAttempts = *req.MaxAttempts
+	}
+	if req.ScoringMethod != "" {
+		mission.ScoringMethod = req.ScoringMethod
 	}
 
 	if req.Type == "quiz" && len(req.Questions) > 0 {
 		for _, q := range req.Questions {
-			mission.Questions = append(mission.Questions, MissionQuestion{
-				Question: q.Question,
-				Options:  q.Options,
-				Answer:   q.Answer,
-			})
+			question, err := newQuestion(0, q)
+			if err != nil {
+				return nil, err
+			}
+			mission.Questions = append(mission.Questions, question)
+		}
+	}
+	if req.Type == "quiz" && len(req.PoolRules) > 0 {
+		rules, err := s.newPoolRules(0, creatorID, req.PoolRules)
+		if err != nil {
+			return nil, err
 		}
+		mission.PoolRules = rules
 	}
+	classes, err := s.newMissionClasses(0, creatorID, req.ClassIDs)
+	if err != nil {
+		return nil, err
+	}
+	mission.Classes = classes
 
 	if err := s.repo.Create(mission); err != nil {
 		return nil, err
@@ -82,11 +137,86 @@ func (s *MissionService) GetAllMissions(params MissionListParams) (*MissionListR
 
 func (s *MissionService) UpdateMission(id uint, req *UpdateMissionRequest) (*Mission, error) {
 	// Check if exists
-	_, err := s.repo.FindByID(id)
+	mission, err := s.repo.FindByID(id)
 	if err != nil {
 		return nil, err
 	}
 
+	policy, tiers := mission.RewardPolicy, mission.RewardTiers
+	if req.RewardPolicy != "" {
+		policy = req.RewardPolicy
+	}
+	if req.RewardTiers != nil {
+		tiers = req.RewardTiers
+	}
+	if err := validateRewardPolicy(policy, tiers); err != nil {
+		return nil, err
+	}
+
+	// The publish window and recurrence as they will be after the update
+	window := *mission
+	if req.PublishAt != nil {
+		window.PublishAt = req.PublishAt
+	}
+	if req.CloseAt != nil {
+		window.CloseAt = req.CloseAt
+	}
+	if req.Deadline != nil {
+		window.Deadline = req.Deadline
+	}
+	if req.RecurrenceEnd != nil {
+		window.RecurrenceEnd = req.RecurrenceEnd
+	}
+	if err := window.validateSchedule(); err != nil {
+		return nil, err
+	}
+	if mission.Recurrence == "" && req.Status == MissionRecurring {
+		return nil, errors.New("only recurring missions can have status recurring")
+	}
+	if mission.Recurrence != "" && req.Status != "" && req.Status != MissionRecurring && req.Status != "inactive" {
+		return nil, errors.New("a recurring mission is a template; set its status to recurring or inactive")
+	}
+
+	// Validate the new questions and pool rules before changing anything.
+	// Questions keep their IDs, so submissions and attempts in progress still
+	// point at them.
+	existing := make(map[uint]bool, len(mission.Questions))
+	for _, q := range mission.Questions {
+		existing[q.ID] = true
+	}
+	var questions []MissionQuestion
+	var kept []uint
+	for _, q := range req.Questions {
+		question, err := newQuestion(id, q)
+		if err != nil {
+			return nil, err
+		}
+		if q.ID > 0 {
+			if !existing[q.ID] {
+				return nil, fmt.Errorf("question %d is not part of this mission", q.ID)
+			}
+			question.ID = q.ID
+			kept = append(kept, q.ID)
+		}
+		questions = append(questions, question)
+	}
+
+	var rules []PoolRule
+	if req.PoolRules != nil {
+		rules, err = s.newPoolRules(id, mission.CreatorID, req.PoolRules)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var classes []MissionClass
+	if req.ClassIDs != nil {
+		classes, err = s.newMissionClasses(id, mission.CreatorID, req.ClassIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	updates := make(map[string]interface{})
 	if req.Title != "" {
 		updates["title"] = req.Title
@@ -103,6 +233,55 @@ func (s *MissionService) UpdateMission(id uint, req *UpdateMissionRequest) (*Mis
 	if req.Status != "" {
 		updates["status"] = req.Status
 	}
+	if req.PublishAt != nil {
+		updates["publish_at"] = req.PublishAt
+	}
+	if req.CloseAt != nil {
+		updates["close_at"] = req.CloseAt
+	}
+	if req.RecurrenceEnd != nil {
+		updates["recurrence_end"] = req.RecurrenceEnd
+	}
+	switch {
+	case mission.Recurrence != "":
+		// Instances not yet spawned follow the template's new schedule
+		if req.PublishAt != nil || req.RecurrenceEnd != nil || req.Status == MissionRecurring {
+			updates["next_run_at"] = window.shift(window.PublishAt, mission.Occurrence)
+		}
+	case req.Status == "" && mission.Status != "inactive" && (req.PublishAt != nil || req.CloseAt != nil || req.Deadline != nil):
+		// A moved window publishes, reopens or expires the mission
+		updates["status"] = window.windowStatus(s.db.NowFunc())
+	}
+	if req.PassThreshold != nil {
+		updates["pass_threshold"] = *req.PassThreshold
+	}
+	if req.RewardPolicy != "" {
+		updates["reward_policy"] = req.RewardPolicy
+	}
+	if req.RewardTiers != nil {
+		updates["reward_tiers"] = RewardTiers(req.RewardTiers)
+	}
+	if req.EarlyBonusPercent != nil {
+		updates["early_bonus_percent"] = *req.EarlyBonusPercent
+	}
+	if req.EarlyBonusUntil != nil {
+		updates["early_bonus_until"] = req.EarlyBonusUntil
+	}
+	if req.MaxAttempts != nil {
+		updates["max_attempts"] = *req.MaxAttempts
+	}
+	if req.ScoringMethod != "" {
+		updates["scoring_method"] = req.ScoringMethod
+	}
+	if req.TimeLimitMinutes != nil {
+		updates["time_limit_minutes"] = *req.TimeLimitMinutes
+	}
+	if req.ShuffleQuestions != nil {
+		updates["shuffle_questions"] = *req.ShuffleQuestions
+	}
+	if req.ShuffleOptions != nil {
+		updates["shuffle_options"] = *req.ShuffleOptions
+	}
 
 	if len(updates) > 0 {
 		if err := s.repo.Update(id, updates); err != nil {
@@ -113,20 +292,18 @@ func (s *MissionService) UpdateMission(id uint, req *UpdateMissionRequest) (*Mis
 	// Handle questions update if provided
 	if req.Questions != nil {
 		err := s.db.Transaction(func(tx *gorm.DB) error {
-			// Delete existing questions
-			if err := tx.Where("mission_id = ?", id).Delete(&MissionQuestion{}).Error; err != nil {
+			// Remove the fixed questions left out; questions drawn for attempts stay
+			removed := tx.Where("mission_id = ? AND session_id IS NULL", id)
+			if len(kept) > 0 {
+				removed = removed.Where("id NOT IN ?", kept)
+			}
+			if err := removed.Delete(&MissionQuestion{}).Error; err != nil {
 				return err
 			}
 
-			// Add new questions
-			for _, q := range req.Questions {
-				newQ := MissionQuestion{
-					MissionID: id,
-					Question:  q.Question,
-					Options:   q.Options,
-					Answer:    q.Answer,
-				}
-				if err := tx.Create(&newQ).Error; err != nil {
+			// Update the questions with an ID and add the others
+			for i := range questions {
+				if err := tx.Save(&questions[i]).Error; err != nil {
 					return err
 				}
 			}
@@ -137,6 +314,29 @@ func (s *MissionService) UpdateMission(id uint, req *UpdateMissionRequest) (*Mis
 		}
 	}
 
+	// Replace the pool rules if provided
+	if req.PoolRules != nil {
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("mission_id = ?", id).Delete(&PoolRule{}).Error; err != nil {
+				return err
+			}
+			if len(rules) > 0 {
+				return tx.Create(&rules).Error
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Replace the classes if provided
+	if req.ClassIDs != nil {
+		if err := s.repo.ReplaceMissionClasses(id, classes); err != nil {
+			return nil, err
+		}
+	}
+
 	return s.repo.FindByID(id)
 }
 
@@ -156,24 +356,60 @@ func (s *MissionService) SubmitMission(req *SubmitMissionRequest, studentID uint
 	if err != nil {
 		return nil, err
 	}
+	if err := s.checkClassAccess(mission, studentID); err != nil {
+		return nil, err
+	}
 
-	// Check deadline
+	// A quiz attempt in progress takes the answers, under its own time limit
+	if mission.Type == "quiz" {
+		session, err := s.repo.FindOpenSession(mission.ID, studentID)
+		if err == nil {
+			taken, err := s.sessionMission(mission, session)
+			if err != nil {
+				return nil, err
+			}
+			return s.submitSession(taken, session, req.Answers)
+		}
+		if err.Error() != "no quiz attempt in progress" {
+			return nil, err
+		}
+		if mission.requiresSession() {
+			return nil, errors.New("start the quiz before submitting answers")
+		}
+	}
+
+	// Check the publish window and deadline
+	if mission.Status != "active" {
+		return nil, errors.New("mission is not active")
+	}
+	if mission.CloseAt != nil && !mission.CloseAt.After(s.db.NowFunc()) {
+		return nil, errors.New("mission is closed")
+	}
 	if mission.Deadline != nil && mission.Deadline.Before(s.db.NowFunc()) {
 		return nil, errors.New("mission deadline has passed")
 	}
 
-	// Check duplicate submission
-	exists, err := s.repo.CheckDuplicateSubmission(req.MissionID, studentID)
+	return s.submitAttempt(mission, studentID, req, nil)
+}
+
+// submitAttempt stores a new attempt at a mission, grading quizzes straight
+// away. A submission closing an attempt session also closes the session with
+// the status set on it by the caller.
+func (s *MissionService) submitAttempt(mission *Mission, studentID uint, req *SubmitMissionRequest, session *AttemptSession) (*MissionSubmission, error) {
+	// Check the attempts left; a rejected attempt may be resubmitted
+	attempts, err := s.repo.FindAttemptsWithTx(nil, req.MissionID, studentID)
 	if err != nil {
 		return nil, err
 	}
-	if exists {
-		return nil, errors.New("you have already submitted this mission")
+	attempt, err := nextAttempt(mission, attempts)
+	if err != nil {
+		return nil, err
 	}
 
 	submission := &MissionSubmission{
 		MissionID: req.MissionID,
 		StudentID: studentID,
+		Attempt:   attempt,
 		Content:   req.Content,
 		FileURL:   req.FileURL,
 		Status:    "pending",
@@ -184,57 +420,157 @@ func (s *MissionService) SubmitMission(req *SubmitMissionRequest, studentID uint
 		submission.Content = string(answersBytes)
 	}
 
-	if err := s.repo.CreateSubmission(submission); err != nil {
-		return nil, err
+	// Quizzes are graded straight away; a passing score is approved and rewarded
+	// without waiting for a dosen
+	var result *QuizResult
+	var reward Reward
+	if mission.Type == "quiz" && len(mission.Questions) > 0 {
+		result = GradeQuiz(mission, req.Answers)
+		submission.Score = int(math.Round(result.Earned))
+		submission.MaxScore = result.MaxPoints
+		submission.Percentage = result.Percentage
+		submission.AutoGraded = true
+		submission.Feedback = result.Feedback
+		switch {
+		case result.Passed:
+			submission.Status = "approved"
+			submission.ReviewNote = fmt.Sprintf("Auto-graded: %.2f/%d points, %d/%d fully correct (%.2f%%), pass threshold %d%%", result.Earned, result.MaxPoints, result.Correct, result.Total, result.Percentage, mission.PassThreshold)
+			if alreadyRewarded(attempts, 0) {
+				submission.ReviewNote += "; reward already paid for an earlier attempt"
+			} else {
+				reward = mission.CalculateReward(result.Percentage, s.db.NowFunc())
+				submission.RewardPoints = reward.Points
+			}
+		case mission.PassThreshold > 0 && mission.hasAttemptsLeft(usedAttempts(attempts)+1):
+			// Failing with attempts left frees the student to try again; the last
+			// attempt waits for a dosen
+			submission.Status = "rejected"
+			submission.ReviewNote = fmt.Sprintf("Auto-graded: %.2f/%d points, %d/%d fully correct (%.2f%%), below pass threshold %d%%", result.Earned, result.MaxPoints, result.Correct, result.Total, result.Percentage, mission.PassThreshold)
+		}
+	}
+	if session != nil && session.Status == SessionExpired {
+		note := "Auto-submitted when the time limit ran out"
+		if submission.ReviewNote != "" {
+			note += "; " + submission.ReviewNote
+		}
+		submission.ReviewNote = note
 	}
 
-	return submission, nil
-}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if session != nil {
+			locked, err := s.repo.LockSessionWithTx(tx, session.ID)
+			if err != nil {
+				return err
+			}
+			if locked.Status != SessionInProgress {
+				return errors.New("this quiz attempt has already been submitted")
+			}
+		}
 
-func (s *MissionService) ReviewSubmission(submissionID uint, req *ReviewSubmissionRequest, reviewerID uint) error {
-	submission, err := s.repo.FindSubmissionByID(submissionID)
+		if err := s.repo.CreateSubmissionWithTx(tx, submission); err != nil {
+			return err
+		}
+
+		if session != nil {
+			err := s.repo.UpdateSessionWithTx(tx, session.ID, map[string]interface{}{
+				"status":        session.Status,
+				"answers":       session.Answers,
+				"submission_id": submission.ID,
+				"submitted_at":  s.db.NowFunc(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if reward.Points > 0 {
+			if err := s.walletService.ProcessMissionRewardWithTx(tx, studentID, reward.Points, mission.Title, mission.ID, 0, reward.Detail); err != nil {
+				return err
+			}
+		}
+
+		return s.refreshCountedAttempt(tx, mission, studentID)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if submission.Status != "pending" {
-		return errors.New("submission has already been reviewed")
-	}
+	return submission, nil
+}
 
+func (s *MissionService) ReviewSubmission(submissionID uint, req *ReviewSubmissionRequest, reviewerID uint) error {
 	// Start a transaction for the review and potential wallet reward
 	return s.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the submission so two reviews of it cannot both pay the reward
+		submission, err := s.repo.LockSubmissionWithTx(tx, submissionID)
+		if err != nil {
+			return err
+		}
+		if submission.Status != "pending" {
+			return errors.New("submission has already been reviewed")
+		}
+
 		updates := map[string]interface{}{
 			"status":          req.Status,
 			"score":           req.Score,
 			"validation_note": req.ReviewNote,
 			"validated_by":    reviewerID,
 		}
+		// An auto-graded quiz keeps its points; a dosen score replaces its percentage
+		if submission.AutoGraded {
+			delete(updates, "score")
+			if req.Score > 0 {
+				updates["percentage"] = scorePercent(submission, req.Score)
+			}
+		}
 
-		// Update submission status
-		if err := s.repo.UpdateSubmissionWithTx(tx, submissionID, updates); err != nil {
+		mission, err := s.repo.FindByID(submission.MissionID)
+		if err != nil {
 			return err
 		}
 
-		// If approved, reward points
+		// If approved, work out the payout under the mission's reward policy,
+		// unless an earlier attempt was already paid
+		var reward Reward
 		if req.Status == "approved" {
-			mission, err := s.repo.FindByID(submission.MissionID)
+			attempts, err := s.repo.FindAttemptsWithTx(tx, submission.MissionID, submission.StudentID)
 			if err != nil {
 				return err
 			}
+			if !alreadyRewarded(attempts, submission.ID) {
+				reward = mission.CalculateReward(scorePercent(submission, req.Score), submission.CreatedAt)
+				updates["reward_points"] = reward.Points
+			}
+		}
+
+		// Update submission status
+		if err := s.repo.UpdateSubmissionWithTx(tx, submissionID, updates); err != nil {
+			return err
+		}
 
+		// Reward points
+		if reward.Points > 0 {
 			// for now we'll assume it handles its own internal transaction if needed,
 			// though nested transactions in GORM/MySQL are safe.
-			err = s.walletService.ProcessMissionRewardWithTx(tx, submission.StudentID, mission.Points, mission.Title, mission.ID, reviewerID)
+			err = s.walletService.ProcessMissionRewardWithTx(tx, submission.StudentID, reward.Points, mission.Title, mission.ID, reviewerID, reward.Detail)
 			if err != nil {
 				return err
 			}
 		}
 
-		return nil
+		return s.refreshCountedAttempt(tx, mission, submission.StudentID)
 	})
 }
 
-func (s *MissionService) GetAllSubmissions(params SubmissionListParams) (*SubmissionListResponse, error) {
+// GetAllSubmissions lists submissions. Filtering by a class needs the user to
+// own it, unless they are a student, who only ever sees their own submissions.
+func (s *MissionService) GetAllSubmissions(params SubmissionListParams, userID uint, role string) (*SubmissionListResponse, error) {
+	if params.ClassID > 0 && role != "mahasiswa" {
+		if _, err := s.findOwnedClass(params.ClassID, userID, role); err != nil {
+			return nil, err
+		}
+	}
+
 	if params.Page < 1 {
 		params.Page = 1
 	}
@@ -258,30 +594,52 @@ func (s *MissionService) GetAllSubmissions(params SubmissionListParams) (*Submis
 	}, nil
 }
 
-func (s *MissionService) GetDosenStats(dosenID uint) (*DosenStatsResponse, error) {
+// GetDosenStats counts a dosen's missions and reviews, within one of their
+// classes when classID is set
+func (s *MissionService) GetDosenStats(dosenID, classID uint, role string) (*DosenStatsResponse, error) {
+	var students int64
 	var totalMissions int64
 	var pendingReviews int64
 	var validatedTasks int64
 
+	missions := s.db.Model(&Mission{}).Where("creator_id = ?", dosenID)
+	pending := s.db.Table("mission_submissions").
+		Joins("JOIN missions ON missions.id = mission_submissions.mission_id").
+		Where("missions.creator_id = ? AND mission_submissions.status = ?", dosenID, "pending")
+	validated := s.db.Model(&MissionSubmission{}).Where("validated_by = ?", dosenID)
+
+	if classID > 0 {
+		class, err := s.findOwnedClass(classID, dosenID, role)
+		if err != nil {
+			return nil, err
+		}
+		students = class.StudentCount
+
+		inClass := "mission_id IN (SELECT mission_id FROM mission_classes WHERE class_id = ?)"
+		byStudents := "student_id IN (SELECT student_id FROM class_enrollments WHERE class_id = ?)"
+		missions = missions.Where("id IN (SELECT mission_id FROM mission_classes WHERE class_id = ?)", classID)
+		pending = pending.Where("mission_submissions."+inClass, classID).Where("mission_submissions."+byStudents, classID)
+		validated = validated.Where(inClass, classID).Where(byStudents, classID)
+	}
+
 	// Count missions created by dosen
-	if err := s.db.Model(&Mission{}).Where("creator_id = ?", dosenID).Count(&totalMissions).Error; err != nil {
+	if err := missions.Count(&totalMissions).Error; err != nil {
 		return nil, err
 	}
 
 	// Count pending submissions for missions created by this dosen
-	if err := s.db.Table("mission_submissions").
-		Joins("JOIN missions ON missions.id = mission_submissions.mission_id").
-		Where("missions.creator_id = ? AND mission_submissions.status = ?", dosenID, "pending").
-		Count(&pendingReviews).Error; err != nil {
+	if err := pending.Count(&pendingReviews).Error; err != nil {
 		return nil, err
 	}
 
 	// Count validated submissions by this dosen
-	if err := s.db.Model(&MissionSubmission{}).Where("validated_by = ?", dosenID).Count(&validatedTasks).Error; err != nil {
+	if err := validated.Count(&validatedTasks).Error; err != nil {
 		return nil, err
 	}
 
 	return &DosenStatsResponse{
+		ClassID:        classID,
+		Students:       students,
 		TotalMissions:  totalMissions,
 		PendingReviews: pendingReviews,
 		ValidatedTasks: validatedTasks,
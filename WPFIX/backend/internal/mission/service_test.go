@@ -0,0 +1,123 @@
+package mission_test
+
+import (
+	"testing"
+	"wallet-point/internal/database"
+	"wallet-point/internal/fraud"
+	"wallet-point/internal/mission"
+	"wallet-point/internal/wallet"
+
+	"gorm.io/gorm"
+)
+
+func newService(db *gorm.DB) *mission.MissionService {
+	fraudService := fraud.NewService(fraud.NewRepository(db), fraud.NewDetector(db), fraud.Policy{})
+	walletService := wallet.NewWalletService(wallet.NewWalletRepository(db), fraudService, db)
+	return mission.NewMissionService(mission.NewMissionRepository(db), walletService, db)
+}
+
+func balanceOf(t *testing.T, db *gorm.DB, walletID uint) int {
+	t.Helper()
+	var balance int
+	if err := db.Model(&wallet.Wallet{}).Where("id = ?", walletID).Pluck("balance", &balance).Error; err != nil {
+		t.Fatalf("failed to load balance: %v", err)
+	}
+	return balance
+}
+
+func TestResubmitAfterRejection(t *testing.T) {
+	db := database.OpenTestDB(t)
+	service := newService(db)
+
+	dosen, _ := database.CreateTestUser(t, db, "dosen", 0)
+	student, studentWallet := database.CreateTestUser(t, db, "mahasiswa", 0)
+
+	// The default of one attempt still lets a rejected task be fixed
+	m, err := service.CreateMission(&mission.CreateMissionRequest{Title: "Essay", Type: "task", Points: 50}, dosen.ID)
+	if err != nil {
+		t.Fatalf("failed to create mission: %v", err)
+	}
+	if m.MaxAttempts != 1 {
+		t.Fatalf("max attempts = %d, want the default of 1", m.MaxAttempts)
+	}
+
+	first, err := service.SubmitMission(&mission.SubmitMissionRequest{MissionID: m.ID, Content: "draft"}, student.ID)
+	if err != nil {
+		t.Fatalf("first submission: %v", err)
+	}
+	if err := service.ReviewSubmission(first.ID, &mission.ReviewSubmissionRequest{Status: "rejected", ReviewNote: "add sources"}, dosen.ID); err != nil {
+		t.Fatalf("reject: %v", err)
+	}
+
+	second, err := service.SubmitMission(&mission.SubmitMissionRequest{MissionID: m.ID, Content: "with sources"}, student.ID)
+	if err != nil {
+		t.Fatalf("resubmission after rejection: %v", err)
+	}
+	if second.Attempt != 2 {
+		t.Errorf("resubmission is attempt %d, want 2", second.Attempt)
+	}
+
+	if err := service.ReviewSubmission(second.ID, &mission.ReviewSubmissionRequest{Status: "approved"}, dosen.ID); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	err = service.ReviewSubmission(second.ID, &mission.ReviewSubmissionRequest{Status: "approved"}, dosen.ID)
+	if err == nil || err.Error() != "submission has already been reviewed" {
+		t.Errorf("second review returned %v, want already reviewed", err)
+	}
+	if balance := balanceOf(t, db, studentWallet.ID); balance != 50 {
+		t.Errorf("balance is %d after one approval of 50 points", balance)
+	}
+
+	if _, err := service.SubmitMission(&mission.SubmitMissionRequest{MissionID: m.ID, Content: "again"}, student.ID); err == nil {
+		t.Error("an approved task was submitted again")
+	}
+}
+
+func TestExportMissionNeedsOwnership(t *testing.T) {
+	db := database.OpenTestDB(t)
+	service := newService(db)
+
+	creator, _ := database.CreateTestUser(t, db, "dosen", 0)
+	other, _ := database.CreateTestUser(t, db, "dosen", 0)
+	admin, _ := database.CreateTestUser(t, db, "admin", 0)
+
+	m, err := service.CreateMission(&mission.CreateMissionRequest{
+		Title:  "Capitals",
+		Type:   "quiz",
+		Points: 10,
+		Questions: []mission.QuestionRequest{
+			{Question: "Capital of France?", Options: []string{"Paris", "Berlin"}, Answer: "Paris"},
+		},
+	}, creator.ID)
+	if err != nil {
+		t.Fatalf("failed to create mission: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		userID  uint
+		role    string
+		wantErr string
+	}{
+		{"creator", creator.ID, "dosen", ""},
+		{"another dosen", other.ID, "dosen", "you do not own this mission"},
+		{"admin", admin.ID, "admin", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _, err := service.ExportMission(m.ID, tt.userID, tt.role, mission.FormatJSON)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("export: %v", err)
+			}
+			if len(data) == 0 {
+				t.Error("export is empty")
+			}
+		})
+	}
+}
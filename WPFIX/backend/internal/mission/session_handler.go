@@ -0,0 +1,186 @@
+package mission
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StartAttempt handles starting a quiz attempt
+// @Summary Start quiz attempt
+// @Description Open an attempt session with a server-tracked deadline. Questions and options come in the order drawn for the attempt; answer options by index in that order or by text. An attempt already in progress is returned instead.
+// @Tags Mahasiswa - Missions
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Mission ID"
+// @Success 201 {object} utils.Response{data=AttemptView}
+// @Success 200 {object} utils.Response{data=AttemptView}
+// @Failure 400 {object} utils.Response
+// @Router /mahasiswa/missions/{id}/start [post]
+func (h *MissionHandler) StartAttempt(c *gin.Context) {
+	studentID := c.GetUint("user_id")
+	missionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid mission ID", nil)
+		return
+	}
+
+	view, started, err := h.service.StartAttempt(uint(missionID), studentID)
+	if err != nil {
+		h.attemptError(c, err)
+		return
+	}
+
+	if !started {
+		utils.SuccessResponse(c, http.StatusOK, "Quiz attempt in progress", view)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Quiz attempt started", view)
+
+	// Log activity
+	details := fmt.Sprintf("Student started attempt %d of mission ID %d", view.Attempt, view.MissionID)
+	if view.ExpiresAt != nil {
+		details += ", due " + view.ExpiresAt.Format("2006-01-02 15:04:05")
+	}
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    studentID,
+		Action:    "START_ATTEMPT",
+		Entity:    "MISSION",
+		EntityID:  view.MissionID,
+		Details:   details,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// GetAttempt handles resuming a quiz attempt
+// @Summary Get quiz attempt
+// @Description Get the attempt in progress with its saved answers and remaining time
+// @Tags Mahasiswa - Missions
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Mission ID"
+// @Success 200 {object} utils.Response{data=AttemptView}
+// @Failure 404 {object} utils.Response
+// @Router /mahasiswa/missions/{id}/attempt [get]
+func (h *MissionHandler) GetAttempt(c *gin.Context) {
+	missionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid mission ID", nil)
+		return
+	}
+
+	view, err := h.service.GetAttempt(uint(missionID), c.GetUint("user_id"))
+	if err != nil {
+		h.attemptError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Quiz attempt retrieved successfully", view)
+}
+
+// SaveAnswers handles autosaving answers
+// @Summary Autosave answers
+// @Description Save answers to the attempt in progress; a later answer to the same question replaces the earlier one
+// @Tags Mahasiswa - Missions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Mission ID"
+// @Param request body SaveAnswersRequest true "Answers"
+// @Success 200 {object} utils.Response{data=AttemptView}
+// @Failure 400 {object} utils.Response
+// @Router /mahasiswa/missions/{id}/attempt/answers [put]
+func (h *MissionHandler) SaveAnswers(c *gin.Context) {
+	missionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid mission ID", nil)
+		return
+	}
+
+	var req SaveAnswersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	view, err := h.service.SaveAnswers(uint(missionID), c.GetUint("user_id"), req.Answers)
+	if err != nil {
+		h.attemptError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Answers saved", view)
+}
+
+// SubmitAttempt handles submitting a quiz attempt
+// @Summary Submit quiz attempt
+// @Description Submit the attempt in progress for grading. Answers sent here are merged over the saved ones. After the time limit the submission is rejected and the saved answers are submitted instead.
+// @Tags Mahasiswa - Missions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Mission ID"
+// @Param request body SubmitAttemptRequest false "Last answers"
+// @Success 201 {object} utils.Response{data=MissionSubmission}
+// @Failure 400 {object} utils.Response
+// @Router /mahasiswa/missions/{id}/attempt/submit [post]
+func (h *MissionHandler) SubmitAttempt(c *gin.Context) {
+	studentID := c.GetUint("user_id")
+	missionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid mission ID", nil)
+		return
+	}
+
+	var req SubmitAttemptRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ValidationErrorResponse(c, err.Error())
+			return
+		}
+	}
+
+	submission, err := h.service.SubmitAttempt(uint(missionID), studentID, req.Answers)
+	if err != nil {
+		h.attemptError(c, err)
+		return
+	}
+
+	message := "Quiz submitted successfully"
+	if submission.Status == "approved" {
+		message = "Quiz passed and reward credited"
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, message, submission)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    studentID,
+		Action:    "SUBMIT_MISSION",
+		Entity:    "SUBMISSION",
+		EntityID:  submission.ID,
+		Details:   fmt.Sprintf("Student submitted quiz attempt %d, auto-graded %d/%d (%.2f%%), %s", submission.Attempt, submission.Score, submission.MaxScore, submission.Percentage, submission.Status),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// attemptError maps attempt session errors to HTTP status codes
+func (h *MissionHandler) attemptError(c *gin.Context, err error) {
+	statusCode := http.StatusBadRequest
+	switch err.Error() {
+	case "mission not found", "no quiz attempt in progress":
+		statusCode = http.StatusNotFound
+	case errTimeUp.Error():
+		statusCode = http.StatusGone
+	case errNotInClass.Error():
+		statusCode = http.StatusForbidden
+	}
+	utils.ErrorResponse(c, statusCode, err.Error(), nil)
+}
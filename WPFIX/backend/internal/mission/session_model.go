@@ -0,0 +1,105 @@
+package mission
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Attempt session statuses
+const (
+	SessionInProgress = "in_progress"
+	SessionSubmitted  = "submitted"
+	SessionExpired    = "expired" // Closed by the server when time ran out
+)
+
+// SessionGracePeriod is allowed past the time limit for answers still in flight
+const SessionGracePeriod = 30 * time.Second
+
+// SessionCloseInterval is how often expired attempt sessions are closed
+const SessionCloseInterval = time.Minute
+
+// AttemptLayout is the question order and option order drawn for one attempt
+type AttemptLayout struct {
+	Questions []uint         `json:"questions"`
+	Options   map[uint][]int `json:"options,omitempty"` // Shown position -> original option index
+}
+
+func (l AttemptLayout) Value() (driver.Value, error) {
+	return json.Marshal(l)
+}
+
+func (l *AttemptLayout) Scan(value interface{}) error {
+	if value == nil {
+		*l = AttemptLayout{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, l)
+}
+
+// SavedAnswers are the answers autosaved during an attempt, stored as JSON
+type SavedAnswers []AnswerSubmission
+
+func (sa SavedAnswers) Value() (driver.Value, error) {
+	if sa == nil {
+		return nil, nil
+	}
+	return json.Marshal(sa)
+}
+
+func (sa *SavedAnswers) Scan(value interface{}) error {
+	if value == nil {
+		*sa = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, sa)
+}
+
+// AttemptSession is a student's quiz attempt in progress. The server keeps the
+// start time and deadline, so the time limit cannot be dodged by the client.
+type AttemptSession struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	MissionID uint      `json:"mission_id" gorm:"not null;index;uniqueIndex:idx_session_attempt"`
+	StudentID uint      `json:"student_id" gorm:"not null;index;uniqueIndex:idx_session_attempt"`
+	Attempt   int       `json:"attempt" gorm:"not null;uniqueIndex:idx_session_attempt"`
+	Status    string    `json:"status" gorm:"type:enum('in_progress','submitted','expired');default:'in_progress';index"`
+	StartedAt time.Time `json:"started_at" gorm:"not null"`
+	// Start plus the time limit, capped by the mission deadline; nil when neither is set
+	ExpiresAt    *time.Time    `json:"expires_at" gorm:"index"`
+	Layout       AttemptLayout `json:"-" gorm:"type:json"`
+	Answers      SavedAnswers  `json:"answers" gorm:"type:json"` // As shown to the student, before unshuffling
+	SubmissionID *uint         `json:"submission_id"`
+	SubmittedAt  *time.Time    `json:"submitted_at"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+func (AttemptSession) TableName() string {
+	return "mission_attempt_sessions"
+}
+
+// AttemptView is an attempt session as shown to the student taking it, with
+// the questions in the order and option order drawn for the attempt
+type AttemptView struct {
+	AttemptSession
+	TimeLimitMinutes int               `json:"time_limit_minutes"`
+	RemainingSeconds *int64            `json:"remaining_seconds"` // nil when there is no time limit
+	Questions        []StudentQuestion `json:"questions"`
+}
+
+type SaveAnswersRequest struct {
+	Answers []AnswerSubmission `json:"answers" binding:"required,min=1"`
+}
+
+type SubmitAttemptRequest struct {
+	Answers []AnswerSubmission `json:"answers"` // Merged over the autosaved answers
+}
@@ -0,0 +1,423 @@
+package mission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+	"wallet-point/utils"
+
+	"gorm.io/gorm"
+)
+
+var errTimeUp = errors.New("the time limit has passed; your saved answers were submitted")
+
+// requiresSession reports whether a quiz must be taken in an attempt session
+// rather than submitted in one shot
+func (m *Mission) requiresSession() bool {
+	return m.TimeLimitMinutes > 0 || m.ShuffleQuestions || m.ShuffleOptions || len(m.PoolRules) > 0
+}
+
+// expired reports whether an attempt's time, grace period included, has run out
+func (a *AttemptSession) expired(now time.Time) bool {
+	return a.ExpiresAt != nil && now.After(a.ExpiresAt.Add(SessionGracePeriod))
+}
+
+// StartAttempt opens a quiz attempt session with a server-side deadline, or
+// returns the student's attempt already in progress. The second result tells
+// whether a new attempt was started.
+func (s *MissionService) StartAttempt(missionID, studentID uint) (*AttemptView, bool, error) {
+	mission, err := s.repo.FindByID(missionID)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := s.checkClassAccess(mission, studentID); err != nil {
+		return nil, false, err
+	}
+	if mission.Type != "quiz" || (len(mission.Questions) == 0 && len(mission.PoolRules) == 0) {
+		return nil, false, errors.New("only quizzes with questions can be started")
+	}
+
+	now := s.db.NowFunc()
+	if session, err := s.repo.FindOpenSession(missionID, studentID); err == nil {
+		taken, err := s.sessionMission(mission, session)
+		if err != nil {
+			return nil, false, err
+		}
+		if !session.expired(now) {
+			return s.attemptView(taken, session, now), false, nil
+		}
+		// Time ran out before the closer got to it; the saved answers count
+		// as the attempt before a new one may start
+		session.Status = SessionExpired
+		if _, err := s.closeSession(taken, session); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if mission.Status != "active" {
+		return nil, false, errors.New("mission is not active")
+	}
+	if mission.CloseAt != nil && !mission.CloseAt.After(now) {
+		return nil, false, errors.New("mission is closed")
+	}
+	if mission.Deadline != nil && mission.Deadline.Before(now) {
+		return nil, false, errors.New("mission deadline has passed")
+	}
+
+	attempts, err := s.repo.FindAttemptsWithTx(nil, missionID, studentID)
+	if err != nil {
+		return nil, false, err
+	}
+	attempt, err := nextAttempt(mission, attempts)
+	if err != nil {
+		return nil, false, err
+	}
+
+	session := &AttemptSession{
+		MissionID: missionID,
+		StudentID: studentID,
+		Attempt:   attempt,
+		Status:    SessionInProgress,
+		StartedAt: now,
+	}
+	if mission.TimeLimitMinutes > 0 {
+		expiresAt := now.Add(time.Duration(mission.TimeLimitMinutes) * time.Minute)
+		session.ExpiresAt = &expiresAt
+	}
+	for _, closes := range []*time.Time{mission.Deadline, mission.CloseAt} {
+		if closes != nil && (session.ExpiresAt == nil || closes.Before(*session.ExpiresAt)) {
+			deadline := *closes
+			session.ExpiresAt = &deadline
+		}
+	}
+
+	// Draw the attempt's bank questions alongside the session, then the order
+	// of all its questions
+	taken := mission
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.repo.CreateSessionWithTx(tx, session); err != nil {
+			return err
+		}
+
+		drawn, err := s.drawPool(tx, mission, session.ID)
+		if err != nil {
+			return err
+		}
+		if len(drawn) > 0 {
+			copied := *mission
+			copied.Questions = append(append([]MissionQuestion{}, mission.Questions...), drawn...)
+			taken = &copied
+		}
+
+		session.Layout = drawLayout(taken)
+		return s.repo.UpdateSessionWithTx(tx, session.ID, map[string]interface{}{"layout": session.Layout})
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return s.attemptView(taken, session, now), true, nil
+}
+
+// GetAttempt returns the student's quiz attempt in progress
+func (s *MissionService) GetAttempt(missionID, studentID uint) (*AttemptView, error) {
+	mission, session, err := s.openSession(missionID, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.db.NowFunc()
+	if session.expired(now) {
+		session.Status = SessionExpired
+		if _, err := s.closeSession(mission, session); err != nil {
+			return nil, err
+		}
+		return nil, errTimeUp
+	}
+
+	return s.attemptView(mission, session, now), nil
+}
+
+// SaveAnswers autosaves answers to an attempt in progress, replacing any
+// earlier answer to the same question
+func (s *MissionService) SaveAnswers(missionID, studentID uint, answers []AnswerSubmission) (*AttemptView, error) {
+	mission, session, err := s.openSession(missionID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateAnswers(mission, answers); err != nil {
+		return nil, err
+	}
+
+	now := s.db.NowFunc()
+	if session.expired(now) {
+		session.Status = SessionExpired
+		if _, err := s.closeSession(mission, session); err != nil {
+			return nil, err
+		}
+		return nil, errTimeUp
+	}
+
+	session.Answers = mergeAnswers(session.Answers, answers)
+	if err := s.repo.UpdateSessionWithTx(s.db, session.ID, map[string]interface{}{"answers": session.Answers}); err != nil {
+		return nil, err
+	}
+
+	return s.attemptView(mission, session, now), nil
+}
+
+// SubmitAttempt closes an attempt in progress and grades it
+func (s *MissionService) SubmitAttempt(missionID, studentID uint, answers []AnswerSubmission) (*MissionSubmission, error) {
+	mission, session, err := s.openSession(missionID, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.submitSession(mission, session, answers)
+}
+
+// submitSession submits an attempt with the given answers merged over the
+// saved ones. Past the time limit only the saved answers count.
+func (s *MissionService) submitSession(mission *Mission, session *AttemptSession, answers []AnswerSubmission) (*MissionSubmission, error) {
+	if session.expired(s.db.NowFunc()) {
+		session.Status = SessionExpired
+		if _, err := s.closeSession(mission, session); err != nil {
+			return nil, err
+		}
+		return nil, errTimeUp
+	}
+	if err := validateAnswers(mission, answers); err != nil {
+		return nil, err
+	}
+
+	session.Answers = mergeAnswers(session.Answers, answers)
+	session.Status = SessionSubmitted
+	return s.closeSession(mission, session)
+}
+
+// closeSession submits a session's saved answers, mapped back from the
+// shuffled options shown to the student
+func (s *MissionService) closeSession(mission *Mission, session *AttemptSession) (*MissionSubmission, error) {
+	req := &SubmitMissionRequest{
+		MissionID: mission.ID,
+		Answers:   unshuffleAnswers(mission, session),
+	}
+	return s.submitAttempt(mission, session.StudentID, req, session)
+}
+
+// openSession finds a mission and the student's attempt in progress at it
+func (s *MissionService) openSession(missionID, studentID uint) (*Mission, *AttemptSession, error) {
+	mission, err := s.repo.FindByID(missionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	session, err := s.repo.FindOpenSession(missionID, studentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	mission, err = s.sessionMission(mission, session)
+	if err != nil {
+		return nil, nil, err
+	}
+	return mission, session, nil
+}
+
+// CloseExpiredSessions submits the saved answers of every attempt whose time
+// has run out
+func (s *MissionService) CloseExpiredSessions() {
+	ids, err := s.repo.FindExpiredSessionIDs(s.db.NowFunc().Add(-SessionGracePeriod))
+	if err != nil {
+		log.Printf("[mission] failed to find expired quiz attempts: %v", err)
+		return
+	}
+
+	for _, id := range ids {
+		if err := s.closeExpiredSession(id); err != nil {
+			log.Printf("[mission] failed to close quiz attempt %d: %v", id, err)
+		}
+	}
+}
+
+func (s *MissionService) closeExpiredSession(sessionID uint) error {
+	session, err := s.repo.FindSessionByID(sessionID)
+	if err != nil {
+		return err
+	}
+	// Submitted since it was listed
+	if session.Status != SessionInProgress {
+		return nil
+	}
+
+	mission, err := s.repo.FindByID(session.MissionID)
+	if err != nil {
+		if err.Error() == "mission not found" {
+			// Deleted mid-attempt; nothing is left to grade
+			return s.repo.UpdateSessionWithTx(s.db, session.ID, map[string]interface{}{"status": SessionExpired})
+		}
+		return err
+	}
+
+	mission, err = s.sessionMission(mission, session)
+	if err != nil {
+		return err
+	}
+
+	session.Status = SessionExpired
+	_, err = s.closeSession(mission, session)
+	return err
+}
+
+// RunSessionCloser closes expired quiz attempts every interval until ctx is cancelled
+func (s *MissionService) RunSessionCloser(ctx context.Context, interval time.Duration) {
+	utils.RunEvery(ctx, interval, s.CloseExpiredSessions)
+}
+
+// drawLayout draws the question order and option orders of a new attempt
+func drawLayout(mission *Mission) AttemptLayout {
+	layout := AttemptLayout{}
+	for _, q := range mission.Questions {
+		layout.Questions = append(layout.Questions, q.ID)
+	}
+	if mission.ShuffleQuestions {
+		rand.Shuffle(len(layout.Questions), func(i, j int) {
+			layout.Questions[i], layout.Questions[j] = layout.Questions[j], layout.Questions[i]
+		})
+	}
+
+	if mission.ShuffleOptions {
+		layout.Options = make(map[uint][]int)
+		for _, q := range mission.Questions {
+			if shufflesOptions(&q) {
+				layout.Options[q.ID] = rand.Perm(len(q.Options))
+			}
+		}
+	}
+
+	return layout
+}
+
+// shufflesOptions reports whether a question's options may be shown in any
+// order. Matching answers go by option position, so those options stay put.
+func shufflesOptions(q *MissionQuestion) bool {
+	switch q.Type {
+	case QuestionNumeric, QuestionShortText, QuestionMatching:
+		return false
+	}
+	return len(q.Options) > 1
+}
+
+// attemptView shows a session's questions in the order drawn for it
+func (s *MissionService) attemptView(mission *Mission, session *AttemptSession, now time.Time) *AttemptView {
+	view := &AttemptView{
+		AttemptSession:   *session,
+		TimeLimitMinutes: mission.TimeLimitMinutes,
+	}
+	if session.ExpiresAt != nil {
+		remaining := int64(math.Max(0, session.ExpiresAt.Sub(now).Seconds()))
+		view.RemainingSeconds = &remaining
+	}
+
+	questions := make(map[uint]MissionQuestion, len(mission.Questions))
+	for _, q := range mission.Questions {
+		questions[q.ID] = q
+	}
+	for _, id := range session.Layout.Questions {
+		if q, ok := questions[id]; ok {
+			view.Questions = append(view.Questions, shownQuestion(q, session.Layout))
+			delete(questions, id)
+		}
+	}
+	// Questions added after the attempt started come last
+	for _, q := range mission.Questions {
+		if _, ok := questions[q.ID]; ok {
+			view.Questions = append(view.Questions, shownQuestion(q, session.Layout))
+		}
+	}
+
+	return view
+}
+
+// shownQuestion is a question as shown in an attempt, options shuffled
+func shownQuestion(q MissionQuestion, layout AttemptLayout) StudentQuestion {
+	question := newStudentQuestion(q)
+	if order, ok := layout.Options[q.ID]; ok && len(order) == len(q.Options) {
+		question.Options = make(JSONOptions, len(order))
+		for shown, original := range order {
+			question.Options[shown] = q.Options[original]
+		}
+	}
+	return question
+}
+
+// unshuffleAnswers maps option indexes in a session's answers, given in the
+// shuffled order shown to the student, back to the option texts
+func unshuffleAnswers(mission *Mission, session *AttemptSession) []AnswerSubmission {
+	options := make(map[uint]JSONOptions, len(mission.Questions))
+	for _, q := range mission.Questions {
+		options[q.ID] = q.Options
+	}
+
+	answers := make([]AnswerSubmission, 0, len(session.Answers))
+	for _, a := range session.Answers {
+		order := session.Layout.Options[a.QuestionID]
+		if opts := options[a.QuestionID]; len(order) > 0 && len(order) == len(opts) {
+			a.Answer = unshuffleOption(opts, order, a.Answer)
+			given := a.Answers
+			a.Answers = make([]string, len(given))
+			for i, v := range given {
+				a.Answers[i] = unshuffleOption(opts, order, v)
+			}
+		}
+		answers = append(answers, a)
+	}
+	return answers
+}
+
+func unshuffleOption(options JSONOptions, order []int, value string) string {
+	if hasOptionText(options, value) {
+		return value
+	}
+	if index, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && index >= 0 && index < len(order) {
+		return options[order[index]]
+	}
+	return value
+}
+
+// validateAnswers checks that every answer is to one of the quiz's questions
+func validateAnswers(mission *Mission, answers []AnswerSubmission) error {
+	questions := make(map[uint]bool, len(mission.Questions))
+	for _, q := range mission.Questions {
+		questions[q.ID] = true
+	}
+	for _, a := range answers {
+		if !questions[a.QuestionID] {
+			return fmt.Errorf("question %d is not part of this quiz", a.QuestionID)
+		}
+	}
+	return nil
+}
+
+// mergeAnswers replaces saved answers with newer ones to the same question
+func mergeAnswers(saved SavedAnswers, answers []AnswerSubmission) SavedAnswers {
+	merged := append(SavedAnswers{}, saved...)
+	for _, a := range answers {
+		replaced := false
+		for i := range merged {
+			if merged[i].QuestionID == a.QuestionID {
+				merged[i] = a
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, a)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,71 @@
+package mission
+
+import "sort"
+
+// StudentQuestion is a quiz question as shown to students, without its answer
+type StudentQuestion struct {
+	ID        uint        `json:"id"`
+	MissionID uint        `json:"mission_id"`
+	Type      string      `json:"type"`
+	Question  string      `json:"question"`
+	Options   JSONOptions `json:"options"`
+	Points    int         `json:"points"`
+
+	// Matching questions: the answers to pair with the options, sorted so
+	// their order gives nothing away
+	MatchOptions JSONOptions `json:"match_options,omitempty"`
+}
+
+// StudentMission is a mission as shown to students. Its Questions shadow the
+// embedded mission's so the answer key is never serialised.
+type StudentMission struct {
+	Mission
+	Questions []StudentQuestion `json:"questions,omitempty"`
+}
+
+// NewStudentMission strips the answers from a mission's questions
+func NewStudentMission(mission *Mission) *StudentMission {
+	view := &StudentMission{Mission: *mission}
+	view.Mission.Questions = nil
+	for _, q := range mission.Questions {
+		view.Questions = append(view.Questions, newStudentQuestion(q))
+	}
+	return view
+}
+
+// newStudentQuestion strips the answer key from a question
+func newStudentQuestion(q MissionQuestion) StudentQuestion {
+	question := StudentQuestion{
+		ID:        q.ID,
+		MissionID: q.MissionID,
+		Type:      q.Type,
+		Question:  q.Question,
+		Options:   q.Options,
+		Points:    q.Points,
+	}
+	switch q.Type {
+	case QuestionOrdering:
+		// Items may have been entered in the right order
+		question.Options = sortedOptions(q.Options)
+	case QuestionMatching:
+		question.MatchOptions = sortedOptions(q.CorrectAnswers)
+	}
+	return question
+}
+
+// missionView shapes a mission for the requester's role: students get the
+// answerless view, dosen and admins the full mission
+func missionView(mission *Mission, role string) interface{} {
+	if role == "mahasiswa" {
+		return NewStudentMission(mission)
+	}
+	return mission
+}
+
+// sortedOptions returns a sorted copy of options
+func sortedOptions(options JSONOptions) JSONOptions {
+	sorted := make(JSONOptions, len(options))
+	copy(sorted, options)
+	sort.Strings(sorted)
+	return sorted
+}
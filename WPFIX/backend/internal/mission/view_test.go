@@ -0,0 +1,132 @@
+package mission
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// quizWithEveryType builds a quiz with one question of each type, with
+// options in an order that is not sorted
+func quizWithEveryType() *Mission {
+	return &Mission{
+		ID:    1,
+		Title: "Every question type",
+		Type:  "quiz",
+		Questions: []MissionQuestion{
+			{ID: 1, Type: QuestionSingleChoice, Question: "Capital of France?", Options: JSONOptions{"Paris", "Berlin", "Amsterdam"}, Answer: "Paris", Points: 1},
+			{ID: 2, Type: QuestionMultiSelect, Question: "Prime numbers?", Options: JSONOptions{"7", "4", "2"}, Answer: "2,7", CorrectAnswers: JSONOptions{"2", "7"}, Points: 2},
+			{ID: 3, Type: QuestionNumeric, Question: "Value of pi?", Answer: "3.14", Tolerance: 0.01, Points: 1},
+			{ID: 4, Type: QuestionShortText, Question: "Language of this repo?", Answer: "go", CorrectAnswers: JSONOptions{"go", "golang"}, MatchMode: "ignore_case", Points: 1},
+			{ID: 5, Type: QuestionOrdering, Question: "Order the planets from the sun", Options: JSONOptions{"Mercury", "Venus", "Earth"}, Answer: "Mercury,Venus,Earth", CorrectAnswers: JSONOptions{"Mercury", "Venus", "Earth"}, Points: 3},
+			{ID: 6, Type: QuestionMatching, Question: "Match the capitals", Options: JSONOptions{"Japan", "Italy"}, Answer: "Tokyo,Rome", CorrectAnswers: JSONOptions{"Tokyo", "Rome"}, Points: 2},
+		},
+	}
+}
+
+// serialisedQuestions renders a mission view as JSON and returns its questions
+func serialisedQuestions(t *testing.T, view interface{}) []map[string]interface{} {
+	t.Helper()
+
+	data, err := json.Marshal(view)
+	if err != nil {
+		t.Fatalf("failed to serialise mission: %v", err)
+	}
+	var decoded struct {
+		Questions []map[string]interface{} `json:"questions"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to decode mission: %v", err)
+	}
+	return decoded.Questions
+}
+
+func options(q map[string]interface{}, key string) []string {
+	values, _ := q[key].([]interface{})
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		result = append(result, v.(string))
+	}
+	return result
+}
+
+func TestMissionViewHidesAnswersFromStudents(t *testing.T) {
+	answerKeys := []string{"answer", "correct_answers", "tolerance", "match_mode"}
+
+	questions := serialisedQuestions(t, missionView(quizWithEveryType(), "mahasiswa"))
+	if len(questions) != 6 {
+		t.Fatalf("got %d questions, want 6", len(questions))
+	}
+	for _, q := range questions {
+		for _, key := range answerKeys {
+			if _, ok := q[key]; ok {
+				t.Errorf("%s question serialised %q for a student", q["type"], key)
+			}
+		}
+	}
+}
+
+func TestMissionViewKeepsAnswersForStaff(t *testing.T) {
+	for _, role := range []string{"dosen", "admin"} {
+		questions := serialisedQuestions(t, missionView(quizWithEveryType(), role))
+		if len(questions) != 6 {
+			t.Fatalf("%s: got %d questions, want 6", role, len(questions))
+		}
+
+		for _, q := range questions {
+			for _, key := range []string{"answer", "tolerance"} {
+				if _, ok := q[key]; !ok {
+					t.Errorf("%s: %s question is missing %q", role, q["type"], key)
+				}
+			}
+			if q["type"] != QuestionSingleChoice && q["type"] != QuestionNumeric {
+				if _, ok := q["correct_answers"]; !ok {
+					t.Errorf("%s: %s question is missing correct_answers", role, q["type"])
+				}
+			}
+		}
+
+		shortText := questions[3]
+		if shortText["match_mode"] != "ignore_case" {
+			t.Errorf("%s: short_text question has match_mode %v, want ignore_case", role, shortText["match_mode"])
+		}
+		if numeric := questions[2]; numeric["tolerance"] != 0.01 {
+			t.Errorf("%s: numeric question has tolerance %v, want 0.01", role, numeric["tolerance"])
+		}
+	}
+}
+
+func TestMissionViewSortsOptionsForStudents(t *testing.T) {
+	mission := quizWithEveryType()
+	questions := serialisedQuestions(t, missionView(mission, "mahasiswa"))
+
+	tests := []struct {
+		index int
+		key   string
+		want  []string
+	}{
+		// Choices keep the order the dosen wrote them in
+		{0, "options", []string{"Paris", "Berlin", "Amsterdam"}},
+		{1, "options", []string{"7", "4", "2"}},
+		// Ordering items and matching answers would give the answer away
+		{4, "options", []string{"Earth", "Mercury", "Venus"}},
+		{5, "options", []string{"Japan", "Italy"}},
+		{5, "match_options", []string{"Rome", "Tokyo"}},
+	}
+	for _, tt := range tests {
+		q := questions[tt.index]
+		if got := options(q, tt.key); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s question %s = %v, want %v", q["type"], tt.key, got, tt.want)
+		}
+	}
+	for _, i := range []int{0, 1, 2, 3, 4} {
+		if _, ok := questions[i]["match_options"]; ok {
+			t.Errorf("%s question has match_options", questions[i]["type"])
+		}
+	}
+
+	// The mission itself is left as it was
+	if got := mission.Questions[4].Options; !reflect.DeepEqual(got, JSONOptions{"Mercury", "Venus", "Earth"}) {
+		t.Errorf("ordering options were reordered in place: %v", got)
+	}
+}
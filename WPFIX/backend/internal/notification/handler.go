@@ -0,0 +1,137 @@
+package notification
+
+import (
+	"net/http"
+	"strconv"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetNotifications handles listing the user's notifications
+// @Summary Get notifications
+// @Description Get the signed-in user's in-app notifications, newest first
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Param unread query bool false "Only unread notifications"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.Response{data=ListResponse}
+// @Router /notifications [get]
+func (h *Handler) GetNotifications(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	unreadOnly, _ := strconv.ParseBool(c.Query("unread"))
+
+	response, err := h.service.GetNotifications(ListParams{
+		UserID:     c.GetUint("user_id"),
+		UnreadOnly: unreadOnly,
+		Page:       page,
+		Limit:      limit,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve notifications", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Notifications retrieved successfully", response)
+}
+
+// GetUnreadCount handles counting the user's unread notifications
+// @Summary Get unread notification count
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /notifications/unread-count [get]
+func (h *Handler) GetUnreadCount(c *gin.Context) {
+	count, err := h.service.CountUnread(c.GetUint("user_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to count notifications", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Unread count retrieved successfully", gin.H{"unread": count})
+}
+
+// MarkRead handles marking a notification as read
+// @Summary Mark notification read
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Notification ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /notifications/{id}/read [post]
+func (h *Handler) MarkRead(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid notification ID", nil)
+		return
+	}
+
+	if err := h.service.MarkRead(c.GetUint("user_id"), uint(id)); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "notification not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Notification marked as read", nil)
+}
+
+// MarkAllRead handles marking all notifications as read
+// @Summary Mark all notifications read
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /notifications/read-all [post]
+func (h *Handler) MarkAllRead(c *gin.Context) {
+	updated, err := h.service.MarkAllRead(c.GetUint("user_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update notifications", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Notifications marked as read", gin.H{"updated": updated})
+}
+
+// Delete handles removing a notification
+// @Summary Delete notification
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Notification ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /notifications/{id} [delete]
+func (h *Handler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid notification ID", nil)
+		return
+	}
+
+	if err := h.service.Delete(c.GetUint("user_id"), uint(id)); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "notification not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Notification deleted successfully", nil)
+}
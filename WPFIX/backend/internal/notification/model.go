@@ -0,0 +1,44 @@
+package notification
+
+import "time"
+
+// Notification types
+const (
+	TypeBackInStock = "back_in_stock"
+	TypePriceDrop   = "price_drop"
+	TypeOutbid      = "auction_outbid"
+	TypeAuctionWon  = "auction_won"
+)
+
+// Notification is an in-app message shown to a single user
+type Notification struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	UserID        uint       `json:"user_id" gorm:"not null;index:idx_notification_user_read"`
+	Type          string     `json:"type" gorm:"size:50;not null"`
+	Title         string     `json:"title" gorm:"size:255;not null"`
+	Message       string     `json:"message" gorm:"type:text"`
+	ReferenceType string     `json:"reference_type,omitempty" gorm:"size:50"` // e.g. product
+	ReferenceID   *uint      `json:"reference_id"`
+	ReadAt        *time.Time `json:"read_at" gorm:"index:idx_notification_user_read"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+type ListParams struct {
+	UserID     uint
+	UnreadOnly bool
+	Page       int
+	Limit      int
+}
+
+type ListResponse struct {
+	Notifications []Notification `json:"notifications"`
+	Unread        int64          `json:"unread"`
+	Total         int64          `json:"total"`
+	Page          int            `json:"page"`
+	Limit         int            `json:"limit"`
+	TotalPages    int            `json:"total_pages"`
+}
@@ -0,0 +1,94 @@
+package notification
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// CreateBatch stores notifications, inside tx when one is given
+func (r *Repository) CreateBatch(tx *gorm.DB, notifications []Notification) error {
+	if tx == nil {
+		tx = r.db
+	}
+	if len(notifications) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(notifications, 200).Error
+}
+
+// FindAll gets a user's notifications, newest first
+func (r *Repository) FindAll(params ListParams) ([]Notification, int64, error) {
+	var notifications []Notification
+	var total int64
+
+	query := r.db.Model(&Notification{}).Where("user_id = ?", params.UserID)
+	if params.UnreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Order("created_at DESC").Limit(params.Limit).Offset(offset).Find(&notifications).Error
+	return notifications, total, err
+}
+
+// CountUnread counts a user's unread notifications
+func (r *Repository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// MarkRead marks one of a user's notifications as read
+func (r *Repository) MarkRead(userID, id uint, at time.Time) error {
+	result := r.db.Model(&Notification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Where("read_at IS NULL").
+		Update("read_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		var count int64
+		if err := r.db.Model(&Notification{}).Where("id = ? AND user_id = ?", id, userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return errors.New("notification not found")
+		}
+	}
+	return nil
+}
+
+// MarkAllRead marks all of a user's notifications as read
+func (r *Repository) MarkAllRead(userID uint, at time.Time) (int64, error) {
+	result := r.db.Model(&Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", at)
+	return result.RowsAffected, result.Error
+}
+
+// Delete removes one of a user's notifications
+func (r *Repository) Delete(userID, id uint) error {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&Notification{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("notification not found")
+	}
+	return nil
+}
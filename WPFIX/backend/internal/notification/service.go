@@ -0,0 +1,72 @@
+package notification
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Notify stores notifications for their users. Like fraud screening it fails
+// open: a notification that cannot be stored is logged and never fails the
+// operation that triggered it.
+func (s *Service) Notify(tx *gorm.DB, notifications ...Notification) {
+	if err := s.repo.CreateBatch(tx, notifications); err != nil {
+		log.Printf("[notification] failed to store %d notifications: %v", len(notifications), err)
+	}
+}
+
+func (s *Service) GetNotifications(params ListParams) (*ListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	}
+
+	notifications, total, err := s.repo.FindAll(params)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := s.repo.CountUnread(params.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(params.Limit)))
+
+	return &ListResponse{
+		Notifications: notifications,
+		Unread:        unread,
+		Total:         total,
+		Page:          params.Page,
+		Limit:         params.Limit,
+		TotalPages:    totalPages,
+	}, nil
+}
+
+func (s *Service) CountUnread(userID uint) (int64, error) {
+	return s.repo.CountUnread(userID)
+}
+
+func (s *Service) MarkRead(userID, id uint) error {
+	return s.repo.MarkRead(userID, id, time.Now())
+}
+
+func (s *Service) MarkAllRead(userID uint) (int64, error) {
+	return s.repo.MarkAllRead(userID, time.Now())
+}
+
+func (s *Service) Delete(userID, id uint) error {
+	return s.repo.Delete(userID, id)
+}
@@ -0,0 +1,270 @@
+package paymentrequest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"wallet-point/internal/audit"
+	"wallet-point/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles HTTP requests for payment requests
+type Handler struct {
+	service      *Service
+	auditService *audit.AuditService
+}
+
+// NewHandler creates a new payment request handler
+func NewHandler(service *Service, auditService *audit.AuditService) *Handler {
+	return &Handler{service: service, auditService: auditService}
+}
+
+// Create handles POST /mahasiswa/requests
+// @Summary Create a payment request
+// @Description Ask one or more students to pay a share of a group purchase
+// @Tags Payment Requests
+// @Accept json
+// @Produce json
+// @Param request body CreateRequest true "Payment request details"
+// @Success 201 {object} utils.Response{data=PaymentRequest}
+// @Failure 400 {object} utils.Response
+// @Security BearerAuth
+// @Router /mahasiswa/requests [post]
+func (h *Handler) Create(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req CreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	request, err := h.service.CreateRequest(userID, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Payment request created successfully", request)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "CREATE_PAYMENT_REQUEST",
+		Entity:    "PAYMENT_REQUEST",
+		EntityID:  request.ID,
+		Details:   fmt.Sprintf("Requested %d points from %d payers: %s", request.TotalAmount, len(request.Shares), request.Title),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// GetMyRequests handles GET /mahasiswa/requests
+// @Summary Get my payment requests
+// @Description Get payment requests created by the current user with their aggregated status
+// @Tags Payment Requests
+// @Produce json
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} utils.Response
+// @Security BearerAuth
+// @Router /mahasiswa/requests [get]
+func (h *Handler) GetMyRequests(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	requests, total, err := h.service.GetMyRequests(userID, limit, offset)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Payment requests retrieved successfully", gin.H{
+		"requests": requests,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+	})
+}
+
+// GetIncoming handles GET /mahasiswa/requests/incoming
+// @Summary Get incoming payment requests
+// @Description Get shares the current user has been asked to pay
+// @Tags Payment Requests
+// @Produce json
+// @Param status query string false "Filter by share status" Enums(pending, paid, held, declined, cancelled)
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} utils.Response
+// @Security BearerAuth
+// @Router /mahasiswa/requests/incoming [get]
+func (h *Handler) GetIncoming(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	shares, total, err := h.service.GetIncomingShares(userID, c.Query("status"), limit, offset)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Incoming payment requests retrieved successfully", gin.H{
+		"shares": shares,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// GetByID handles GET /mahasiswa/requests/:id
+// @Summary Get payment request by ID
+// @Description Get a payment request visible to its requester or payers
+// @Tags Payment Requests
+// @Produce json
+// @Param id path int true "Payment request ID"
+// @Success 200 {object} utils.Response{data=PaymentRequest}
+// @Failure 404 {object} utils.Response
+// @Security BearerAuth
+// @Router /mahasiswa/requests/{id} [get]
+func (h *Handler) GetByID(c *gin.Context) {
+	requestID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid payment request ID", nil)
+		return
+	}
+
+	request, err := h.service.GetRequest(uint(requestID), c.GetUint("user_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Payment request retrieved successfully", request)
+}
+
+// Pay handles POST /mahasiswa/requests/:id/pay
+// @Summary Pay my share
+// @Description Pay the current user's share of a payment request via point transfer. A transfer held by fraud screening leaves the share held until an admin reviews it.
+// @Tags Payment Requests
+// @Produce json
+// @Param id path int true "Payment request ID"
+// @Success 200 {object} utils.Response{data=PaymentRequestShare}
+// @Failure 400 {object} utils.Response
+// @Security BearerAuth
+// @Router /mahasiswa/requests/{id}/pay [post]
+func (h *Handler) Pay(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	requestID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid payment request ID", nil)
+		return
+	}
+
+	share, err := h.service.PayShare(uint(requestID), userID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	message := "Share paid successfully"
+	if share.Status == "held" {
+		message = "Share payment is held for review"
+	}
+	utils.SuccessResponse(c, http.StatusOK, message, share)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "PAY_PAYMENT_REQUEST",
+		Entity:    "PAYMENT_REQUEST",
+		EntityID:  uint(requestID),
+		Details:   fmt.Sprintf("Paid %d points for payment request %d", share.Amount, requestID),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// Decline handles POST /mahasiswa/requests/:id/decline
+// @Summary Decline my share
+// @Description Decline the current user's share of a payment request
+// @Tags Payment Requests
+// @Produce json
+// @Param id path int true "Payment request ID"
+// @Success 200 {object} utils.Response{data=PaymentRequestShare}
+// @Failure 400 {object} utils.Response
+// @Security BearerAuth
+// @Router /mahasiswa/requests/{id}/decline [post]
+func (h *Handler) Decline(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	requestID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid payment request ID", nil)
+		return
+	}
+
+	share, err := h.service.DeclineShare(uint(requestID), userID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Share declined", share)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "DECLINE_PAYMENT_REQUEST",
+		Entity:    "PAYMENT_REQUEST",
+		EntityID:  uint(requestID),
+		Details:   fmt.Sprintf("Declined share of payment request %d", requestID),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// Cancel handles POST /mahasiswa/requests/:id/cancel
+// @Summary Cancel a payment request
+// @Description Requester withdraws an open payment request; pending shares are cancelled
+// @Tags Payment Requests
+// @Produce json
+// @Param id path int true "Payment request ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Security BearerAuth
+// @Router /mahasiswa/requests/{id}/cancel [post]
+func (h *Handler) Cancel(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	requestID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid payment request ID", nil)
+		return
+	}
+
+	if err := h.service.CancelRequest(uint(requestID), userID); err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "payment request not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Payment request cancelled", nil)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    userID,
+		Action:    "CANCEL_PAYMENT_REQUEST",
+		Entity:    "PAYMENT_REQUEST",
+		EntityID:  uint(requestID),
+		Details:   fmt.Sprintf("Cancelled payment request %d", requestID),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
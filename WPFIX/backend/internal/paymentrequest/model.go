@@ -0,0 +1,86 @@
+package paymentrequest
+
+import (
+	"time"
+)
+
+// PaymentRequest represents a student asking classmates to chip in points for a group purchase
+type PaymentRequest struct {
+	ID          uint                  `json:"id" gorm:"primaryKey"`
+	RequesterID uint                  `json:"requester_id" gorm:"not null;index"`
+	Title       string                `json:"title" gorm:"size:255;not null"`
+	Description string                `json:"description" gorm:"type:text"`
+	TotalAmount int                   `json:"total_amount" gorm:"not null"`
+	Status      string                `json:"status" gorm:"type:enum('open','completed','closed','cancelled');default:'open'"`
+	Shares      []PaymentRequestShare `json:"shares,omitempty" gorm:"foreignKey:PaymentRequestID;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+
+	// Virtual fields for response
+	RequesterName string          `json:"requester_name,omitempty" gorm:"-"`
+	Summary       *RequestSummary `json:"summary,omitempty" gorm:"-"`
+}
+
+// TableName specifies the table name for PaymentRequest model
+func (PaymentRequest) TableName() string {
+	return "payment_requests"
+}
+
+// PaymentRequestShare is the portion of a payment request owed by a single payer.
+// A share paid by a transfer that fraud screening held stays "held" until an
+// admin releases or reverses the transfer.
+type PaymentRequestShare struct {
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	PaymentRequestID uint       `json:"payment_request_id" gorm:"not null;index"`
+	PayerID          uint       `json:"payer_id" gorm:"not null;index"`
+	Amount           int        `json:"amount" gorm:"not null"`
+	Status           string     `json:"status" gorm:"type:enum('pending','paid','held','declined','cancelled');default:'pending'"`
+	TransferID       *uint      `json:"transfer_id"`
+	RespondedAt      *time.Time `json:"responded_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+
+	// Virtual fields for response
+	PayerName string `json:"payer_name,omitempty" gorm:"-"`
+	PayerNIM  string `json:"payer_nim,omitempty" gorm:"-"`
+}
+
+// TableName specifies the table name for PaymentRequestShare model
+func (PaymentRequestShare) TableName() string {
+	return "payment_request_shares"
+}
+
+// RequestSummary aggregates the state of every share in a payment request
+type RequestSummary struct {
+	TotalShares     int `json:"total_shares"`
+	PaidShares      int `json:"paid_shares"`
+	DeclinedShares  int `json:"declined_shares"`
+	PendingShares   int `json:"pending_shares"`
+	HeldShares      int `json:"held_shares"`
+	CollectedAmount int `json:"collected_amount"`
+	PendingAmount   int `json:"pending_amount"`
+	HeldAmount      int `json:"held_amount"`
+}
+
+// IncomingShare is a share owed by the current user, with the parent request details
+type IncomingShare struct {
+	PaymentRequestShare
+	Title         string `json:"title"`
+	Description   string `json:"description"`
+	RequesterID   uint   `json:"requester_id"`
+	RequesterName string `json:"requester_name"`
+	RequestStatus string `json:"request_status"`
+}
+
+// CreateRequest represents the request body for creating a payment request
+type CreateRequest struct {
+	Title       string         `json:"title" binding:"required,max=255"`
+	Description string         `json:"description"`
+	Shares      []ShareRequest `json:"shares" binding:"required,min=1,dive"`
+}
+
+// ShareRequest represents a single payer and the amount they are asked to pay
+type ShareRequest struct {
+	PayerID uint `json:"payer_id" binding:"required"`
+	Amount  int  `json:"amount" binding:"required,gt=0"`
+}
@@ -0,0 +1,137 @@
+package paymentrequest
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository handles database operations for payment requests
+type Repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new payment request repository
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// Create creates a payment request together with its shares
+func (r *Repository) Create(request *PaymentRequest) error {
+	return r.db.Create(request).Error
+}
+
+// FindByID retrieves a payment request with its shares
+func (r *Repository) FindByID(id uint) (*PaymentRequest, error) {
+	var request PaymentRequest
+	err := r.db.Preload("Shares").First(&request, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("payment request not found")
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// FindByRequester retrieves payment requests created by a user
+func (r *Repository) FindByRequester(requesterID uint, limit, offset int) ([]PaymentRequest, int64, error) {
+	var requests []PaymentRequest
+	var total int64
+
+	query := r.db.Model(&PaymentRequest{}).Where("requester_id = ?", requesterID)
+	query.Count(&total)
+
+	err := query.Preload("Shares").Limit(limit).Offset(offset).Order("created_at DESC").Find(&requests).Error
+	return requests, total, err
+}
+
+// FindSharesByPayer retrieves shares owed by a user, joined with the parent request
+func (r *Repository) FindSharesByPayer(payerID uint, status string, limit, offset int) ([]IncomingShare, int64, error) {
+	var shares []IncomingShare
+	var total int64
+
+	query := r.db.Table("payment_request_shares prs").
+		Select("prs.*, pr.title, pr.description, pr.requester_id, pr.status as request_status, u.full_name as requester_name").
+		Joins("JOIN payment_requests pr ON pr.id = prs.payment_request_id").
+		Joins("LEFT JOIN users u ON u.id = pr.requester_id").
+		Where("prs.payer_id = ?", payerID)
+
+	if status != "" {
+		query = query.Where("prs.status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Limit(limit).Offset(offset).Order("prs.created_at DESC").Scan(&shares).Error
+	return shares, total, err
+}
+
+// LockRequestWithTx loads a payment request and locks the row for update
+func (r *Repository) LockRequestWithTx(tx *gorm.DB, id uint) (*PaymentRequest, error) {
+	var request PaymentRequest
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("payment request not found")
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// LockShareWithTx loads a payer's share in a request and locks the row for update
+func (r *Repository) LockShareWithTx(tx *gorm.DB, requestID, payerID uint) (*PaymentRequestShare, error) {
+	var share PaymentRequestShare
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("payment_request_id = ? AND payer_id = ?", requestID, payerID).
+		First(&share).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("you are not a payer on this request")
+		}
+		return nil, err
+	}
+	return &share, nil
+}
+
+// FindShareByTransferWithTx retrieves the share paid by a transfer, or nil if
+// the transfer paid no share
+func (r *Repository) FindShareByTransferWithTx(tx *gorm.DB, transferID uint) (*PaymentRequestShare, error) {
+	var share PaymentRequestShare
+	err := tx.Where("transfer_id = ?", transferID).First(&share).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &share, nil
+}
+
+// UpdateShareWithTx updates a share within a transaction
+func (r *Repository) UpdateShareWithTx(tx *gorm.DB, id uint, updates map[string]interface{}) error {
+	return tx.Model(&PaymentRequestShare{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// CancelPendingSharesWithTx marks every pending share in a request as cancelled
+func (r *Repository) CancelPendingSharesWithTx(tx *gorm.DB, requestID uint) error {
+	return tx.Model(&PaymentRequestShare{}).
+		Where("payment_request_id = ? AND status = ?", requestID, "pending").
+		Update("status", "cancelled").Error
+}
+
+// FindSharesWithTx retrieves every share of a request within a transaction
+func (r *Repository) FindSharesWithTx(tx *gorm.DB, requestID uint) ([]PaymentRequestShare, error) {
+	var shares []PaymentRequestShare
+	err := tx.Where("payment_request_id = ?", requestID).Find(&shares).Error
+	return shares, err
+}
+
+// UpdateStatusWithTx sets the aggregated status of a request within a transaction
+func (r *Repository) UpdateStatusWithTx(tx *gorm.DB, requestID uint, status string) error {
+	return tx.Model(&PaymentRequest{}).Where("id = ?", requestID).Update("status", status).Error
+}
@@ -0,0 +1,369 @@
+package paymentrequest
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"wallet-point/internal/transfer"
+	"wallet-point/internal/wallet"
+
+	"gorm.io/gorm"
+)
+
+type Service struct {
+	repo            *Repository
+	walletService   *wallet.WalletService
+	transferService *transfer.Service
+	db              *gorm.DB
+}
+
+func NewService(repo *Repository, walletService *wallet.WalletService, transferService *transfer.Service, db *gorm.DB) *Service {
+	s := &Service{
+		repo:            repo,
+		walletService:   walletService,
+		transferService: transferService,
+		db:              db,
+	}
+	// Shares paid by a held transfer settle when an admin reviews it
+	transferService.OnHeldResolved(s.SyncHeldTransferWithTx)
+	return s
+}
+
+// CreateRequest opens a payment request with one pending share per payer
+func (s *Service) CreateRequest(requesterID uint, req *CreateRequest) (*PaymentRequest, error) {
+	if _, err := s.walletService.GetWalletByUserID(requesterID); err != nil {
+		return nil, errors.New("requester wallet not found")
+	}
+
+	request := &PaymentRequest{
+		RequesterID: requesterID,
+		Title:       req.Title,
+		Description: req.Description,
+		Status:      "open",
+	}
+
+	seen := make(map[uint]bool)
+	for _, sr := range req.Shares {
+		if sr.PayerID == requesterID {
+			return nil, errors.New("cannot request points from yourself")
+		}
+		if seen[sr.PayerID] {
+			return nil, fmt.Errorf("payer %d is listed more than once", sr.PayerID)
+		}
+		seen[sr.PayerID] = true
+
+		if _, err := s.walletService.GetWalletByUserID(sr.PayerID); err != nil {
+			return nil, fmt.Errorf("payer %d not found or has no wallet", sr.PayerID)
+		}
+
+		request.TotalAmount += sr.Amount
+		request.Shares = append(request.Shares, PaymentRequestShare{
+			PayerID: sr.PayerID,
+			Amount:  sr.Amount,
+			Status:  "pending",
+		})
+	}
+
+	if err := s.repo.Create(request); err != nil {
+		return nil, errors.New("failed to create payment request")
+	}
+
+	requests := []PaymentRequest{*request}
+	if err := s.populateDetails(requests); err != nil {
+		return nil, err
+	}
+
+	return &requests[0], nil
+}
+
+// GetRequest returns a payment request visible to its requester or one of its payers
+func (s *Service) GetRequest(requestID, userID uint) (*PaymentRequest, error) {
+	request, err := s.repo.FindByID(requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := request.RequesterID == userID
+	for _, share := range request.Shares {
+		if share.PayerID == userID {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, errors.New("payment request not found")
+	}
+
+	requests := []PaymentRequest{*request}
+	if err := s.populateDetails(requests); err != nil {
+		return nil, err
+	}
+
+	return &requests[0], nil
+}
+
+// GetMyRequests returns the payment requests created by a user with aggregated status
+func (s *Service) GetMyRequests(requesterID uint, limit, offset int) ([]PaymentRequest, int64, error) {
+	requests, total, err := s.repo.FindByRequester(requesterID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := s.populateDetails(requests); err != nil {
+		return nil, 0, err
+	}
+	return requests, total, nil
+}
+
+// GetIncomingShares returns the shares a user has been asked to pay
+func (s *Service) GetIncomingShares(payerID uint, status string, limit, offset int) ([]IncomingShare, int64, error) {
+	return s.repo.FindSharesByPayer(payerID, status, limit, offset)
+}
+
+// PayShare pays the current user's share through a regular point transfer
+func (s *Service) PayShare(requestID, payerID uint) (*PaymentRequestShare, error) {
+	var share *PaymentRequestShare
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		request, err := s.repo.LockRequestWithTx(tx, requestID)
+		if err != nil {
+			return err
+		}
+		if request.Status != "open" {
+			return errors.New("payment request is no longer open")
+		}
+
+		share, err = s.repo.LockShareWithTx(tx, requestID, payerID)
+		if err != nil {
+			return err
+		}
+		if share.Status != "pending" {
+			return fmt.Errorf("share has already been %s", share.Status)
+		}
+
+		description := fmt.Sprintf("Payment request #%d: %s", request.ID, request.Title)
+		t, err := s.transferService.CreateTransferWithTx(tx, payerID, request.RequesterID, share.Amount, description)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		share.Status = "paid"
+		if t.Status == "held" {
+			// The payer is debited but the requester is only credited once an
+			// admin releases the transfer; see SyncHeldTransferWithTx
+			share.Status = "held"
+		}
+		share.TransferID = &t.ID
+		share.RespondedAt = &now
+		if err := s.repo.UpdateShareWithTx(tx, share.ID, map[string]interface{}{
+			"status":       share.Status,
+			"transfer_id":  t.ID,
+			"responded_at": now,
+		}); err != nil {
+			return err
+		}
+
+		return s.refreshStatusWithTx(tx, requestID)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return share, nil
+}
+
+// DeclineShare marks the current user's share as declined
+func (s *Service) DeclineShare(requestID, payerID uint) (*PaymentRequestShare, error) {
+	var share *PaymentRequestShare
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		request, err := s.repo.LockRequestWithTx(tx, requestID)
+		if err != nil {
+			return err
+		}
+		if request.Status != "open" {
+			return errors.New("payment request is no longer open")
+		}
+
+		share, err = s.repo.LockShareWithTx(tx, requestID, payerID)
+		if err != nil {
+			return err
+		}
+		if share.Status != "pending" {
+			return fmt.Errorf("share has already been %s", share.Status)
+		}
+
+		now := time.Now()
+		share.Status = "declined"
+		share.RespondedAt = &now
+		if err := s.repo.UpdateShareWithTx(tx, share.ID, map[string]interface{}{
+			"status":       share.Status,
+			"responded_at": now,
+		}); err != nil {
+			return err
+		}
+
+		return s.refreshStatusWithTx(tx, requestID)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return share, nil
+}
+
+// CancelRequest lets the requester withdraw an open request; shares already paid are kept
+func (s *Service) CancelRequest(requestID, requesterID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		request, err := s.repo.LockRequestWithTx(tx, requestID)
+		if err != nil {
+			return err
+		}
+		if request.RequesterID != requesterID {
+			return errors.New("payment request not found")
+		}
+		if request.Status != "open" {
+			return errors.New("payment request is no longer open")
+		}
+
+		if err := s.repo.CancelPendingSharesWithTx(tx, requestID); err != nil {
+			return err
+		}
+		return s.repo.UpdateStatusWithTx(tx, requestID, "cancelled")
+	})
+}
+
+// SyncHeldTransferWithTx settles the share paid by a held transfer once an
+// admin releases or reverses it. A released transfer pays the share. A reversed
+// one has refunded the payer, so the share is pending again while the request
+// is open and cancelled otherwise.
+func (s *Service) SyncHeldTransferWithTx(tx *gorm.DB, t *transfer.Transfer) error {
+	found, err := s.repo.FindShareByTransferWithTx(tx, t.ID)
+	if err != nil || found == nil {
+		return err
+	}
+
+	request, err := s.repo.LockRequestWithTx(tx, found.PaymentRequestID)
+	if err != nil {
+		return err
+	}
+	share, err := s.repo.LockShareWithTx(tx, request.ID, found.PayerID)
+	if err != nil {
+		return err
+	}
+	if share.Status != "held" || share.TransferID == nil || *share.TransferID != t.ID {
+		return nil
+	}
+
+	updates := map[string]interface{}{"responded_at": time.Now()}
+	switch {
+	case t.Status == "success":
+		updates["status"] = "paid"
+	case t.Status == "reversed" && request.Status == "open":
+		updates["status"] = "pending"
+		updates["transfer_id"] = nil
+		updates["responded_at"] = nil
+	case t.Status == "reversed":
+		updates["status"] = "cancelled"
+	default:
+		return nil
+	}
+	if err := s.repo.UpdateShareWithTx(tx, share.ID, updates); err != nil {
+		return err
+	}
+
+	if request.Status != "open" {
+		return nil
+	}
+	return s.refreshStatusWithTx(tx, request.ID)
+}
+
+// refreshStatusWithTx recomputes the request status once a share has been
+// answered. Requests with shares pending or held stay open.
+func (s *Service) refreshStatusWithTx(tx *gorm.DB, requestID uint) error {
+	shares, err := s.repo.FindSharesWithTx(tx, requestID)
+	if err != nil {
+		return err
+	}
+
+	summary := summarize(shares)
+	if summary.PendingShares > 0 || summary.HeldShares > 0 {
+		return nil
+	}
+
+	status := "completed"
+	if summary.PaidShares < summary.TotalShares {
+		status = "closed"
+	}
+	return s.repo.UpdateStatusWithTx(tx, requestID, status)
+}
+
+// summarize aggregates share states into a request summary
+func summarize(shares []PaymentRequestShare) *RequestSummary {
+	summary := &RequestSummary{TotalShares: len(shares)}
+	for _, share := range shares {
+		switch share.Status {
+		case "paid":
+			summary.PaidShares++
+			summary.CollectedAmount += share.Amount
+		case "declined":
+			summary.DeclinedShares++
+		case "pending":
+			summary.PendingShares++
+			summary.PendingAmount += share.Amount
+		case "held":
+			summary.HeldShares++
+			summary.HeldAmount += share.Amount
+		}
+	}
+	return summary
+}
+
+// populateDetails fills in requester and payer names and the aggregated summary
+func (s *Service) populateDetails(requests []PaymentRequest) error {
+	if len(requests) == 0 {
+		return nil
+	}
+
+	userIDs := make(map[uint]bool)
+	for _, r := range requests {
+		userIDs[r.RequesterID] = true
+		for _, share := range r.Shares {
+			userIDs[share.PayerID] = true
+		}
+	}
+
+	ids := make([]uint, 0, len(userIDs))
+	for id := range userIDs {
+		ids = append(ids, id)
+	}
+
+	type UserInfo struct {
+		ID       uint
+		FullName string
+		NimNip   string
+	}
+
+	var users []UserInfo
+	if err := s.db.Table("users").Select("id, full_name, nim_nip").Where("id IN ?", ids).Scan(&users).Error; err != nil {
+		return err
+	}
+
+	infoMap := make(map[uint]UserInfo)
+	for _, u := range users {
+		infoMap[u.ID] = u
+	}
+
+	for i := range requests {
+		requests[i].RequesterName = infoMap[requests[i].RequesterID].FullName
+		for j := range requests[i].Shares {
+			if payer, ok := infoMap[requests[i].Shares[j].PayerID]; ok {
+				requests[i].Shares[j].PayerName = payer.FullName
+				requests[i].Shares[j].PayerNIM = payer.NimNip
+			}
+		}
+		requests[i].Summary = summarize(requests[i].Shares)
+	}
+
+	return nil
+}
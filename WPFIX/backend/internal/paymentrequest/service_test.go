@@ -0,0 +1,422 @@
+package paymentrequest_test
+
+import (
+	"testing"
+	"wallet-point/internal/database"
+	"wallet-point/internal/fraud"
+	"wallet-point/internal/paymentrequest"
+	"wallet-point/internal/transfer"
+	"wallet-point/internal/wallet"
+
+	"gorm.io/gorm"
+)
+
+func newService(db *gorm.DB, policy fraud.Policy) (*paymentrequest.Service, *transfer.Service) {
+	walletRepo := wallet.NewWalletRepository(db)
+	fraudService := fraud.NewService(fraud.NewRepository(db), fraud.NewDetector(db), policy)
+	walletService := wallet.NewWalletService(walletRepo, fraudService, db)
+	transferService := transfer.NewService(transfer.NewRepository(db), walletRepo, walletService, fraudService, db)
+	service := paymentrequest.NewService(paymentrequest.NewRepository(db), walletService, transferService, db)
+	return service, transferService
+}
+
+func balanceOf(t *testing.T, db *gorm.DB, walletID uint) int {
+	t.Helper()
+	var w wallet.Wallet
+	if err := db.First(&w, walletID).Error; err != nil {
+		t.Fatalf("failed to load wallet %d: %v", walletID, err)
+	}
+	return w.Balance
+}
+
+func shareOf(t *testing.T, request *paymentrequest.PaymentRequest, payerID uint) paymentrequest.PaymentRequestShare {
+	t.Helper()
+	for _, share := range request.Shares {
+		if share.PayerID == payerID {
+			return share
+		}
+	}
+	t.Fatalf("payer %d has no share in request %d", payerID, request.ID)
+	return paymentrequest.PaymentRequestShare{}
+}
+
+func expectError(t *testing.T, err error, want string) {
+	t.Helper()
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v, want %q", err, want)
+	}
+}
+
+func TestCreateRequest(t *testing.T) {
+	db := database.OpenTestDB(t)
+	service, _ := newService(db, fraud.Policy{})
+
+	requester, _ := database.CreateTestUser(t, db, "mahasiswa", 0)
+	alice, _ := database.CreateTestUser(t, db, "mahasiswa", 100)
+	bob, _ := database.CreateTestUser(t, db, "mahasiswa", 100)
+
+	request, err := service.CreateRequest(requester.ID, &paymentrequest.CreateRequest{
+		Title: "Group lunch",
+		Shares: []paymentrequest.ShareRequest{
+			{PayerID: alice.ID, Amount: 30},
+			{PayerID: bob.ID, Amount: 20},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateRequest: %v", err)
+	}
+
+	if request.Status != "open" || request.TotalAmount != 50 {
+		t.Errorf("got status %q total %d, want open 50", request.Status, request.TotalAmount)
+	}
+	if got := shareOf(t, request, alice.ID); got.Status != "pending" || got.Amount != 30 {
+		t.Errorf("alice's share is %s %d, want pending 30", got.Status, got.Amount)
+	}
+	want := paymentrequest.RequestSummary{TotalShares: 2, PendingShares: 2, PendingAmount: 50}
+	if *request.Summary != want {
+		t.Errorf("got summary %+v, want %+v", *request.Summary, want)
+	}
+
+	_, err = service.CreateRequest(requester.ID, &paymentrequest.CreateRequest{
+		Title:  "Self",
+		Shares: []paymentrequest.ShareRequest{{PayerID: requester.ID, Amount: 10}},
+	})
+	expectError(t, err, "cannot request points from yourself")
+}
+
+func TestPayShare(t *testing.T) {
+	db := database.OpenTestDB(t)
+	service, _ := newService(db, fraud.Policy{})
+
+	requester, requesterWallet := database.CreateTestUser(t, db, "mahasiswa", 0)
+	alice, aliceWallet := database.CreateTestUser(t, db, "mahasiswa", 100)
+	bob, _ := database.CreateTestUser(t, db, "mahasiswa", 100)
+	outsider, _ := database.CreateTestUser(t, db, "mahasiswa", 100)
+
+	request, err := service.CreateRequest(requester.ID, &paymentrequest.CreateRequest{
+		Title: "Printing",
+		Shares: []paymentrequest.ShareRequest{
+			{PayerID: alice.ID, Amount: 30},
+			{PayerID: bob.ID, Amount: 20},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateRequest: %v", err)
+	}
+
+	share, err := service.PayShare(request.ID, alice.ID)
+	if err != nil {
+		t.Fatalf("PayShare: %v", err)
+	}
+	if share.Status != "paid" || share.TransferID == nil || share.RespondedAt == nil {
+		t.Errorf("got share %+v, want paid with a transfer", share)
+	}
+	if got := balanceOf(t, db, aliceWallet.ID); got != 70 {
+		t.Errorf("alice's balance is %d, want 70", got)
+	}
+	if got := balanceOf(t, db, requesterWallet.ID); got != 30 {
+		t.Errorf("requester's balance is %d, want 30", got)
+	}
+
+	// Paying the same share again must not move points twice
+	_, err = service.PayShare(request.ID, alice.ID)
+	expectError(t, err, "share has already been paid")
+	if got := balanceOf(t, db, aliceWallet.ID); got != 70 {
+		t.Errorf("alice's balance is %d after paying twice, want 70", got)
+	}
+
+	_, err = service.PayShare(request.ID, outsider.ID)
+	expectError(t, err, "you are not a payer on this request")
+
+	request, err = service.GetRequest(request.ID, requester.ID)
+	if err != nil {
+		t.Fatalf("GetRequest: %v", err)
+	}
+	if request.Status != "open" {
+		t.Errorf("got status %q with a share pending, want open", request.Status)
+	}
+}
+
+func TestDeclineShare(t *testing.T) {
+	db := database.OpenTestDB(t)
+	service, _ := newService(db, fraud.Policy{})
+
+	requester, _ := database.CreateTestUser(t, db, "mahasiswa", 0)
+	alice, aliceWallet := database.CreateTestUser(t, db, "mahasiswa", 100)
+	bob, _ := database.CreateTestUser(t, db, "mahasiswa", 100)
+
+	request, err := service.CreateRequest(requester.ID, &paymentrequest.CreateRequest{
+		Title: "Tickets",
+		Shares: []paymentrequest.ShareRequest{
+			{PayerID: alice.ID, Amount: 30},
+			{PayerID: bob.ID, Amount: 20},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateRequest: %v", err)
+	}
+
+	share, err := service.DeclineShare(request.ID, alice.ID)
+	if err != nil {
+		t.Fatalf("DeclineShare: %v", err)
+	}
+	if share.Status != "declined" {
+		t.Errorf("got share status %q, want declined", share.Status)
+	}
+	if got := balanceOf(t, db, aliceWallet.ID); got != 100 {
+		t.Errorf("alice's balance is %d after declining, want 100", got)
+	}
+
+	_, err = service.PayShare(request.ID, alice.ID)
+	expectError(t, err, "share has already been declined")
+}
+
+func TestCancelRequest(t *testing.T) {
+	db := database.OpenTestDB(t)
+	service, _ := newService(db, fraud.Policy{})
+
+	requester, requesterWallet := database.CreateTestUser(t, db, "mahasiswa", 0)
+	alice, _ := database.CreateTestUser(t, db, "mahasiswa", 100)
+	bob, bobWallet := database.CreateTestUser(t, db, "mahasiswa", 100)
+
+	request, err := service.CreateRequest(requester.ID, &paymentrequest.CreateRequest{
+		Title: "Field trip",
+		Shares: []paymentrequest.ShareRequest{
+			{PayerID: alice.ID, Amount: 30},
+			{PayerID: bob.ID, Amount: 20},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateRequest: %v", err)
+	}
+	if _, err := service.PayShare(request.ID, alice.ID); err != nil {
+		t.Fatalf("PayShare: %v", err)
+	}
+
+	expectError(t, service.CancelRequest(request.ID, alice.ID), "payment request not found")
+	if err := service.CancelRequest(request.ID, requester.ID); err != nil {
+		t.Fatalf("CancelRequest: %v", err)
+	}
+
+	request, err = service.GetRequest(request.ID, requester.ID)
+	if err != nil {
+		t.Fatalf("GetRequest: %v", err)
+	}
+	if request.Status != "cancelled" {
+		t.Errorf("got status %q, want cancelled", request.Status)
+	}
+	if got := shareOf(t, request, alice.ID).Status; got != "paid" {
+		t.Errorf("alice's paid share is %q after cancelling, want paid", got)
+	}
+	if got := shareOf(t, request, bob.ID).Status; got != "cancelled" {
+		t.Errorf("bob's pending share is %q after cancelling, want cancelled", got)
+	}
+
+	// Nothing moves once the request is cancelled
+	_, err = service.PayShare(request.ID, bob.ID)
+	expectError(t, err, "payment request is no longer open")
+	_, err = service.DeclineShare(request.ID, bob.ID)
+	expectError(t, err, "payment request is no longer open")
+	expectError(t, service.CancelRequest(request.ID, requester.ID), "payment request is no longer open")
+	if got := balanceOf(t, db, bobWallet.ID); got != 100 {
+		t.Errorf("bob's balance is %d, want 100", got)
+	}
+	if got := balanceOf(t, db, requesterWallet.ID); got != 30 {
+		t.Errorf("requester's balance is %d, want 30", got)
+	}
+}
+
+func TestAggregatedStatus(t *testing.T) {
+	db := database.OpenTestDB(t)
+	service, _ := newService(db, fraud.Policy{})
+
+	requester, _ := database.CreateTestUser(t, db, "mahasiswa", 0)
+	alice, _ := database.CreateTestUser(t, db, "mahasiswa", 100)
+	bob, _ := database.CreateTestUser(t, db, "mahasiswa", 100)
+
+	create := func() *paymentrequest.PaymentRequest {
+		request, err := service.CreateRequest(requester.ID, &paymentrequest.CreateRequest{
+			Title: "Books",
+			Shares: []paymentrequest.ShareRequest{
+				{PayerID: alice.ID, Amount: 30},
+				{PayerID: bob.ID, Amount: 20},
+			},
+		})
+		if err != nil {
+			t.Fatalf("CreateRequest: %v", err)
+		}
+		return request
+	}
+
+	// Every share paid completes the request
+	completed := create()
+	if _, err := service.PayShare(completed.ID, alice.ID); err != nil {
+		t.Fatalf("PayShare: %v", err)
+	}
+	if _, err := service.PayShare(completed.ID, bob.ID); err != nil {
+		t.Fatalf("PayShare: %v", err)
+	}
+	request, err := service.GetRequest(completed.ID, requester.ID)
+	if err != nil {
+		t.Fatalf("GetRequest: %v", err)
+	}
+	if request.Status != "completed" {
+		t.Errorf("got status %q with every share paid, want completed", request.Status)
+	}
+	want := paymentrequest.RequestSummary{TotalShares: 2, PaidShares: 2, CollectedAmount: 50}
+	if *request.Summary != want {
+		t.Errorf("got summary %+v, want %+v", *request.Summary, want)
+	}
+
+	// A declined share closes the request once nothing is pending
+	closed := create()
+	if _, err := service.PayShare(closed.ID, alice.ID); err != nil {
+		t.Fatalf("PayShare: %v", err)
+	}
+	if _, err := service.DeclineShare(closed.ID, bob.ID); err != nil {
+		t.Fatalf("DeclineShare: %v", err)
+	}
+	request, err = service.GetRequest(closed.ID, bob.ID)
+	if err != nil {
+		t.Fatalf("GetRequest: %v", err)
+	}
+	if request.Status != "closed" {
+		t.Errorf("got status %q with a share declined, want closed", request.Status)
+	}
+	want = paymentrequest.RequestSummary{TotalShares: 2, PaidShares: 1, DeclinedShares: 1, CollectedAmount: 30}
+	if *request.Summary != want {
+		t.Errorf("got summary %+v, want %+v", *request.Summary, want)
+	}
+}
+
+func TestHeldSharePayment(t *testing.T) {
+	db := database.OpenTestDB(t)
+	// A new account moving out its whole balance scores 40 and is held
+	service, transferService := newService(db, fraud.Policy{HoldScore: 40})
+
+	admin, _ := database.CreateTestUser(t, db, "admin", 0)
+
+	// setup opens a request whose first payer's transfer is held and whose
+	// second payer has paid
+	setup := func() (request *paymentrequest.PaymentRequest, requesterWalletID, payerID, payerWalletID uint, transferID uint) {
+		requester, requesterWallet := database.CreateTestUser(t, db, "mahasiswa", 0)
+		payer, payerWallet := database.CreateTestUser(t, db, "mahasiswa", 30)
+		other, _ := database.CreateTestUser(t, db, "mahasiswa", 100)
+
+		request, err := service.CreateRequest(requester.ID, &paymentrequest.CreateRequest{
+			Title: "Lab kit",
+			Shares: []paymentrequest.ShareRequest{
+				{PayerID: payer.ID, Amount: 30},
+				{PayerID: other.ID, Amount: 20},
+			},
+		})
+		if err != nil {
+			t.Fatalf("CreateRequest: %v", err)
+		}
+		if _, err := service.PayShare(request.ID, other.ID); err != nil {
+			t.Fatalf("PayShare: %v", err)
+		}
+
+		share, err := service.PayShare(request.ID, payer.ID)
+		if err != nil {
+			t.Fatalf("PayShare: %v", err)
+		}
+		if share.Status != "held" || share.TransferID == nil {
+			t.Fatalf("got share %+v, want held with a transfer", share)
+		}
+		return request, requesterWallet.ID, payer.ID, payerWallet.ID, *share.TransferID
+	}
+
+	t.Run("held", func(t *testing.T) {
+		request, requesterWalletID, payerID, payerWalletID, _ := setup()
+
+		if got := balanceOf(t, db, payerWalletID); got != 0 {
+			t.Errorf("payer's balance is %d, want 0", got)
+		}
+		if got := balanceOf(t, db, requesterWalletID); got != 20 {
+			t.Errorf("requester's balance is %d, want 20 until the transfer is released", got)
+		}
+
+		request, err := service.GetRequest(request.ID, payerID)
+		if err != nil {
+			t.Fatalf("GetRequest: %v", err)
+		}
+		if request.Status != "open" {
+			t.Errorf("got status %q with a share held, want open", request.Status)
+		}
+		want := paymentrequest.RequestSummary{TotalShares: 2, PaidShares: 1, HeldShares: 1, CollectedAmount: 20, HeldAmount: 30}
+		if *request.Summary != want {
+			t.Errorf("got summary %+v, want %+v", *request.Summary, want)
+		}
+
+		_, err = service.PayShare(request.ID, payerID)
+		expectError(t, err, "share has already been held")
+	})
+
+	t.Run("released", func(t *testing.T) {
+		request, requesterWalletID, payerID, _, transferID := setup()
+
+		if _, err := transferService.ReleaseHeldTransfer(transferID, admin.ID, "looks fine"); err != nil {
+			t.Fatalf("ReleaseHeldTransfer: %v", err)
+		}
+
+		request, err := service.GetRequest(request.ID, payerID)
+		if err != nil {
+			t.Fatalf("GetRequest: %v", err)
+		}
+		if got := shareOf(t, request, payerID).Status; got != "paid" {
+			t.Errorf("got share status %q after release, want paid", got)
+		}
+		if request.Status != "completed" {
+			t.Errorf("got status %q after release, want completed", request.Status)
+		}
+		if got := balanceOf(t, db, requesterWalletID); got != 50 {
+			t.Errorf("requester's balance is %d after release, want 50", got)
+		}
+	})
+
+	t.Run("reversed", func(t *testing.T) {
+		request, _, payerID, payerWalletID, transferID := setup()
+
+		if _, err := transferService.ReverseHeldTransfer(transferID, admin.ID, "suspicious"); err != nil {
+			t.Fatalf("ReverseHeldTransfer: %v", err)
+		}
+
+		request, err := service.GetRequest(request.ID, payerID)
+		if err != nil {
+			t.Fatalf("GetRequest: %v", err)
+		}
+		share := shareOf(t, request, payerID)
+		if share.Status != "pending" || share.TransferID != nil {
+			t.Errorf("got share %+v after reversal, want pending with no transfer", share)
+		}
+		if request.Status != "open" {
+			t.Errorf("got status %q after reversal, want open", request.Status)
+		}
+		if got := balanceOf(t, db, payerWalletID); got != 30 {
+			t.Errorf("payer's balance is %d after reversal, want 30", got)
+		}
+	})
+
+	t.Run("reversed after cancel", func(t *testing.T) {
+		request, _, payerID, _, transferID := setup()
+
+		if err := service.CancelRequest(request.ID, request.RequesterID); err != nil {
+			t.Fatalf("CancelRequest: %v", err)
+		}
+		if _, err := transferService.ReverseHeldTransfer(transferID, admin.ID, "suspicious"); err != nil {
+			t.Fatalf("ReverseHeldTransfer: %v", err)
+		}
+
+		request, err := service.GetRequest(request.ID, payerID)
+		if err != nil {
+			t.Fatalf("GetRequest: %v", err)
+		}
+		if got := shareOf(t, request, payerID).Status; got != "cancelled" {
+			t.Errorf("got share status %q after reversal, want cancelled", got)
+		}
+		if request.Status != "cancelled" {
+			t.Errorf("got status %q after reversal, want cancelled", request.Status)
+		}
+	})
+}
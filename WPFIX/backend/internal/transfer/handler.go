@@ -54,7 +54,12 @@ func (h *Handler) CreateTransfer(c *gin.Context) {
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Transfer completed successfully", gin.H{
+	message := "Transfer completed successfully"
+	if transfer.Status == "held" {
+		message = "Transfer is on hold pending review"
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, message, gin.H{
 		"transfer": transfer,
 	})
 
@@ -217,3 +222,84 @@ func (h *Handler) GetRecipientInfo(c *gin.Context) {
 
 	utils.SuccessResponse(c, http.StatusOK, "Recipient found", recipient)
 }
+
+// ReleaseHeldTransfer handles POST /admin/transfers/:id/release
+// @Summary Release a held transfer
+// @Description Credit the receiver of a transfer held by fraud screening (Admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Transfer ID"
+// @Param request body HeldTransferReviewRequest false "Review note"
+// @Success 200 {object} utils.Response{data=Transfer}
+// @Failure 400 {object} utils.Response
+// @Security BearerAuth
+// @Router /admin/transfers/{id}/release [post]
+func (h *Handler) ReleaseHeldTransfer(c *gin.Context) {
+	h.reviewHeldTransfer(c, true)
+}
+
+// ReverseHeldTransfer handles POST /admin/transfers/:id/reverse
+// @Summary Reverse a held transfer
+// @Description Refund the sender of a transfer held by fraud screening (Admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Transfer ID"
+// @Param request body HeldTransferReviewRequest false "Review note"
+// @Success 200 {object} utils.Response{data=Transfer}
+// @Failure 400 {object} utils.Response
+// @Security BearerAuth
+// @Router /admin/transfers/{id}/reverse [post]
+func (h *Handler) ReverseHeldTransfer(c *gin.Context) {
+	h.reviewHeldTransfer(c, false)
+}
+
+func (h *Handler) reviewHeldTransfer(c *gin.Context, release bool) {
+	adminID := c.GetUint("user_id")
+	transferID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return
+	}
+
+	var req HeldTransferReviewRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+	}
+
+	var transfer *Transfer
+	action := "RELEASE_HELD_TRANSFER"
+	message := "Transfer released successfully"
+	if release {
+		transfer, err = h.service.ReleaseHeldTransfer(uint(transferID), adminID, req.Note)
+	} else {
+		transfer, err = h.service.ReverseHeldTransfer(uint(transferID), adminID, req.Note)
+		action = "REVERSE_HELD_TRANSFER"
+		message = "Transfer reversed successfully"
+	}
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "transfer not found" {
+			statusCode = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, statusCode, err.Error(), nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, message, transfer)
+
+	// Log activity
+	h.auditService.LogActivity(audit.CreateAuditParams{
+		UserID:    adminID,
+		Action:    action,
+		Entity:    "TRANSFER",
+		EntityID:  transfer.ID,
+		Details:   fmt.Sprintf("Admin resolved held transfer of %d points | Note: %s", transfer.Amount, req.Note),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
@@ -11,7 +11,7 @@ type Transfer struct {
 	ReceiverWalletID uint      `json:"receiver_wallet_id" gorm:"not null;index"`
 	Amount           int       `json:"amount" gorm:"not null"`
 	Description      string    `json:"description" gorm:"type:varchar(255)"`
-	Status           string    `json:"status" gorm:"type:enum('success','failed');default:'success'"`
+	Status           string    `json:"status" gorm:"type:enum('success','failed','held','reversed');default:'success'"`
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
 
@@ -34,6 +34,11 @@ type TransferRequest struct {
 	Description    string `json:"description" binding:"max=255"`
 }
 
+// HeldTransferReviewRequest represents the admin decision on a transfer held by fraud screening
+type HeldTransferReviewRequest struct {
+	Note string `json:"note" binding:"max=500"`
+}
+
 // TransferResponse represents the response for transfer operations
 type TransferResponse struct {
 	Transfer *Transfer `json:"transfer"`
@@ -1,7 +1,10 @@
 package transfer
 
 import (
+	"errors"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Repository handles database operations for transfers
@@ -77,3 +80,21 @@ func (r *Repository) FindAll(limit, offset int) ([]Transfer, int64, error) {
 func (r *Repository) CreateWithTransaction(tx *gorm.DB, transfer *Transfer) error {
 	return tx.Create(transfer).Error
 }
+
+// LockByIDWithTx loads a transfer and locks the row for update
+func (r *Repository) LockByIDWithTx(tx *gorm.DB, id uint) (*Transfer, error) {
+	var transfer Transfer
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transfer, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("transfer not found")
+		}
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// UpdateStatusWithTx sets the status of a transfer within a transaction
+func (r *Repository) UpdateStatusWithTx(tx *gorm.DB, id uint, status string) error {
+	return tx.Model(&Transfer{}).Where("id = ?", id).Update("status", status).Error
+}
@@ -3,33 +3,74 @@ package transfer
 import (
 	"errors"
 	"fmt"
+	"wallet-point/internal/fraud"
 	"wallet-point/internal/wallet"
 
 	"gorm.io/gorm"
 )
 
+// HeldTransferHook is run inside the transaction that releases or reverses a
+// held transfer, after its status has been updated
+type HeldTransferHook func(tx *gorm.DB, transfer *Transfer) error
+
 type Service struct {
 	repo          *Repository
 	walletRepo    *wallet.WalletRepository
 	walletService *wallet.WalletService
+	fraudService  *fraud.Service
+	heldHooks     []HeldTransferHook
 	db            *gorm.DB
 }
 
-func NewService(repo *Repository, walletRepo *wallet.WalletRepository, walletService *wallet.WalletService, db *gorm.DB) *Service {
+func NewService(repo *Repository, walletRepo *wallet.WalletRepository, walletService *wallet.WalletService, fraudService *fraud.Service, db *gorm.DB) *Service {
 	return &Service{
 		repo:          repo,
 		walletRepo:    walletRepo,
 		walletService: walletService,
+		fraudService:  fraudService,
 		db:            db,
 	}
 }
 
+// OnHeldResolved registers a hook for features that paid something with a
+// transfer that may be held, such as payment request shares
+func (s *Service) OnHeldResolved(hook HeldTransferHook) {
+	s.heldHooks = append(s.heldHooks, hook)
+}
+
+// runHeldHooksWithTx runs the registered hooks for a released or reversed transfer
+func (s *Service) runHeldHooksWithTx(tx *gorm.DB, transfer *Transfer) error {
+	for _, hook := range s.heldHooks {
+		if err := hook(tx, transfer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *Service) CreateTransfer(senderUserID, receiverUserID uint, amount int, description string) (*Transfer, error) {
+	var transfer *Transfer
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		transfer, err = s.CreateTransferWithTx(tx, senderUserID, receiverUserID, amount, description)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// CreateTransferWithTx moves points between two users inside an existing transaction
+func (s *Service) CreateTransferWithTx(tx *gorm.DB, senderUserID, receiverUserID uint, amount int, description string) (*Transfer, error) {
 	if senderUserID == receiverUserID {
 		return nil, errors.New("cannot transfer points to yourself")
 	}
 
-	senderWallet, err := s.walletService.GetWalletByUserID(senderUserID)
+	// Lock the sender's wallet so concurrent transfers see each other's debits
+	senderWallet, err := s.walletService.LockWalletByUserIDWithTx(tx, senderUserID)
 	if err != nil {
 		return nil, errors.New("sender wallet not found")
 	}
@@ -43,6 +84,20 @@ func (s *Service) CreateTransfer(senderUserID, receiverUserID uint, amount int,
 		return nil, errors.New("insufficient balance")
 	}
 
+	// Screen for suspicious patterns before any money moves
+	event := fraud.Event{
+		Type:             fraud.EventTransfer,
+		Channel:          "transfer",
+		SenderWalletID:   senderWallet.ID,
+		ReceiverWalletID: receiverWallet.ID,
+		Amount:           amount,
+	}
+	assessment, err := s.fraudService.Screen(event)
+	if err != nil {
+		return nil, err
+	}
+	held := assessment.Action == fraud.ActionHold
+
 	transfer := &Transfer{
 		SenderWalletID:   senderWallet.ID,
 		ReceiverWalletID: receiverWallet.ID,
@@ -50,20 +105,103 @@ func (s *Service) CreateTransfer(senderUserID, receiverUserID uint, amount int,
 		Description:      description,
 		Status:           "success",
 	}
+	if held {
+		transfer.Status = "held"
+	}
 
-	err = s.db.Transaction(func(tx *gorm.DB) error {
-		// 1. Deduct from sender
-		if err := s.walletService.DebitWithTransaction(tx, senderWallet.ID, amount, "transfer_out", fmt.Sprintf("Transfer to user %d", receiverUserID)); err != nil {
+	// 1. Deduct from sender
+	if err := s.walletService.DebitWithTransaction(tx, senderWallet.ID, amount, "transfer_out", fmt.Sprintf("Transfer to user %d", receiverUserID)); err != nil {
+		return nil, err
+	}
+
+	// 2. Credit to receiver (held transfers are credited once an admin releases them)
+	if !held {
+		if err := s.walletService.CreditWithTransaction(tx, receiverWallet.ID, amount, "transfer_in", fmt.Sprintf("Transfer from user %d", senderUserID)); err != nil {
+			return nil, err
+		}
+	}
+
+	// 3. Create transfer record
+	if err := s.repo.CreateWithTransaction(tx, transfer); err != nil {
+		return nil, err
+	}
+
+	// 4. Queue flagged or held transfers for review
+	if err := s.fraudService.RecordWithTx(tx, event, assessment, &transfer.ID); err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// ReleaseHeldTransfer credits the receiver of a transfer held by fraud screening
+func (s *Service) ReleaseHeldTransfer(transferID, adminID uint, note string) (*Transfer, error) {
+	var transfer *Transfer
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		transfer, err = s.repo.LockByIDWithTx(tx, transferID)
+		if err != nil {
 			return err
 		}
+		if transfer.Status != "held" {
+			return errors.New("transfer is not held")
+		}
 
-		// 2. Credit to receiver
-		if err := s.walletService.CreditWithTransaction(tx, receiverWallet.ID, amount, "transfer_in", fmt.Sprintf("Transfer from user %d", senderUserID)); err != nil {
+		sender, err := s.walletService.GetWalletByID(transfer.SenderWalletID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.walletService.CreditWithTransaction(tx, transfer.ReceiverWalletID, transfer.Amount, "transfer_in", fmt.Sprintf("Transfer from user %d", sender.UserID)); err != nil {
+			return err
+		}
+
+		transfer.Status = "success"
+		if err := s.repo.UpdateStatusWithTx(tx, transfer.ID, transfer.Status); err != nil {
+			return err
+		}
+
+		if err := s.runHeldHooksWithTx(tx, transfer); err != nil {
+			return err
+		}
+
+		return s.fraudService.ResolveByReferenceWithTx(tx, "transfer", transfer.ID, "approved", adminID, note)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// ReverseHeldTransfer refunds the sender of a transfer held by fraud screening
+func (s *Service) ReverseHeldTransfer(transferID, adminID uint, note string) (*Transfer, error) {
+	var transfer *Transfer
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		transfer, err = s.repo.LockByIDWithTx(tx, transferID)
+		if err != nil {
+			return err
+		}
+		if transfer.Status != "held" {
+			return errors.New("transfer is not held")
+		}
+
+		if err := s.walletService.CreditWithTransaction(tx, transfer.SenderWalletID, transfer.Amount, "transfer_in", fmt.Sprintf("Reversal of held transfer #%d", transfer.ID)); err != nil {
+			return err
+		}
+
+		transfer.Status = "reversed"
+		if err := s.repo.UpdateStatusWithTx(tx, transfer.ID, transfer.Status); err != nil {
+			return err
+		}
+
+		if err := s.runHeldHooksWithTx(tx, transfer); err != nil {
 			return err
 		}
 
-		// 3. Create transfer record
-		return s.repo.CreateWithTransaction(tx, transfer)
+		return s.fraudService.ResolveByReferenceWithTx(tx, "transfer", transfer.ID, "rejected", adminID, note)
 	})
 
 	if err != nil {
@@ -11,6 +11,8 @@ type User struct {
 	FullName     string    `json:"full_name" gorm:"not null"`
 	NimNip       string    `json:"nim_nip" gorm:"uniqueIndex;not null"`
 	Role         string    `json:"role" gorm:"type:enum('admin','dosen','mahasiswa');not null"`
+	Major        string    `json:"major" gorm:"size:255"`
+	Batch        string    `json:"batch" gorm:"size:50"`
 	Status       string    `json:"status" gorm:"type:enum('active','inactive','suspended');default:'active'"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
@@ -39,6 +41,8 @@ type UserWithWallet struct {
 	FullName   string     `json:"full_name"`
 	NimNip     string     `json:"nim_nip"`
 	Role       string     `json:"role"`
+	Major      string     `json:"major"`
+	Batch      string     `json:"batch"`
 	Status     string     `json:"status"`
 	Balance    int        `json:"balance"`
 	LastSyncAt *time.Time `json:"last_sync_at,omitempty"`
@@ -51,6 +55,8 @@ type UpdateUserRequest struct {
 	Email    string `json:"email,omitempty" binding:"omitempty,email"`
 	Status   string `json:"status,omitempty" binding:"omitempty,oneof=active inactive suspended"`
 	Role     string `json:"role,omitempty" binding:"omitempty,oneof=admin dosen mahasiswa"`
+	Major    string `json:"major,omitempty" binding:"omitempty,max=255"`
+	Batch    string `json:"batch,omitempty" binding:"omitempty,max=50"`
 }
 
 type ChangePasswordRequest struct {
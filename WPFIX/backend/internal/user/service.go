@@ -76,6 +76,12 @@ func (s *UserService) UpdateUser(userID uint, req *UpdateUserRequest) (*User, er
 	if req.Role != "" {
 		updates["role"] = req.Role
 	}
+	if req.Major != "" {
+		updates["major"] = req.Major
+	}
+	if req.Batch != "" {
+		updates["batch"] = req.Batch
+	}
 
 	// Update user
 	if len(updates) > 0 {
@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type WalletRepository struct {
@@ -40,6 +41,19 @@ func (r *WalletRepository) FindByUserID(userID uint) (*Wallet, error) {
 	return &wallet, nil
 }
 
+// LockByUserIDWithTx finds a user's wallet and locks the row for update
+func (r *WalletRepository) LockByUserIDWithTx(tx *gorm.DB, userID uint) (*Wallet, error) {
+	var wallet Wallet
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).First(&wallet).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("wallet not found")
+		}
+		return nil, err
+	}
+	return &wallet, nil
+}
+
 // GetAllWithUsers gets all wallets with user information
 func (r *WalletRepository) GetAllWithUsers() ([]WalletWithUser, error) {
 	var wallets []WalletWithUser
@@ -9,20 +9,23 @@ import (
 	"log"
 	"math"
 	"time"
+	"wallet-point/internal/fraud"
 
 	"github.com/skip2/go-qrcode"
 	"gorm.io/gorm"
 )
 
 type WalletService struct {
-	repo *WalletRepository
-	db   *gorm.DB
+	repo         *WalletRepository
+	fraudService *fraud.Service
+	db           *gorm.DB
 }
 
-func NewWalletService(repo *WalletRepository, db *gorm.DB) *WalletService {
+func NewWalletService(repo *WalletRepository, fraudService *fraud.Service, db *gorm.DB) *WalletService {
 	return &WalletService{
-		repo: repo,
-		db:   db,
+		repo:         repo,
+		fraudService: fraudService,
+		db:           db,
 	}
 }
 
@@ -31,6 +34,12 @@ func (s *WalletService) GetWalletByUserID(userID uint) (*Wallet, error) {
 	return s.repo.FindByUserID(userID)
 }
 
+// LockWalletByUserIDWithTx retrieves a user's wallet inside a transaction and
+// locks it, so its balance cannot change until the transaction ends
+func (s *WalletService) LockWalletByUserIDWithTx(tx *gorm.DB, userID uint) (*Wallet, error) {
+	return s.repo.LockByUserIDWithTx(tx, userID)
+}
+
 // GetWalletByID finds wallet by ID
 func (s *WalletService) GetWalletByID(walletID uint) (*Wallet, error) {
 	return s.repo.FindByID(walletID)
@@ -216,6 +225,18 @@ func (s *WalletService) MerchantConsumeToken(tokenCode string, merchantID uint)
 		return nil, errors.New("merchant wallet not found")
 	}
 
+	event := fraud.Event{
+		Type:             fraud.EventPayment,
+		Channel:          "qr_merchant",
+		SenderWalletID:   token.WalletID,
+		ReceiverWalletID: merchantWallet.ID,
+		Amount:           token.Amount,
+	}
+	assessment, err := s.fraudService.Screen(event)
+	if err != nil {
+		return nil, err
+	}
+
 	err = s.db.Transaction(func(tx *gorm.DB) error {
 		// 1. Deduct from student
 		err := s.repo.UpdateBalance(tx, token.WalletID, -token.Amount)
@@ -262,7 +283,8 @@ func (s *WalletService) MerchantConsumeToken(tokenCode string, merchantID uint)
 			return err
 		}
 
-		return nil
+		// 6. Queue flagged payments for review
+		return s.fraudService.RecordWithTx(tx, event, assessment, &token.ID)
 	})
 
 	return nil, err
@@ -334,6 +356,18 @@ func (s *WalletService) StudentPayToken(tokenCode string, scannerUserID uint) er
 		recipientWallet = newWallet
 	}
 
+	event := fraud.Event{
+		Type:             fraud.EventPayment,
+		Channel:          "qr_bill",
+		SenderWalletID:   scannerWallet.ID,
+		ReceiverWalletID: recipientWallet.ID,
+		Amount:           token.Amount,
+	}
+	assessment, err := s.fraudService.Screen(event)
+	if err != nil {
+		return err
+	}
+
 	return s.db.Transaction(func(tx *gorm.DB) error {
 		// 1. Deduct from scanner
 		if err := s.repo.UpdateBalance(tx, scannerWallet.ID, -token.Amount); err != nil {
@@ -370,7 +404,8 @@ func (s *WalletService) StudentPayToken(tokenCode string, scannerUserID uint) er
 			Description: fmt.Sprintf("Terima Bayar Mandiri dari User ID %d: %s", scannerUserID, token.Merchant),
 		})
 
-		return nil
+		// 5. Queue flagged payments for review
+		return s.fraudService.RecordWithTx(tx, event, assessment, &token.ID)
 	})
 }
 
@@ -423,13 +458,19 @@ func (s *WalletService) CreditWithTransaction(tx *gorm.DB, walletID uint, amount
 	return s.repo.CreateTransaction(tx, txn)
 }
 
-// ProcessMissionRewardWithTx handles mission rewards within a transaction
-func (s *WalletService) ProcessMissionRewardWithTx(tx *gorm.DB, userID uint, amount int, missionTitle string, missionID uint, reviewerID uint) error {
+// ProcessMissionRewardWithTx handles mission rewards within a transaction;
+// detail explains how the amount was worked out
+func (s *WalletService) ProcessMissionRewardWithTx(tx *gorm.DB, userID uint, amount int, missionTitle string, missionID uint, reviewerID uint, detail string) error {
 	wallet, err := s.repo.FindByUserID(userID)
 	if err != nil {
 		return err
 	}
 
+	description := "Reward for mission: " + missionTitle
+	if detail != "" {
+		description += " (" + detail + ")"
+	}
+
 	// Create transaction record
 	txn := &WalletTransaction{
 		WalletID:    wallet.ID,
@@ -437,7 +478,7 @@ func (s *WalletService) ProcessMissionRewardWithTx(tx *gorm.DB, userID uint, amo
 		Amount:      amount,
 		Direction:   "credit",
 		Status:      "success",
-		Description: "Reward for mission: " + missionTitle,
+		Description: description,
 		ReferenceID: &missionID,
 		CreatedBy:   "dosen",
 	}
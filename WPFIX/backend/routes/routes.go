@@ -1,11 +1,16 @@
 package routes
 
 import (
+	"context"
 	"wallet-point/internal/audit"
 	"wallet-point/internal/auth"
 	"wallet-point/internal/external" // Add this
+	"wallet-point/internal/fraud"
 	"wallet-point/internal/marketplace"
+	"wallet-point/internal/media"
 	"wallet-point/internal/mission"
+	"wallet-point/internal/notification"
+	"wallet-point/internal/paymentrequest"
 	"wallet-point/internal/transfer"
 	"wallet-point/internal/user"
 	"wallet-point/internal/wallet"
@@ -16,7 +21,13 @@ import (
 	"gorm.io/gorm"
 )
 
-func SetupRoutes(r *gin.Engine, db *gorm.DB, allowedOrigins string, jwtExpiry int) {
+// Job is a background job of the services SetupRoutes builds. It runs until
+// ctx is cancelled.
+type Job func(ctx context.Context)
+
+// SetupRoutes registers every route and returns the background jobs for the
+// caller to run
+func SetupRoutes(r *gin.Engine, db *gorm.DB, allowedOrigins string, jwtExpiry int, fraudPolicy fraud.Policy) []Job {
 	// Apply global middleware
 	r.Use(middleware.CORS(allowedOrigins))
 	r.Use(middleware.Logger())
@@ -42,16 +53,32 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, allowedOrigins string, jwtExpiry in
 	missionRepo := mission.NewMissionRepository(db)
 	transferRepo := transfer.NewRepository(db)
 	externalRepo := external.NewRepository(db) // Add this
+	paymentRequestRepo := paymentrequest.NewRepository(db)
+	fraudRepo := fraud.NewRepository(db)
+	notificationRepo := notification.NewRepository(db)
 
 	// Initialize services
+	fraudService := fraud.NewService(fraudRepo, fraud.NewDetector(db), fraudPolicy)
+	notificationService := notification.NewService(notificationRepo)
+	imageService := media.NewImageService(media.NewLocalStorage(utils.UploadDir, "/uploads"))
 	authService := auth.NewAuthService(authRepo, jwtExpiry)
 	userService := user.NewUserService(userRepo)
-	walletService := wallet.NewWalletService(walletRepo, db)
-	marketplaceService := marketplace.NewMarketplaceService(marketplaceRepo, walletService, db)
+	walletService := wallet.NewWalletService(walletRepo, fraudService, db)
 	auditService := audit.NewAuditService(auditRepo)
+	marketplaceService := marketplace.NewMarketplaceService(marketplaceRepo, walletService, fraudService, notificationService, imageService, auditService, db)
 	missionService := mission.NewMissionService(missionRepo, walletService, db)
-	transferService := transfer.NewService(transferRepo, walletRepo, walletService, db)
+	transferService := transfer.NewService(transferRepo, walletRepo, walletService, fraudService, db)
 	externalService := external.NewService(externalRepo, walletRepo, walletService, marketplaceService, missionService, auditService, db) // Add this
+	paymentRequestService := paymentrequest.NewService(paymentRequestRepo, walletService, transferService, db)
+
+	jobs := []Job{
+		// Close auctions once their end time has passed
+		func(ctx context.Context) { marketplaceService.RunAuctionCloser(ctx, marketplace.AuctionCloseInterval) },
+		// Submit the saved answers of quiz attempts whose time has run out
+		func(ctx context.Context) { missionService.RunSessionCloser(ctx, mission.SessionCloseInterval) },
+		// Publish, expire and spawn scheduled missions
+		func(ctx context.Context) { missionService.RunScheduler(ctx, mission.ScheduleInterval) },
+	}
 
 	// Initialize handlers
 	authHandler := auth.NewAuthHandler(authService, auditService)
@@ -62,6 +89,9 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, allowedOrigins string, jwtExpiry in
 	missionHandler := mission.NewMissionHandler(missionService, auditService)
 	transferHandler := transfer.NewHandler(transferService, auditService)
 	externalHandler := external.NewHandler(externalService, auditService) // Add this
+	paymentRequestHandler := paymentrequest.NewHandler(paymentRequestService, auditService)
+	fraudHandler := fraud.NewHandler(fraudService, auditService)
+	notificationHandler := notification.NewHandler(notificationService)
 
 	// ========================================
 	// PUBLIC ROUTES
@@ -100,13 +130,58 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, allowedOrigins string, jwtExpiry in
 		adminGroup.GET("/transactions", walletHandler.GetAllTransactions)
 		adminGroup.GET("/transfers", transferHandler.GetAllTransfers)
 
+		// Fraud Review Queue
+		adminGroup.GET("/fraud/alerts", fraudHandler.GetAlerts)
+		adminGroup.GET("/fraud/alerts/:id", fraudHandler.GetAlert)
+		adminGroup.POST("/fraud/alerts/:id/review", fraudHandler.Review)
+		adminGroup.POST("/transfers/:id/release", transferHandler.ReleaseHeldTransfer)
+		adminGroup.POST("/transfers/:id/reverse", transferHandler.ReverseHeldTransfer)
+
 		// Marketplace Management
 		adminGroup.GET("/marketplace/transactions", marketplaceHandler.GetTransactions) // Add this
+		adminGroup.POST("/marketplace/transactions/:id/ready", marketplaceHandler.MarkReady)
+		adminGroup.POST("/marketplace/transactions/:id/cancel", marketplaceHandler.CancelTransaction)
+		adminGroup.POST("/marketplace/pickup", marketplaceHandler.CollectPickup)
+		adminGroup.GET("/marketplace/analytics/summary", marketplaceHandler.GetSalesSummary)
+		adminGroup.GET("/marketplace/analytics/daily", marketplaceHandler.GetDailySales)
+		adminGroup.GET("/marketplace/analytics/products", marketplaceHandler.GetProductSales)
+		adminGroup.GET("/marketplace/analytics/categories", marketplaceHandler.GetCategorySales)
+		adminGroup.GET("/marketplace/analytics/buyers", marketplaceHandler.GetBuyerSegments)
 		adminGroup.GET("/products", marketplaceHandler.GetAll)
 		adminGroup.POST("/products", marketplaceHandler.Create)
 		adminGroup.GET("/products/:id", marketplaceHandler.GetByID)
 		adminGroup.PUT("/products/:id", marketplaceHandler.Update)
 		adminGroup.DELETE("/products/:id", marketplaceHandler.Delete)
+		adminGroup.POST("/products/:id/image", marketplaceHandler.UploadProductImage)
+		adminGroup.POST("/products/:id/variants", marketplaceHandler.AddVariant)
+		adminGroup.PUT("/products/:id/variants/:variantId", marketplaceHandler.UpdateVariant)
+		adminGroup.DELETE("/products/:id/variants/:variantId", marketplaceHandler.DeleteVariant)
+		adminGroup.GET("/products/:id/codes", marketplaceHandler.GetDigitalCodes)
+		adminGroup.POST("/products/:id/codes", marketplaceHandler.UploadDigitalCodes)
+		adminGroup.DELETE("/products/:id/codes/:codeId", marketplaceHandler.RevokeDigitalCode)
+		adminGroup.POST("/products/:id/auctions", marketplaceHandler.CreateAuction)
+		adminGroup.GET("/auctions", marketplaceHandler.GetAuctions)
+		adminGroup.GET("/auctions/:id", marketplaceHandler.GetAuction)
+		adminGroup.POST("/auctions/:id/close", marketplaceHandler.CloseAuction)
+		adminGroup.GET("/categories", marketplaceHandler.GetCategories)
+		adminGroup.POST("/categories", marketplaceHandler.CreateCategory)
+		adminGroup.PUT("/categories/:id", marketplaceHandler.UpdateCategory)
+		adminGroup.DELETE("/categories/:id", marketplaceHandler.DeleteCategory)
+
+		// Promotions & Coupons
+		adminGroup.GET("/promotions", marketplaceHandler.GetPromotions)
+		adminGroup.POST("/promotions", marketplaceHandler.CreatePromotion)
+		adminGroup.PUT("/promotions/:id", marketplaceHandler.UpdatePromotion)
+		adminGroup.DELETE("/promotions/:id", marketplaceHandler.DeletePromotion)
+		adminGroup.GET("/coupons", marketplaceHandler.GetCoupons)
+		adminGroup.POST("/coupons", marketplaceHandler.CreateCoupon)
+		adminGroup.PUT("/coupons/:id", marketplaceHandler.UpdateCoupon)
+		adminGroup.DELETE("/coupons/:id", marketplaceHandler.DeleteCoupon)
+
+		// Marketplace Reviews
+		adminGroup.GET("/reviews", marketplaceHandler.GetReviews)
+		adminGroup.PUT("/reviews/:id/moderate", marketplaceHandler.ModerateReview)
+		adminGroup.DELETE("/reviews/:id", marketplaceHandler.DeleteReview)
 
 		// Audit Logs
 		adminGroup.GET("/audit-logs", auditHandler.GetAll)
@@ -130,11 +205,29 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, allowedOrigins string, jwtExpiry in
 	{
 		// Mission & Task Management
 		dosenGroup.POST("/missions", missionHandler.CreateMission)
+		dosenGroup.POST("/missions/import", missionHandler.ImportMission)
+		dosenGroup.GET("/missions/:id/export", missionHandler.ExportMission)
 		dosenGroup.PUT("/missions/:id", missionHandler.UpdateMission)
 		dosenGroup.DELETE("/missions/:id", missionHandler.DeleteMission)
 		dosenGroup.GET("/missions", missionHandler.GetAllMissions)
 		dosenGroup.GET("/missions/:id", missionHandler.GetMissionByID)
 
+		// Question Bank
+		dosenGroup.GET("/question-bank", missionHandler.GetBankQuestions)
+		dosenGroup.POST("/question-bank", missionHandler.CreateBankQuestion)
+		dosenGroup.GET("/question-bank/:id", missionHandler.GetBankQuestion)
+		dosenGroup.PUT("/question-bank/:id", missionHandler.UpdateBankQuestion)
+		dosenGroup.DELETE("/question-bank/:id", missionHandler.DeleteBankQuestion)
+
+		// Classes
+		dosenGroup.GET("/classes", missionHandler.GetClasses)
+		dosenGroup.POST("/classes", missionHandler.CreateClass)
+		dosenGroup.GET("/classes/:id", missionHandler.GetClass)
+		dosenGroup.PUT("/classes/:id", missionHandler.UpdateClass)
+		dosenGroup.DELETE("/classes/:id", missionHandler.DeleteClass)
+		dosenGroup.POST("/classes/:id/students", missionHandler.EnrollStudents)
+		dosenGroup.DELETE("/classes/:id/students/:studentId", missionHandler.UnenrollStudent)
+
 		// Submission Validation
 		dosenGroup.GET("/submissions", missionHandler.GetAllSubmissions)
 		dosenGroup.POST("/submissions/:id/review", missionHandler.ReviewSubmission)
@@ -158,7 +251,12 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, allowedOrigins string, jwtExpiry in
 		mahasiswaGroup.GET("/missions", missionHandler.GetAllMissions)
 		mahasiswaGroup.GET("/missions/:id", missionHandler.GetMissionByID)
 		mahasiswaGroup.POST("/missions/submit", missionHandler.SubmitMission)
+		mahasiswaGroup.POST("/missions/:id/start", missionHandler.StartAttempt)
+		mahasiswaGroup.GET("/missions/:id/attempt", missionHandler.GetAttempt)
+		mahasiswaGroup.PUT("/missions/:id/attempt/answers", missionHandler.SaveAnswers)
+		mahasiswaGroup.POST("/missions/:id/attempt/submit", missionHandler.SubmitAttempt)
 		mahasiswaGroup.GET("/submissions", missionHandler.GetAllSubmissions)
+		mahasiswaGroup.GET("/classes", missionHandler.GetMyClasses)
 
 		// Transfer Points
 		mahasiswaGroup.POST("/transfer", transferHandler.CreateTransfer)
@@ -168,10 +266,47 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, allowedOrigins string, jwtExpiry in
 		mahasiswaGroup.GET("/transfer/received", transferHandler.GetReceivedTransfers)
 		mahasiswaGroup.GET("/users/lookup", userHandler.LookupUser) // Lookup user for transfer verification
 
+		// Split Bill / Payment Requests
+		mahasiswaGroup.POST("/requests", paymentRequestHandler.Create)
+		mahasiswaGroup.GET("/requests", paymentRequestHandler.GetMyRequests)
+		mahasiswaGroup.GET("/requests/incoming", paymentRequestHandler.GetIncoming)
+		mahasiswaGroup.GET("/requests/:id", paymentRequestHandler.GetByID)
+		mahasiswaGroup.POST("/requests/:id/pay", paymentRequestHandler.Pay)
+		mahasiswaGroup.POST("/requests/:id/decline", paymentRequestHandler.Decline)
+		mahasiswaGroup.POST("/requests/:id/cancel", paymentRequestHandler.Cancel)
+
 		// Marketplace Purchase
 		mahasiswaGroup.POST("/marketplace/purchase", marketplaceHandler.Purchase)
+		mahasiswaGroup.POST("/marketplace/reservations", marketplaceHandler.ReserveStock)
+		mahasiswaGroup.DELETE("/marketplace/reservations/:id", marketplaceHandler.ReleaseReservation)
 		mahasiswaGroup.GET("/marketplace/products", marketplaceHandler.GetAll) // Reuse GetAll, maybe add status filter later
 		mahasiswaGroup.GET("/marketplace/products/:id", marketplaceHandler.GetByID)
+		mahasiswaGroup.GET("/marketplace/products/:id/eligibility", marketplaceHandler.GetEligibility)
+		mahasiswaGroup.GET("/marketplace/products/:id/reviews", marketplaceHandler.GetProductReviews)
+		mahasiswaGroup.POST("/marketplace/products/:id/reviews", marketplaceHandler.SubmitReview)
+		mahasiswaGroup.DELETE("/marketplace/reviews/:id", marketplaceHandler.DeleteMyReview)
+		mahasiswaGroup.GET("/marketplace/categories", marketplaceHandler.GetCategories)
+		mahasiswaGroup.GET("/marketplace/promotions", marketplaceHandler.GetRunningPromotions)
+		mahasiswaGroup.GET("/marketplace/transactions", marketplaceHandler.GetMyPurchases)
+		mahasiswaGroup.GET("/marketplace/auctions", marketplaceHandler.GetAuctions)
+		mahasiswaGroup.GET("/marketplace/auctions/:id", marketplaceHandler.GetAuction)
+		mahasiswaGroup.POST("/marketplace/auctions/:id/bids", marketplaceHandler.PlaceBid)
+		mahasiswaGroup.GET("/marketplace/bids", marketplaceHandler.GetMyBids)
+
+		// Shopping Cart & Orders
+		mahasiswaGroup.GET("/cart", marketplaceHandler.GetCart)
+		mahasiswaGroup.DELETE("/cart", marketplaceHandler.ClearCart)
+		mahasiswaGroup.POST("/cart/items", marketplaceHandler.AddToCart)
+		mahasiswaGroup.PUT("/cart/items/:id", marketplaceHandler.UpdateCartItem)
+		mahasiswaGroup.DELETE("/cart/items/:id", marketplaceHandler.RemoveCartItem)
+		mahasiswaGroup.POST("/cart/checkout", marketplaceHandler.Checkout)
+		mahasiswaGroup.GET("/orders", marketplaceHandler.GetMyOrders)
+		mahasiswaGroup.GET("/orders/:id", marketplaceHandler.GetMyOrder)
+
+		// Wishlist
+		mahasiswaGroup.GET("/wishlist", marketplaceHandler.GetWishlist)
+		mahasiswaGroup.POST("/wishlist", marketplaceHandler.AddToWishlist)
+		mahasiswaGroup.DELETE("/wishlist/:productId", marketplaceHandler.RemoveFromWishlist)
 
 		// Gamification
 		mahasiswaGroup.GET("/leaderboard", walletHandler.GetLeaderboard)
@@ -195,6 +330,50 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, allowedOrigins string, jwtExpiry in
 	{
 		merchantGroup.POST("/payment/scan", walletHandler.MerchantScan)
 		merchantGroup.GET("/stats", walletHandler.GetMerchantStats)
+
+		// Order Fulfilment
+		merchantGroup.POST("/marketplace/transactions/:id/ready", marketplaceHandler.MarkReady)
+		merchantGroup.POST("/marketplace/transactions/:id/cancel", marketplaceHandler.CancelTransaction)
+		merchantGroup.POST("/marketplace/pickup", marketplaceHandler.CollectPickup)
+		merchantGroup.GET("/marketplace/transactions", marketplaceHandler.GetTransactions)
+		merchantGroup.GET("/marketplace/analytics/summary", marketplaceHandler.GetSalesSummary)
+		merchantGroup.GET("/marketplace/analytics/daily", marketplaceHandler.GetDailySales)
+		merchantGroup.GET("/marketplace/analytics/products", marketplaceHandler.GetProductSales)
+		merchantGroup.GET("/marketplace/analytics/categories", marketplaceHandler.GetCategorySales)
+		merchantGroup.GET("/marketplace/analytics/buyers", marketplaceHandler.GetBuyerSegments)
+
+		// Merchant Catalog (own products only)
+		merchantGroup.GET("/products", marketplaceHandler.GetAll)
+		merchantGroup.POST("/products", marketplaceHandler.Create)
+		merchantProducts := merchantGroup.Group("/products/:id", marketplaceHandler.RequireProductOwner())
+		{
+			merchantProducts.GET("", marketplaceHandler.GetByID)
+			merchantProducts.PUT("", marketplaceHandler.Update)
+			merchantProducts.DELETE("", marketplaceHandler.Delete)
+			merchantProducts.POST("/image", marketplaceHandler.UploadProductImage)
+			merchantProducts.POST("/variants", marketplaceHandler.AddVariant)
+			merchantProducts.PUT("/variants/:variantId", marketplaceHandler.UpdateVariant)
+			merchantProducts.DELETE("/variants/:variantId", marketplaceHandler.DeleteVariant)
+			merchantProducts.GET("/codes", marketplaceHandler.GetDigitalCodes)
+			merchantProducts.POST("/codes", marketplaceHandler.UploadDigitalCodes)
+			merchantProducts.DELETE("/codes/:codeId", marketplaceHandler.RevokeDigitalCode)
+			merchantProducts.POST("/auctions", marketplaceHandler.CreateAuction)
+		}
+		merchantGroup.GET("/auctions", marketplaceHandler.GetAuctions)
+		merchantGroup.POST("/auctions/:id/close", marketplaceHandler.CloseAuction)
+	}
+
+	// ========================================
+	// NOTIFICATIONS (any signed-in user)
+	// ========================================
+	notificationGroup := api.Group("/notifications")
+	notificationGroup.Use(middleware.AuthMiddleware())
+	{
+		notificationGroup.GET("", notificationHandler.GetNotifications)
+		notificationGroup.GET("/unread-count", notificationHandler.GetUnreadCount)
+		notificationGroup.POST("/read-all", notificationHandler.MarkAllRead)
+		notificationGroup.POST("/:id/read", notificationHandler.MarkRead)
+		notificationGroup.DELETE("/:id", notificationHandler.Delete)
 	}
 
 	// Global QR Status Check
@@ -207,4 +386,6 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, allowedOrigins string, jwtExpiry in
 			"message": "Wallet Point API is running",
 		})
 	})
+
+	return jobs
 }
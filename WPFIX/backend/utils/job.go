@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"context"
+	"time"
+)
+
+// RunEvery calls fn every interval until ctx is cancelled. It blocks, so
+// callers run it in its own goroutine; a run in progress is finished before
+// it returns.
+func RunEvery(ctx context.Context, interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fn()
+		}
+	}
+}
@@ -4,17 +4,31 @@ import (
 	"fmt"
 	"math/rand"
 	"net/http"
+	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 const (
-	MaxUploadSize = 10 << 20 // 10MB
-	UploadDir     = "uploads"
+	MaxUploadSize = 10 << 20         // 10MB
+	UploadDir     = "public/uploads" // Served at /uploads
 )
 
+// allowedUploads maps the sniffed content type of an upload to the
+// extensions it may carry. Office documents are ZIP containers.
+var allowedUploads = map[string][]string{
+	"image/jpeg":                {".jpg", ".jpeg"},
+	"image/png":                 {".png"},
+	"image/gif":                 {".gif"},
+	"image/webp":                {".webp"},
+	"application/pdf":           {".pdf"},
+	"application/zip":           {".zip", ".docx", ".xlsx", ".pptx"},
+	"text/plain; charset=utf-8": {".txt", ".csv"},
+}
+
 func HandleFileUpload(c *gin.Context) {
 	file, err := c.FormFile("file")
 	if err != nil {
@@ -27,10 +41,30 @@ func HandleFileUpload(c *gin.Context) {
 		return
 	}
 
+	// Check the real content type, not just the name
+	src, err := file.Open()
+	if err != nil {
+		ErrorResponse(c, http.StatusBadRequest, "Failed to read file", err.Error())
+		return
+	}
+	head := make([]byte, 512)
+	n, _ := src.Read(head)
+	src.Close()
+
+	contentType := http.DetectContentType(head[:n])
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	if !isAllowedUpload(contentType, ext) {
+		ErrorResponse(c, http.StatusBadRequest, "File type not allowed", fmt.Sprintf("%s (%s)", ext, contentType))
+		return
+	}
+
 	// Generate unique filename
-	ext := filepath.Ext(file.Filename)
 	newFilename := fmt.Sprintf("%d_%d%s", time.Now().UnixNano(), rand.Intn(1000), ext)
 
+	if err := os.MkdirAll(UploadDir, 0755); err != nil {
+		ErrorResponse(c, http.StatusInternalServerError, "Failed to create upload directory", err.Error())
+		return
+	}
 	savePath := filepath.Join(UploadDir, newFilename)
 
 	if err := c.SaveUploadedFile(file, savePath); err != nil {
@@ -44,3 +78,13 @@ func HandleFileUpload(c *gin.Context) {
 		"file_url": fileURL,
 	})
 }
+
+// isAllowedUpload reports whether a sniffed content type may be stored under ext
+func isAllowedUpload(contentType, ext string) bool {
+	for _, allowed := range allowedUploads[contentType] {
+		if ext == allowed {
+			return true
+		}
+	}
+	return false
+}